@@ -0,0 +1,86 @@
+package agentclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/telkomdev/tob/config"
+	"github.com/telkomdev/tob/httpx"
+)
+
+// Client calls tob-http-agent with the authentication and TLS options of the service config,
+// it is shared by diskstatus and hoststatus
+type Client struct {
+	url        string
+	token      string
+	hmacSecret string
+	client     *http.Client
+}
+
+// NewClient will return Client of the agent url,
+// the options are token, hmacSecret, caFile, certFile, keyFile and tlsSkipVerify
+func NewClient(url string, configs config.Config, timeout int) (*Client, error) {
+	caFile, _ := configs["caFile"].(string)
+	certFile, _ := configs["certFile"].(string)
+	keyFile, _ := configs["keyFile"].(string)
+	tlsSkipVerify, _ := configs["tlsSkipVerify"].(bool)
+
+	tlsConfig, err := httpx.TLSConfig(caFile, certFile, keyFile, tlsSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	token, _ := configs["token"].(string)
+	hmacSecret, _ := configs["hmacSecret"].(string)
+
+	return &Client{
+		url:        strings.TrimSuffix(url, "/"),
+		token:      token,
+		hmacSecret: hmacSecret,
+		client:     httpx.NewClient(timeout, tlsConfig),
+	}, nil
+}
+
+// Post will post payload as JSON to the agent path and decode the response into target
+func (a *Client) Post(path string, payload interface{}, target interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, a.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if a.token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.token)
+	}
+
+	if a.hmacSecret != "" {
+		httpx.SignRequest(req, a.hmacSecret, body)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("tob-http-agent is not reachable: %s", err.Error())
+	}
+
+	defer func() { resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("tob-http-agent returned status %d", resp.StatusCode)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(target)
+	if err != nil {
+		return fmt.Errorf("invalid tob-http-agent response: %s", err.Error())
+	}
+
+	return nil
+}
@@ -5,13 +5,21 @@ import (
 	"fmt"
 )
 
+const (
+	// CommandListKinds list the available service kinds
+	CommandListKinds = "list-kinds"
+)
+
 // Argument type
 type Argument struct {
 	ShowVersion bool
 	ConfigFile  string
-	Help        func()
-	Message     []byte
-	Verbose     bool
+
+	// Command the subcommand, eg: list-kinds
+	Command string
+	Help    func()
+	Message []byte
+	Verbose bool
 }
 
 // ParseArgument will parse the OS args into Argument type
@@ -37,7 +45,9 @@ func ParseArgument() (*Argument, error) {
 		fmt.Println("tob -[options]")
 		fmt.Println()
 		fmt.Println("tob -c config.json")
+		fmt.Println("tob -c config.json list-kinds")
 		fmt.Println()
+		fmt.Println("list-kinds (list the available service kinds and their options, including the plugin kinds of the config file)")
 		fmt.Println("-config | -c (configuration .json file)")
 		fmt.Println("-h | -help (show help)")
 		fmt.Println("-v | -version (show version)")
@@ -51,5 +61,7 @@ func ParseArgument() (*Argument, error) {
 	argument.ConfigFile = configFile
 	argument.ShowVersion = showVersion
 	argument.Verbose = verbose
+	argument.Command = flag.Arg(0)
+	argument.Help = flag.Usage
 	return argument, nil
 }
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/telkomdev/tob"
+	"github.com/telkomdev/tob/httpx"
+)
+
+// maxBodySize the maximum request body size
+const maxBodySize = 1 << 20
+
+// agentAuth represent the authentication and the path allowlist of the agent
+type agentAuth struct {
+	token        string
+	hmacSecret   string
+	allowedPaths []string
+}
+
+// newAgentAuth will return agentAuth, allowedPaths is a comma separated list
+func newAgentAuth(token, hmacSecret, allowedPaths string) *agentAuth {
+	auth := &agentAuth{
+		token:      token,
+		hmacSecret: hmacSecret,
+	}
+
+	for _, path := range splitList(allowedPaths) {
+		auth.allowedPaths = append(auth.allowedPaths, resolvePath(path))
+	}
+
+	return auth
+}
+
+// resolvePath will return the absolute path without symlinks, or the cleaned path when it does not exist
+func resolvePath(path string) string {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		return resolved
+	}
+
+	return absPath
+}
+
+// enabled will return true when the requests must be authenticated
+func (a *agentAuth) enabled() bool {
+	return a.token != "" || a.hmacSecret != ""
+}
+
+// pathAllowed will return true when path is in the allowlist or below a path of the allowlist,
+// every path is allowed when the allowlist is empty
+func (a *agentAuth) pathAllowed(path string) bool {
+	if len(a.allowedPaths) == 0 {
+		return true
+	}
+
+	path = resolvePath(path)
+	for _, allowed := range a.allowedPaths {
+		if path == allowed || allowed == "/" || strings.HasPrefix(path, allowed+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// authenticate will check the bearer token or the HMAC signature, either one is enough when both are set
+func (a *agentAuth) authenticate(req *http.Request, body []byte) bool {
+	if a.token != "" {
+		authorization := req.Header.Get("Authorization")
+		if strings.HasPrefix(authorization, "Bearer ") {
+			token := strings.TrimPrefix(authorization, "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) == 1 {
+				return true
+			}
+		}
+	}
+
+	if a.hmacSecret != "" {
+		if err := httpx.VerifyRequest(req, a.hmacSecret, body, httpx.DefaultSignatureMaxAge); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// authMiddleware will reject the requests that are not authenticated
+func (a *agentAuth) authMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		if !a.enabled() {
+			next(res, req)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(req.Body, maxBodySize))
+		if err != nil {
+			jsonResponse(res, 400, []byte(`{"success": false, "message": "invalid payload"}`))
+			return
+		}
+
+		if !a.authenticate(req, body) {
+			tob.Logger.Printf("unauthorized request from %s\n", req.RemoteAddr)
+			jsonResponse(res, 401, []byte(`{"success": false, "message": "unauthorized"}`))
+			return
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		next(res, req)
+	}
+}
+
+// serverTLSConfig will return the TLS config of the agent, clients must present a certificate signed by clientCAFile when it is set
+func serverTLSConfig(clientCAFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if clientCAFile != "" {
+		caPEM, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificate found in %s", clientCAFile)
+		}
+
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,493 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/telkomdev/tob/data"
+)
+
+var (
+	// procRoot and sysRoot are the proc and sys file systems
+	procRoot = "/proc"
+	sysRoot  = "/sys"
+
+	// virtualFileSystems are not reported when every mount is listed
+	virtualFileSystems = map[string]bool{
+		"autofs": true, "binfmt_misc": true, "bpf": true, "cgroup": true, "cgroup2": true,
+		"configfs": true, "debugfs": true, "devpts": true, "devtmpfs": true, "fusectl": true,
+		"hugetlbfs": true, "mqueue": true, "nsfs": true, "proc": true, "pstore": true,
+		"rpc_pipefs": true, "securityfs": true, "squashfs": true, "sysfs": true, "tracefs": true,
+		"tmpfs": true, "ramfs": true, "overlay": true, "efivarfs": true, "selinuxfs": true,
+	}
+)
+
+// mountInfo represent a line of /proc/self/mounts
+type mountInfo struct {
+	device string
+	path   string
+	fsType string
+}
+
+// collectHostMetrics will collect the host metrics, a metric that cannot be collected is listed in Errors
+func collectHostMetrics(request data.HostMetricsRequest) data.HostMetrics {
+	metrics := data.HostMetrics{
+		CollectedAt: time.Now().Unix(),
+		Mounts:      []data.Mount{},
+		Interfaces:  []data.Interface{},
+		Processes:   []data.Process{},
+		Units:       []data.Unit{},
+		Errors:      []string{},
+	}
+
+	addError := func(metric string, err error) {
+		metrics.Errors = append(metrics.Errors, fmt.Sprintf("%s: %s", metric, err.Error()))
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		addError("hostname", err)
+	}
+
+	metrics.Hostname = hostname
+
+	metrics.Load, err = readLoad()
+	if err != nil {
+		addError("load", err)
+	}
+
+	metrics.Memory, metrics.Swap, err = readMemory()
+	if err != nil {
+		addError("memory", err)
+	}
+
+	metrics.Mounts, err = readMounts(request.Mounts)
+	if err != nil {
+		addError("mounts", err)
+	}
+
+	metrics.Interfaces, err = readInterfaces()
+	if err != nil {
+		addError("interfaces", err)
+	}
+
+	metrics.FileDescriptors, err = readFileDescriptors()
+	if err != nil {
+		addError("fileDescriptors", err)
+	}
+
+	if len(request.Processes) > 0 {
+		metrics.Processes, err = readProcesses(request.Processes)
+		if err != nil {
+			addError("processes", err)
+		}
+	}
+
+	for _, unit := range request.Units {
+		metrics.Units = append(metrics.Units, readUnit(unit))
+	}
+
+	return metrics
+}
+
+// readLoad will read /proc/loadavg
+func readLoad() (*data.Load, error) {
+	b, err := os.ReadFile(filepath.Join(procRoot, "loadavg"))
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Fields(string(b))
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("invalid loadavg %q", string(b))
+	}
+
+	var loads [3]float64
+	for i := range loads {
+		loads[i], err = strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &data.Load{
+		Load1:  loads[0],
+		Load5:  loads[1],
+		Load15: loads[2],
+		CPUs:   runtime.NumCPU(),
+	}, nil
+}
+
+// readMemory will read the memory and swap usage from /proc/meminfo
+func readMemory() (*data.Memory, *data.Memory, error) {
+	f, err := os.Open(filepath.Join(procRoot, "meminfo"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer func() { f.Close() }()
+
+	// values of meminfo are in kB
+	values := make(map[string]uint64)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+
+		n, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		values[key] = n * 1024
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	memory := &data.Memory{
+		Total:     values["MemTotal"],
+		Available: values["MemAvailable"],
+	}
+
+	memory.UsedPercent = usedPercent(memory.Total-memory.Available, memory.Total)
+
+	swap := &data.Memory{
+		Total:     values["SwapTotal"],
+		Available: values["SwapFree"],
+	}
+
+	swap.UsedPercent = usedPercent(swap.Total-swap.Available, swap.Total)
+
+	return memory, swap, nil
+}
+
+// readMountInfos will read /proc/self/mounts, the last mount of a path wins
+func readMountInfos() ([]mountInfo, error) {
+	f, err := os.Open(filepath.Join(procRoot, "self", "mounts"))
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { f.Close() }()
+
+	var mounts []mountInfo
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+
+		mounts = append(mounts, mountInfo{
+			device: unescapeMountField(fields[0]),
+			path:   unescapeMountField(fields[1]),
+			fsType: fields[2],
+		})
+	}
+
+	return mounts, scanner.Err()
+}
+
+// unescapeMountField will unescape the octal escapes of /proc/self/mounts, eg: \040 is a space
+func unescapeMountField(field string) string {
+	if !strings.Contains(field, `\`) {
+		return field
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+3 < len(field) {
+			if n, err := strconv.ParseUint(field[i+1:i+4], 8, 8); err == nil {
+				sb.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+
+		sb.WriteByte(field[i])
+	}
+
+	return sb.String()
+}
+
+// mountOf will return the mount that contains path
+func mountOf(mounts []mountInfo, path string) (mountInfo, bool) {
+	var (
+		found mountInfo
+		ok    bool
+	)
+
+	for _, m := range mounts {
+		if path == m.path || m.path == "/" || strings.HasPrefix(path, m.path+"/") {
+			if !ok || len(m.path) >= len(found.path) {
+				found = m
+				ok = true
+			}
+		}
+	}
+
+	return found, ok
+}
+
+// readMounts will read the disk and inode usage of paths, or of every non virtual mount when paths is empty
+func readMounts(paths []string) ([]data.Mount, error) {
+	mountInfos, mountErr := readMountInfos()
+
+	var targets []mountInfo
+	if len(paths) == 0 {
+		if mountErr != nil {
+			return []data.Mount{}, mountErr
+		}
+
+		seen := make(map[string]bool)
+		for _, m := range mountInfos {
+			if virtualFileSystems[m.fsType] || seen[m.path] {
+				continue
+			}
+
+			seen[m.path] = true
+			targets = append(targets, m)
+		}
+	} else {
+		for _, path := range paths {
+			target := mountInfo{path: path}
+			if absPath, err := filepath.Abs(path); err == nil {
+				if m, ok := mountOf(mountInfos, absPath); ok {
+					target.device = m.device
+					target.fsType = m.fsType
+				}
+			}
+
+			targets = append(targets, target)
+		}
+	}
+
+	mounts := []data.Mount{}
+
+	var errs []string
+	for _, target := range targets {
+		mount, err := statMount(target)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s %s", target.path, err.Error()))
+			continue
+		}
+
+		// skip the mounts without blocks, eg: a fuse mount
+		if len(paths) == 0 && mount.Total == 0 {
+			continue
+		}
+
+		mounts = append(mounts, mount)
+	}
+
+	sort.Slice(mounts, func(i, j int) bool {
+		return mounts[i].Path < mounts[j].Path
+	})
+
+	if len(errs) > 0 {
+		return mounts, fmt.Errorf("%s", strings.Join(errs, ", "))
+	}
+
+	return mounts, nil
+}
+
+// statMount will read the disk and inode usage with statfs,
+// the used percent is computed like df, the blocks reserved for root are not available
+func statMount(target mountInfo) (data.Mount, error) {
+	stat, err := statfs(target.path)
+	if err != nil {
+		return data.Mount{}, err
+	}
+
+	used := (stat.blocks - stat.free) * stat.blockSize
+	available := stat.available * stat.blockSize
+
+	mount := data.Mount{
+		Path:        target.path,
+		Device:      target.device,
+		FSType:      target.fsType,
+		Total:       stat.blocks * stat.blockSize,
+		Used:        used,
+		Available:   available,
+		UsedPercent: usedPercent(used, used+available),
+		Inodes:      stat.files,
+	}
+
+	if stat.files > 0 {
+		mount.InodesUsed = stat.files - stat.filesFree
+		mount.InodesUsedPercent = usedPercent(mount.InodesUsed, stat.files)
+	}
+
+	return mount, nil
+}
+
+// readInterfaces will read the error counters of every network interface from /proc/net/dev
+func readInterfaces() ([]data.Interface, error) {
+	f, err := os.Open(filepath.Join(procRoot, "net", "dev"))
+	if err != nil {
+		return []data.Interface{}, err
+	}
+
+	defer func() { f.Close() }()
+
+	interfaces := []data.Interface{}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, counters, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+
+		// receive: bytes packets errs drop fifo frame compressed multicast
+		// transmit: bytes packets errs drop fifo colls carrier compressed
+		fields := strings.Fields(counters)
+		if len(fields) < 16 {
+			continue
+		}
+
+		parse := func(i int) uint64 {
+			n, _ := strconv.ParseUint(fields[i], 10, 64)
+			return n
+		}
+
+		interfaces = append(interfaces, data.Interface{
+			Name:      strings.TrimSpace(name),
+			RxErrors:  parse(2),
+			RxDropped: parse(3),
+			TxErrors:  parse(10),
+			TxDropped: parse(11),
+		})
+	}
+
+	return interfaces, scanner.Err()
+}
+
+// readFileDescriptors will read /proc/sys/fs/file-nr
+func readFileDescriptors() (*data.FileDescriptors, error) {
+	b, err := os.ReadFile(filepath.Join(procRoot, "sys", "fs", "file-nr"))
+	if err != nil {
+		return nil, err
+	}
+
+	// allocated, allocated but unused, maximum
+	fields := strings.Fields(string(b))
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("invalid file-nr %q", string(b))
+	}
+
+	allocated, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	unused, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	max, err := strconv.ParseUint(fields[2], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.FileDescriptors{
+		Allocated:   allocated - unused,
+		Max:         max,
+		UsedPercent: usedPercent(allocated-unused, max),
+	}, nil
+}
+
+// readProcesses will count the running processes of each name, the name is matched with /proc/[pid]/comm
+func readProcesses(names []string) ([]data.Process, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return []data.Process{}, err
+	}
+
+	running := make(map[string]int)
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+
+		// the process may have exited already
+		comm, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "comm"))
+		if err != nil {
+			continue
+		}
+
+		running[strings.TrimSpace(string(comm))]++
+	}
+
+	processes := make([]data.Process, 0, len(names))
+	for _, name := range names {
+		// comm is truncated to 15 characters
+		comm := name
+		if len(comm) > 15 {
+			comm = comm[:15]
+		}
+
+		processes = append(processes, data.Process{Name: name, Running: running[comm]})
+	}
+
+	return processes, nil
+}
+
+// readUnit will check the systemd unit is active with its cgroup, the unit is active when its cgroup has a process.
+// A unit without the .service suffix is a service, a unit with a slash is a cgroup path, eg: user.slice/user-1000.slice
+func readUnit(name string) data.Unit {
+	unit := data.Unit{Name: name}
+
+	cgroup := name
+	if !strings.Contains(cgroup, "/") {
+		if !strings.Contains(cgroup, ".") {
+			cgroup = cgroup + ".service"
+		}
+
+		cgroup = filepath.Join("system.slice", cgroup)
+	}
+
+	// cgroup v2 is unified, cgroup v1 has the systemd hierarchy
+	for _, root := range []string{filepath.Join(sysRoot, "fs", "cgroup"), filepath.Join(sysRoot, "fs", "cgroup", "systemd")} {
+		procs, err := os.ReadFile(filepath.Join(root, filepath.Clean("/"+cgroup), "cgroup.procs"))
+		if err != nil {
+			continue
+		}
+
+		if len(strings.TrimSpace(string(procs))) > 0 {
+			unit.Active = true
+			break
+		}
+	}
+
+	return unit
+}
+
+// usedPercent will return used of total in percent rounded to 2 decimals
+func usedPercent(used, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return math.Round(float64(used)/float64(total)*10000) / 100
+}
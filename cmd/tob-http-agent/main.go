@@ -8,16 +8,13 @@ import (
 	"math"
 	"net/http"
 	"os"
-	"os/exec"
-	"regexp"
+	"path/filepath"
 	"runtime"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/telkomdev/tob"
 	"github.com/telkomdev/tob/data"
-	"github.com/telkomdev/tob/util"
 )
 
 const (
@@ -25,21 +22,48 @@ const (
 	DefaultPort = 9113
 
 	// Version current version for tob-http-agent
-	Version = "1.1.0"
+	Version = "1.2.0"
 )
 
 func main() {
 
 	var (
-		httpPort    int
-		showVersion bool
-		verbose     bool
+		httpPort     int
+		showVersion  bool
+		verbose      bool
+		token        string
+		hmacSecret   string
+		tlsCert      string
+		tlsKey       string
+		tlsClientCA  string
+		allowedPaths string
+
+		pushURL       string
+		pushID        string
+		pushInterval  int
+		pushCAFile    string
+		pushMounts    string
+		pushProcesses string
+		pushUnits     string
 	)
 
 	flag.IntVar(&httpPort, "port", DefaultPort, "HTTP Port (eg: 9113)")
 	flag.BoolVar(&showVersion, "version", false, "show version")
 	flag.BoolVar(&showVersion, "v", false, "show version")
 	flag.BoolVar(&verbose, "V", true, "verbose mode (if true log will appear otherwise no)")
+	flag.StringVar(&token, "token", os.Getenv("TOB_AGENT_TOKEN"), "bearer token required by every request")
+	flag.StringVar(&hmacSecret, "hmac-secret", os.Getenv("TOB_AGENT_HMAC_SECRET"), "HMAC secret of the signed requests")
+	flag.StringVar(&tlsCert, "tls-cert", "", "TLS certificate file")
+	flag.StringVar(&tlsKey, "tls-key", "", "TLS key file")
+	flag.StringVar(&tlsClientCA, "tls-client-ca", "", "CA file of the client certificates (mTLS)")
+	flag.StringVar(&allowedPaths, "allow-path", "", "comma separated paths that may be checked, eg: /,/data")
+	flag.StringVar(&pushURL, "push-url", "", "push mode, tob agent report url, eg: https://tob.mycompany.com/api/tob/agent/report")
+	flag.StringVar(&pushID, "push-id", "", "push mode, the pushId of the hoststatus service")
+	flag.IntVar(&pushInterval, "push-interval", 60, "push mode, seconds between two reports")
+	flag.StringVar(&pushCAFile, "push-ca-file", "", "push mode, CA file used to verify tob")
+	flag.StringVar(&pushMounts, "push-mounts", "", "push mode, comma separated mounts to report, every mount when it is empty")
+	flag.StringVar(&pushProcesses, "push-processes", "", "push mode, comma separated process names to report")
+	flag.StringVar(&pushUnits, "push-units", "", "push mode, comma separated systemd units to report")
 
 	flag.Usage = func() {
 		fmt.Println()
@@ -47,7 +71,16 @@ func main() {
 		fmt.Println("tob-http-agent -[options]")
 		fmt.Println()
 		fmt.Println("tob-http-agent -port 9113")
+		fmt.Println("tob-http-agent -port 9113 -token mytoken -tls-cert agent.crt -tls-key agent.key -allow-path /,/data")
 		fmt.Println()
+		fmt.Println("-port (HTTP port)")
+		fmt.Println("-token (bearer token, or the TOB_AGENT_TOKEN env)")
+		fmt.Println("-hmac-secret (HMAC secret of the signed requests, or the TOB_AGENT_HMAC_SECRET env)")
+		fmt.Println("-tls-cert | -tls-key (serve HTTPS)")
+		fmt.Println("-tls-client-ca (require client certificates signed by this CA)")
+		fmt.Println("-allow-path (comma separated paths that may be checked, every path when it is empty)")
+		fmt.Println("-push-url (push mode, post signed reports to tob instead of listening, requires -push-id and -hmac-secret)")
+		fmt.Println("-push-id | -push-interval | -push-ca-file | -push-mounts | -push-processes | -push-units (push mode options)")
 		fmt.Println("-h | -help (show help)")
 		fmt.Println("-v | -version (show version)")
 		fmt.Println("-V : verbose mode")
@@ -63,11 +96,62 @@ func main() {
 		os.Exit(0)
 	}
 
-	http.HandleFunc("/", loggerMiddleware(indexHandler()))
-	http.HandleFunc("/check-disk", loggerMiddleware(checkStorageHandler()))
+	auth := newAgentAuth(token, hmacSecret, allowedPaths)
+	if !auth.enabled() && pushURL == "" {
+		tob.Logger.Println("warning: authentication is disabled, set -token or -hmac-secret")
+	}
+
+	if len(auth.allowedPaths) == 0 {
+		tob.Logger.Println("warning: every path may be checked, set -allow-path")
+	}
+
+	if pushURL != "" {
+		request := data.HostMetricsRequest{
+			Mounts:    splitList(pushMounts),
+			Processes: splitList(pushProcesses),
+			Units:     splitList(pushUnits),
+		}
+
+		p, err := newPusher(pushURL, pushID, hmacSecret, pushInterval, pushCAFile, request, auth)
+		if err != nil {
+			tob.Logger.Println(err)
+			os.Exit(1)
+		}
+
+		p.run()
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", loggerMiddleware(indexHandler()))
+	mux.HandleFunc("/check-disk", loggerMiddleware(auth.authMiddleware(checkStorageHandler(auth))))
+	mux.HandleFunc("/host-metrics", loggerMiddleware(auth.authMiddleware(hostMetricsHandler(auth))))
+
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", httpPort),
+		Handler: mux,
+	}
+
+	var err error
+	if tlsCert != "" || tlsKey != "" {
+		server.TLSConfig, err = serverTLSConfig(tlsClientCA)
+		if err != nil {
+			tob.Logger.Println(err)
+			os.Exit(1)
+		}
+
+		tob.Logger.Printf("webapp running on port :%d with TLS\n", httpPort)
+		err = server.ListenAndServeTLS(tlsCert, tlsKey)
+	} else {
+		if tlsClientCA != "" {
+			tob.Logger.Println("-tls-client-ca requires -tls-cert and -tls-key")
+			os.Exit(1)
+		}
+
+		tob.Logger.Printf("webapp running on port :%d\n", httpPort)
+		err = server.ListenAndServe()
+	}
 
-	tob.Logger.Printf("webapp running on port :%d\n", httpPort)
-	err := http.ListenAndServe(fmt.Sprintf(":%d", httpPort), nil)
 	if err != nil {
 		tob.Logger.Println(err)
 		os.Exit(1)
@@ -86,7 +170,7 @@ func indexHandler() http.HandlerFunc {
 	}
 }
 
-func checkStorageHandler() http.HandlerFunc {
+func checkStorageHandler(auth *agentAuth) http.HandlerFunc {
 	return func(res http.ResponseWriter, req *http.Request) {
 		if req.Method != http.MethodPost {
 			jsonResponse(res, 400, []byte(`{"success": false, "message": "invalid http method"}`))
@@ -99,13 +183,12 @@ func checkStorageHandler() http.HandlerFunc {
 			return
 		}
 
-		jsonMap, wait, err := checkDiskStatus(fileSystem.Path)
-		if err != nil {
-			jsonResponse(res, 500, []byte(`{"success": false, "message": "error check storage"}`))
+		if !auth.pathAllowed(fileSystem.Path) {
+			jsonResponse(res, 403, []byte(`{"success": false, "message": "path is not allowed"}`))
 			return
 		}
 
-		err = wait()
+		jsonMap, err := checkDiskStatus(fileSystem.Path)
 		if err != nil {
 			tob.Logger.Println(err)
 			jsonResponse(res, 500, []byte(`{"success": false, "message": "error check storage"}`))
@@ -130,6 +213,60 @@ func checkStorageHandler() http.HandlerFunc {
 	}
 }
 
+func hostMetricsHandler(auth *agentAuth) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		var request data.HostMetricsRequest
+
+		switch req.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			if err := json.NewDecoder(req.Body).Decode(&request); err != nil && err != io.EOF {
+				jsonResponse(res, 400, []byte(`{"success": false, "message": "invalid host metrics payload"}`))
+				return
+			}
+		default:
+			jsonResponse(res, 400, []byte(`{"success": false, "message": "invalid http method"}`))
+			return
+		}
+
+		for _, mount := range request.Mounts {
+			if !auth.pathAllowed(mount) {
+				jsonResponse(res, 403, []byte(`{"success": false, "message": "path is not allowed"}`))
+				return
+			}
+		}
+
+		metrics := collectHostMetrics(request)
+
+		// every mount is reported when no mount is requested, except the mounts that are not allowed
+		if len(request.Mounts) == 0 {
+			mounts := []data.Mount{}
+			for _, mount := range metrics.Mounts {
+				if auth.pathAllowed(mount.Path) {
+					mounts = append(mounts, mount)
+				}
+			}
+
+			metrics.Mounts = mounts
+		}
+
+		payload := customResponse{
+			Success: true,
+			Message: "host metrics",
+			Data:    metrics,
+		}
+
+		response, err := json.Marshal(payload)
+		if err != nil {
+			tob.Logger.Println(err)
+			jsonResponse(res, 500, []byte(`{"success": false, "message": "error host metrics"}`))
+			return
+		}
+
+		jsonResponse(res, 200, response)
+	}
+}
+
 // HTTP utility
 func jsonResponse(res http.ResponseWriter, httpCode int, payload []byte) {
 	res.Header().Add("Content-Type", "application/json")
@@ -146,121 +283,49 @@ func loggerMiddleware(next http.Handler) http.HandlerFunc {
 	}
 }
 
-func checkDiskStatus(directoryTarget string) (map[string]interface{}, func() error, error) {
-	// check if df binary exist
-	dfPath, err := exec.LookPath("df")
-	if err != nil {
-		tob.Logger.Println(err)
-		return nil, nil, err
-	}
-
+// checkDiskStatus will return the disk usage of the mount that contains directoryTarget,
+// with the same fields as the df output that was parsed by the previous versions
+func checkDiskStatus(directoryTarget string) (map[string]interface{}, error) {
 	// check if directory exist
-	_, err = os.Stat(directoryTarget)
+	_, err := os.Stat(directoryTarget)
 	if err != nil {
-		tob.Logger.Println(err)
-		return nil, nil, err
+		return nil, err
 	}
 
-	cmd := exec.Command(dfPath, directoryTarget)
-	outPipe, err := cmd.StdoutPipe()
-	if err != nil {
-		tob.Logger.Println(err)
-		return nil, nil, err
-	}
+	target := mountInfo{path: directoryTarget, device: directoryTarget}
 
-	err = cmd.Start()
-	if err != nil {
-		tob.Logger.Println(err)
-		return nil, nil, err
-	}
-
-	b, err := io.ReadAll(outPipe)
-	if err != nil {
-		tob.Logger.Println(err)
-		return nil, nil, err
-	}
-
-	ss := strings.Split(string(b), "\n")
-
-	var (
-		jsonMap map[string]interface{}
-	)
-
-	split := func(text string) ([]string, error) {
-		re, err := regexp.Compile(`\s+|\s+$`)
-		if err != nil {
-			return nil, err
-		}
-		result := re.Split(text, -1)
-		return result, nil
-	}
-
-	parseNumberOnly := func(str string) int {
-		re := regexp.MustCompile(`[-]?\d[\d,]*[\.]?[\d{2}]*`)
-		allStringNum := re.FindAllString(str, -1)
-		if len(allStringNum) > 0 {
-			numStr := allStringNum[0]
-			num, err := strconv.Atoi(numStr)
-			if err != nil {
-				return 0
+	mountInfos, err := readMountInfos()
+	if err == nil {
+		absPath, err := filepath.Abs(directoryTarget)
+		if err == nil {
+			if m, ok := mountOf(mountInfos, absPath); ok {
+				target = m
 			}
-
-			return num
-		}
-		return 0
-	}
-
-	if len(ss) >= 2 {
-		headers := ss[0]
-		values := ss[1]
-
-		tob.Logger.Println(headers)
-		tob.Logger.Println(values)
-
-		headerSplitted, err := split(headers)
-		if err != nil {
-			tob.Logger.Println(err)
-			return nil, nil, err
-		}
-
-		valueSplitted, err := split(values)
-		if err != nil {
-			tob.Logger.Println(err)
-			return nil, nil, err
 		}
 
-		// the length of headerSplitted must be equal to valueSplitted
-		headerSplitted = headerSplitted[:len(valueSplitted)]
-
-		jsonMap = make(map[string]interface{})
-
-		for i := 0; i < len(headerSplitted); i++ {
-			if strings.ToLower(headerSplitted[i]) == "use%" {
-				jsonMap[strings.ToLower(headerSplitted[i])] = parseNumberOnly(valueSplitted[i])
-			} else {
-				jsonMap[strings.ToLower(headerSplitted[i])] = valueSplitted[i]
+		// a device is checked with the file system mounted from it, like df
+		for _, m := range mountInfos {
+			if m.device == directoryTarget {
+				target = m
+				break
 			}
-
-		}
-
-		usedFloat64 := util.InterfaceToFloat64(jsonMap["used"])
-
-		availableFloat64 := util.InterfaceToFloat64(jsonMap["available"])
-
-		var diskUsed float64
-		if usedFloat64 > 0 {
-			diskUsed = (usedFloat64 / (usedFloat64 + availableFloat64)) * 100
-		} else {
-			diskUsed = 0.0
 		}
-
-		jsonMap["diskUsed"] = math.Round(diskUsed)
-
 	}
 
-	wait := func() error {
-		return cmd.Wait()
+	mount, err := statMount(target)
+	if err != nil {
+		return nil, err
 	}
 
-	return jsonMap, wait, nil
+	jsonMap := make(map[string]interface{})
+	jsonMap["filesystem"] = mount.Device
+	jsonMap["1k-blocks"] = strconv.FormatUint(mount.Total/1024, 10)
+	jsonMap["used"] = strconv.FormatUint(mount.Used/1024, 10)
+	jsonMap["available"] = strconv.FormatUint(mount.Available/1024, 10)
+	jsonMap["use%"] = int(math.Ceil(mount.UsedPercent))
+	jsonMap["mounted"] = mount.Path
+	jsonMap["diskUsed"] = math.Round(mount.UsedPercent)
+	jsonMap["inodesUsed"] = math.Round(mount.InodesUsedPercent)
+
+	return jsonMap, nil
 }
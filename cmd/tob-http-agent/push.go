@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/telkomdev/tob"
+	"github.com/telkomdev/tob/data"
+	"github.com/telkomdev/tob/httpx"
+)
+
+// pusher will push the host metrics to the tob HTTP server instead of being polled,
+// for hosts that tob cannot reach, eg: behind NAT
+type pusher struct {
+	url        string
+	id         string
+	hmacSecret string
+	interval   time.Duration
+	request    data.HostMetricsRequest
+	auth       *agentAuth
+	client     *http.Client
+}
+
+func newPusher(url, id, hmacSecret string, interval int, caFile string, request data.HostMetricsRequest, auth *agentAuth) (*pusher, error) {
+	if id == "" {
+		return nil, errors.New("-push-id is required in push mode")
+	}
+
+	if hmacSecret == "" {
+		return nil, errors.New("-hmac-secret is required in push mode")
+	}
+
+	if interval <= 0 {
+		return nil, errors.New("-push-interval must be greater than 0")
+	}
+
+	for _, mount := range request.Mounts {
+		if !auth.pathAllowed(mount) {
+			return nil, fmt.Errorf("mount %s is not allowed", mount)
+		}
+	}
+
+	tlsConfig, err := httpx.TLSConfig(caFile, "", "", false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pusher{
+		url:        url,
+		id:         id,
+		hmacSecret: hmacSecret,
+		interval:   time.Duration(interval) * time.Second,
+		request:    request,
+		auth:       auth,
+		client:     httpx.NewClient(10, tlsConfig),
+	}, nil
+}
+
+// run will push a report every interval, a failed push is logged and retried on the next interval
+func (p *pusher) run() {
+	tob.Logger.Printf("push mode: reporting %s to %s every %s\n", p.id, p.url, p.interval)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		err := p.push()
+		if err != nil {
+			tob.Logger.Printf("push mode: %s\n", err.Error())
+		}
+
+		<-ticker.C
+	}
+}
+
+func (p *pusher) push() error {
+	metrics := collectHostMetrics(p.request)
+
+	if len(p.request.Mounts) == 0 {
+		mounts := []data.Mount{}
+		for _, mount := range metrics.Mounts {
+			if p.auth.pathAllowed(mount.Path) {
+				mounts = append(mounts, mount)
+			}
+		}
+
+		metrics.Mounts = mounts
+	}
+
+	body, err := json.Marshal(data.HostReport{PushID: p.id, Metrics: metrics})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	httpx.SignRequest(req, p.hmacSecret, body)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() { resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("tob returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// splitList will split a comma separated list
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
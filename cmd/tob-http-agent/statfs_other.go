@@ -0,0 +1,22 @@
+//go:build !linux && !darwin && !freebsd
+
+package main
+
+import (
+	"errors"
+	"runtime"
+)
+
+// fsStat represent the statfs result in blocks and inodes
+type fsStat struct {
+	blockSize uint64
+	blocks    uint64
+	free      uint64
+	available uint64
+	files     uint64
+	filesFree uint64
+}
+
+func statfs(path string) (fsStat, error) {
+	return fsStat{}, errors.New("statfs is not supported on " + runtime.GOOS)
+}
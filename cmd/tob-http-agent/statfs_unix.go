@@ -0,0 +1,34 @@
+//go:build linux || darwin || freebsd
+
+package main
+
+import (
+	"syscall"
+)
+
+// fsStat represent the statfs result in blocks and inodes
+type fsStat struct {
+	blockSize uint64
+	blocks    uint64
+	free      uint64
+	available uint64
+	files     uint64
+	filesFree uint64
+}
+
+func statfs(path string) (fsStat, error) {
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(path, &stat)
+	if err != nil {
+		return fsStat{}, err
+	}
+
+	return fsStat{
+		blockSize: uint64(stat.Bsize),
+		blocks:    uint64(stat.Blocks),
+		free:      uint64(stat.Bfree),
+		available: uint64(stat.Bavail),
+		files:     uint64(stat.Files),
+		filesFree: uint64(stat.Ffree),
+	}, nil
+}
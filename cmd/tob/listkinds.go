@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/telkomdev/tob"
+	"github.com/telkomdev/tob/config"
+	"github.com/telkomdev/tob/runner"
+)
+
+// listKinds will print every available service kind with its options,
+// the plugin kinds are loaded from the config file when it exists
+func listKinds(w io.Writer, configFilePath string) error {
+	configFile, err := os.Open(configFilePath)
+	if err == nil {
+		configs, err := config.LoadConfig(configFile)
+		configFile.Close()
+		if err != nil {
+			return err
+		}
+
+		err = runner.RegisterPluginKinds(configs)
+		if err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "every kind accepts: url, kind, checkInterval, enable, notificator")
+	fmt.Fprintln(tw)
+
+	for _, info := range tob.ServiceKinds() {
+		kind := string(info.Kind)
+		if info.Plugin {
+			kind = fmt.Sprintf("%s (plugin)", kind)
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\n", kind, info.Description)
+		for _, option := range info.Options {
+			optionType := option.Type
+			if option.Required {
+				optionType = fmt.Sprintf("%s, required", optionType)
+			}
+
+			fmt.Fprintf(tw, "    %s (%s)\t%s\n", option.Name, optionType, option.Description)
+		}
+	}
+
+	return tw.Flush()
+}
@@ -27,6 +27,22 @@ func main() {
 		os.Exit(0)
 	}
 
+	switch args.Command {
+	case "":
+	case tob.CommandListKinds:
+		err = listKinds(os.Stdout, args.ConfigFile)
+		if err != nil {
+			fmt.Println("error: ", err)
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	default:
+		fmt.Printf("error: unknown command %s\n", args.Command)
+		args.Help()
+		os.Exit(1)
+	}
+
 	configFile, err := os.Open(args.ConfigFile)
 	if err != nil {
 		fmt.Println("error: ", err)
@@ -72,7 +88,7 @@ func main() {
 	}
 
 	// dashboard server
-	dashboardServer, err := server.NewHTTPServer(configs, tob.Logger)
+	dashboardServer, err := server.NewHTTPServer(configs, tob.Logger, runner.Events())
 	if err != nil {
 		fmt.Println("error: ", err)
 		os.Exit(1)
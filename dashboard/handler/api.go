@@ -4,24 +4,71 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
-	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/telkomdev/tob/config"
+	"github.com/telkomdev/tob/dashboard/history"
 	"github.com/telkomdev/tob/dashboard/shared"
 	"github.com/telkomdev/tob/dashboard/utils"
+	"github.com/telkomdev/tob/data"
+	"github.com/telkomdev/tob/events"
+	"github.com/telkomdev/tob/httpx"
+	"github.com/telkomdev/tob/services/heartbeat"
+	"github.com/telkomdev/tob/services/hoststatus"
+)
+
+const (
+	// maxAgentReportSize the maximum size of an agent report
+	maxAgentReportSize = 1 << 20
+
+	// maxHeartbeatBodySize the maximum body size of a heartbeat ping
+	maxHeartbeatBodySize = 10 << 10
 )
 
 var (
 	defaultDashboardTitle = "Tob Monitoring Dashboard"
 )
 
-// WebhookMessage type
+// WebhookMessage type, the structured status sent by the webhook notificator of another tob instance.
+// A message without service and status is parsed from the text: <service> is <STATUS>[ | details]
 type WebhookMessage struct {
 	Message string `json:"message"`
+	Service string `json:"service"`
+	Status  string `json:"status"`
+	Details string `json:"details"`
+	SentAt  int64  `json:"sentAt"`
+}
+
+// serviceStatuses the statuses shown by the dashboard
+var serviceStatuses = map[string]bool{
+	"UP":        true,
+	"DOWN":      true,
+	"DEGRADED":  true,
+	"CHECKING":  true,
+	"MONITORED": true,
+}
+
+// status will return the service, status and details of the message, it returns false when the message is not valid
+func (m WebhookMessage) status() (string, string, string, bool) {
+	if m.Service != "" || m.Status != "" {
+		return m.Service, m.Status, m.Details, m.Service != "" && serviceStatuses[m.Status]
+	}
+
+	head, details, _ := strings.Cut(m.Message, "|")
+
+	// eg: mysql_cluster_1 is UP. It was down for 5 minutes
+	fields := strings.Fields(head)
+	if len(fields) < 3 || fields[1] != "is" {
+		return "", "", "", false
+	}
+
+	status := strings.TrimRight(fields[2], ".")
+	return fields[0], status, strings.TrimSpace(details), serviceStatuses[status]
 }
 
 // LoginPayload type
@@ -32,12 +79,16 @@ type LoginPayload struct {
 
 // DashboardHTTPHandler type
 type DashboardHTTPHandler struct {
+	serviceDataMutex      sync.RWMutex
 	serviceData           map[string]map[string]interface{}
+	events                *events.Bus
+	history               *history.History
 	logger                *log.Logger
 	dashboardWebhookToken string
 	dashboardTitle        string
 	dashboardUsername     string
 	dashboardPassword     string
+	configs               config.Config
 }
 
 // Data type
@@ -53,7 +104,7 @@ type LoginResponse struct {
 }
 
 // NewDashboardHTTPHandler DashboardHTTPHandler's constructor
-func NewDashboardHTTPHandler(tobConfig config.Config, logger *log.Logger) (*DashboardHTTPHandler, error) {
+func NewDashboardHTTPHandler(tobConfig config.Config, logger *log.Logger, bus *events.Bus) (*DashboardHTTPHandler, error) {
 	if dashboardTitle, ok := tobConfig["dashboardTitle"].(string); ok {
 		defaultDashboardTitle = dashboardTitle
 	}
@@ -90,10 +141,16 @@ func NewDashboardHTTPHandler(tobConfig config.Config, logger *log.Logger) (*Dash
 			continue
 		}
 
+		// the service config is shared with the running service, the dashboard keeps its own copy
+		service := make(map[string]interface{}, len(services))
+		for key, value := range services {
+			service[key] = value
+		}
+
 		// by default services status is UP
-		services["status"] = "UP"
-		services["url"] = ""
-		serviceData[name] = services
+		service["status"] = "UP"
+		service["url"] = ""
+		serviceData[name] = service
 
 	}
 
@@ -107,14 +164,23 @@ func NewDashboardHTTPHandler(tobConfig config.Config, logger *log.Logger) (*Dash
 		return nil, errors.New("cannot parse dashboardPassword from configs")
 	}
 
-	return &DashboardHTTPHandler{
+	h := &DashboardHTTPHandler{
 		dashboardTitle:        defaultDashboardTitle,
 		serviceData:           serviceData,
+		events:                bus,
+		history:               history.NewHistory(),
 		logger:                logger,
 		dashboardWebhookToken: dashboardWebhookToken,
 		dashboardUsername:     dashboardUsername,
 		dashboardPassword:     dashboardPassword,
-	}, nil
+		configs:               tobConfig,
+	}
+
+	// the service data follows the events until the process exits
+	serviceEvents, _ := bus.Subscribe(eventsBufferSize)
+	go h.watch(serviceEvents)
+
+	return h, nil
 }
 
 // Login will handle user login
@@ -211,7 +277,7 @@ func (h *DashboardHTTPHandler) GetServices() http.HandlerFunc {
 		}
 
 		data := Data{
-			Data:           h.serviceData,
+			Data:           h.snapshot(),
 			DashboardTitle: h.dashboardTitle,
 		}
 
@@ -274,29 +340,46 @@ func (h *DashboardHTTPHandler) HandleTobWebhook() http.HandlerFunc {
 			return
 		}
 
-		messages := strings.Split(message.Message, " ")
-		if len(messages) > 0 {
-			h.logger.Print("--------------------")
-			h.logger.Print(messages)
-
-			serviceName := strings.Trim(messages[0], " ")
-			status := strings.Trim(regexp.MustCompile(`[^a-zA-Z0-9 ]+`).ReplaceAllString(messages[2], ""), " ")
+		serviceName, status, details, ok := message.status()
+		if !ok {
+			shared.BuildJSONResponse(resp, shared.Response[shared.EmptyJSON]{
+				Success: false,
+				Code:    400,
+				Message: "webhook payload is not valid",
+				Data:    shared.EmptyJSON{},
+			}, 400)
+			return
+		}
 
-			service, ok := h.serviceData[serviceName]
-			if ok {
-				service["status"] = status
-			}
+		if !h.hasService(serviceName) {
+			h.logger.Printf("webhook: service %s is not in the dashboard\n", serviceName)
+			shared.BuildJSONResponse(resp, shared.Response[shared.EmptyJSON]{
+				Success: false,
+				Code:    404,
+				Message: "service not found",
+				Data:    shared.EmptyJSON{},
+			}, 404)
+			return
+		}
 
-			if len(messages) > 3 {
-				messageDetails := strings.Join(messages[4:], " ")
-				service["messageDetails"] = messageDetails
-			}
+		// the details of a service that is UP are cleared
+		if status == "UP" {
+			details = ""
+		}
 
-			if status == "UP" {
-				service["messageDetails"] = ""
-			}
+		checkedAt := time.Now()
+		if message.SentAt > 0 {
+			checkedAt = time.Unix(message.SentAt, 0)
 		}
 
+		h.events.Publish(events.Event{
+			Kind:      events.Status,
+			Service:   serviceName,
+			Status:    status,
+			CheckedAt: checkedAt,
+			Message:   details,
+		})
+
 		shared.BuildJSONResponse(resp, shared.Response[shared.EmptyJSON]{
 			Success: true,
 			Code:    200,
@@ -305,3 +388,131 @@ func (h *DashboardHTTPHandler) HandleTobWebhook() http.HandlerFunc {
 		}, 200)
 	}
 }
+
+// HandleAgentReport will handle the host metrics pushed by tob-http-agent in push mode,
+// the report must be signed with the hmacSecret of the hoststatus service of its pushId
+func (h *DashboardHTTPHandler) HandleAgentReport() http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			shared.BuildJSONResponse(resp, shared.Response[shared.EmptyJSON]{
+				Success: false,
+				Code:    405,
+				Message: "http method not valid",
+				Data:    shared.EmptyJSON{},
+			}, 405)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(req.Body, maxAgentReportSize))
+		if err != nil {
+			shared.BuildJSONResponse(resp, shared.Response[shared.EmptyJSON]{
+				Success: false,
+				Code:    400,
+				Message: "agent report payload is not valid",
+				Data:    shared.EmptyJSON{},
+			}, 400)
+			return
+		}
+
+		var report data.HostReport
+
+		err = json.Unmarshal(body, &report)
+		if err != nil || report.PushID == "" {
+			shared.BuildJSONResponse(resp, shared.Response[shared.EmptyJSON]{
+				Success: false,
+				Code:    400,
+				Message: "agent report payload is not valid",
+				Data:    shared.EmptyJSON{},
+			}, 400)
+			return
+		}
+
+		// an unknown pushId gets the reply of an invalid signature, so the configured pushIds are not revealed
+		secret, ok := hoststatus.PushSecret(h.configs, report.PushID)
+		if ok {
+			err = httpx.VerifyRequest(req, secret, body, httpx.DefaultSignatureMaxAge)
+		} else {
+			err = errors.New("pushId is not configured")
+		}
+
+		if err != nil {
+			h.logger.Printf("agent report %s from %s: %s\n", report.PushID, req.RemoteAddr, err.Error())
+			shared.BuildJSONResponse(resp, shared.Response[shared.EmptyJSON]{
+				Success: false,
+				Code:    401,
+				Message: "agent report signature is not valid",
+				Data:    shared.EmptyJSON{},
+			}, 401)
+			return
+		}
+
+		err = hoststatus.Report(report.PushID, report.Metrics)
+		if err != nil {
+			shared.BuildJSONResponse(resp, shared.Response[shared.EmptyJSON]{
+				Success: false,
+				Code:    409,
+				Message: err.Error(),
+				Data:    shared.EmptyJSON{},
+			}, 409)
+			return
+		}
+
+		shared.BuildJSONResponse(resp, shared.Response[shared.EmptyJSON]{
+			Success: true,
+			Code:    200,
+			Message: "handle agent report succeed",
+			Data:    shared.EmptyJSON{},
+		}, 200)
+	}
+}
+
+// HandleHeartbeat will record the ping of a heartbeat service, the url is /api/heartbeat/<pingKey>[/start|/success|/fail]
+// and the body excerpt is kept with the ping
+func (h *DashboardHTTPHandler) HandleHeartbeat() http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodPost && req.Method != http.MethodHead {
+			shared.BuildJSONResponse(resp, shared.Response[shared.EmptyJSON]{
+				Success: false,
+				Code:    405,
+				Message: "http method not valid",
+				Data:    shared.EmptyJSON{},
+			}, 405)
+			return
+		}
+
+		pingKey, event, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, heartbeat.PingPath), "/")
+		if event == "" {
+			event = heartbeat.EventSuccess
+		}
+
+		if !heartbeat.IsEvent(event) || !heartbeat.Configured(h.configs, pingKey) {
+			shared.BuildJSONResponse(resp, shared.Response[shared.EmptyJSON]{
+				Success: false,
+				Code:    404,
+				Message: "heartbeat not found",
+				Data:    shared.EmptyJSON{},
+			}, 404)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(req.Body, maxHeartbeatBodySize))
+		if err != nil {
+			shared.BuildJSONResponse(resp, shared.Response[shared.EmptyJSON]{
+				Success: false,
+				Code:    400,
+				Message: "heartbeat payload is not valid",
+				Data:    shared.EmptyJSON{},
+			}, 400)
+			return
+		}
+
+		heartbeat.Record(pingKey, event, string(body), req.RemoteAddr)
+
+		shared.BuildJSONResponse(resp, shared.Response[shared.EmptyJSON]{
+			Success: true,
+			Code:    200,
+			Message: "heartbeat recorded",
+			Data:    shared.EmptyJSON{},
+		}, 200)
+	}
+}
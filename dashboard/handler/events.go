@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/telkomdev/tob/dashboard/shared"
+	"github.com/telkomdev/tob/dashboard/utils"
+	"github.com/telkomdev/tob/events"
+)
+
+const (
+	// eventsBufferSize the events buffered for a subscriber before they are dropped
+	eventsBufferSize = 256
+
+	// eventsKeepAlive the interval of the comment sent to keep an idle events stream open
+	eventsKeepAlive = 30 * time.Second
+)
+
+// watch will apply the events to the service data
+func (h *DashboardHTTPHandler) watch(serviceEvents <-chan events.Event) {
+	for event := range serviceEvents {
+		h.serviceDataMutex.Lock()
+
+		service, ok := h.serviceData[event.Service]
+		if ok {
+			h.history.Apply(event)
+
+			switch event.Kind {
+			case events.Check:
+				service["lastCheckTime"] = event.CheckedAt
+				service["latency"] = event.Latency
+				service["message"] = event.Message
+				if event.Results != nil {
+					service["results"] = event.Results
+				}
+			case events.Status:
+				service["status"] = event.Status
+				service["messageDetails"] = event.Message
+				service["lastStatusChange"] = event.CheckedAt
+			}
+		}
+
+		h.serviceDataMutex.Unlock()
+	}
+}
+
+// hasService will return true when the service is shown by the dashboard
+func (h *DashboardHTTPHandler) hasService(name string) bool {
+	h.serviceDataMutex.RLock()
+	defer h.serviceDataMutex.RUnlock()
+
+	_, ok := h.serviceData[name]
+	return ok
+}
+
+// snapshot will return a copy of the service data, the secrets of the service configs are redacted
+func (h *DashboardHTTPHandler) snapshot() map[string]map[string]interface{} {
+	h.serviceDataMutex.RLock()
+	defer h.serviceDataMutex.RUnlock()
+
+	serviceData := make(map[string]map[string]interface{}, len(h.serviceData))
+	for name, service := range h.serviceData {
+		serviceData[name] = utils.Redact(service).(map[string]interface{})
+	}
+
+	return serviceData
+}
+
+// StreamEvents will stream the check and status events of the services as server-sent events
+func (h *DashboardHTTPHandler) StreamEvents() http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			shared.BuildJSONResponse(resp, shared.Response[shared.EmptyJSON]{
+				Success: false,
+				Code:    405,
+				Message: "http method not valid",
+				Data:    shared.EmptyJSON{},
+			}, 405)
+			return
+		}
+
+		flusher, ok := resp.(http.Flusher)
+		if !ok {
+			shared.BuildJSONResponse(resp, shared.Response[shared.EmptyJSON]{
+				Success: false,
+				Code:    500,
+				Message: "streaming is not supported",
+				Data:    shared.EmptyJSON{},
+			}, 500)
+			return
+		}
+
+		serviceEvents, unsubscribe := h.events.Subscribe(eventsBufferSize)
+		defer unsubscribe()
+
+		resp.Header().Set("Content-Type", "text/event-stream")
+		resp.Header().Set("Cache-Control", "no-cache")
+		resp.Header().Set("Connection", "keep-alive")
+		resp.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		keepAlive := time.NewTicker(eventsKeepAlive)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case <-req.Context().Done():
+				return
+			case <-keepAlive.C:
+				fmt.Fprint(resp, ": keep-alive\n\n")
+				flusher.Flush()
+			case event, ok := <-serviceEvents:
+				if !ok {
+					return
+				}
+
+				if !h.hasService(event.Service) {
+					continue
+				}
+
+				payload, err := json.Marshal(event)
+				if err != nil {
+					h.logger.Println(err)
+					continue
+				}
+
+				fmt.Fprintf(resp, "event: %s\ndata: %s\n\n", event.Kind, payload)
+				flusher.Flush()
+			}
+		}
+	}
+}
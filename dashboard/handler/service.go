@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/telkomdev/tob/dashboard/history"
+	"github.com/telkomdev/tob/dashboard/shared"
+	"github.com/telkomdev/tob/dashboard/utils"
+)
+
+const (
+	// ServicePath the path of the service detail, the url is /api/services/<name>
+	ServicePath = "/api/services/"
+)
+
+// ServiceDetail type
+type ServiceDetail struct {
+	Name string `json:"name"`
+
+	// Service the dashboard data of the service, the secrets of its config are redacted
+	Service map[string]interface{} `json:"service"`
+	history.ServiceHistory
+}
+
+// GetService will return the dashboard data, the recent checks and the incidents of a tob service
+func (h *DashboardHTTPHandler) GetService() http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			shared.BuildJSONResponse(resp, shared.Response[shared.EmptyJSON]{
+				Success: false,
+				Code:    405,
+				Message: "http method not valid",
+				Data:    shared.EmptyJSON{},
+			}, 405)
+			return
+		}
+
+		name := strings.TrimPrefix(req.URL.Path, ServicePath)
+
+		h.serviceDataMutex.RLock()
+		service, ok := h.serviceData[name]
+		if ok {
+			service = utils.Redact(service).(map[string]interface{})
+		}
+		h.serviceDataMutex.RUnlock()
+
+		if !ok {
+			shared.BuildJSONResponse(resp, shared.Response[shared.EmptyJSON]{
+				Success: false,
+				Code:    404,
+				Message: "service not found",
+				Data:    shared.EmptyJSON{},
+			}, 404)
+			return
+		}
+
+		shared.BuildJSONResponse(resp, shared.Response[ServiceDetail]{
+			Success: true,
+			Code:    200,
+			Message: "get service succeed",
+			Data: ServiceDetail{
+				Name:           name,
+				Service:        service,
+				ServiceHistory: h.history.Service(name),
+			},
+		}, 200)
+	}
+}
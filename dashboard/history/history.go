@@ -0,0 +1,146 @@
+package history
+
+import (
+	"sync"
+	"time"
+
+	"github.com/telkomdev/tob/events"
+)
+
+const (
+	// MaxChecks the check results kept for each service
+	MaxChecks = 720
+
+	// MaxIncidents the incidents kept for each service
+	MaxIncidents = 100
+)
+
+// Check represent the result of a check
+type Check struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	Status    string    `json:"status"`
+	Latency   int64     `json:"latency"`
+	Message   string    `json:"message"`
+}
+
+// Incident represent a period where a service is DOWN or DEGRADED,
+// EndedAt is nil while the incident is open
+type Incident struct {
+	Status    string     `json:"status"`
+	Message   string     `json:"message"`
+	StartedAt time.Time  `json:"startedAt"`
+	EndedAt   *time.Time `json:"endedAt"`
+
+	// Duration in seconds, up to now while the incident is open
+	Duration int64 `json:"duration"`
+}
+
+// ServiceHistory represent the recent checks and incidents of a service, the latest is the last
+type ServiceHistory struct {
+	Checks    []Check    `json:"checks"`
+	Incidents []Incident `json:"incidents"`
+}
+
+// History keeps the recent checks and incidents of every service in memory
+type History struct {
+	mutex    sync.RWMutex
+	services map[string]*ServiceHistory
+}
+
+// NewHistory History's constructor
+func NewHistory() *History {
+	return &History{
+		services: make(map[string]*ServiceHistory),
+	}
+}
+
+// isIncident will return true when the status is an incident
+func isIncident(status string) bool {
+	return status == "DOWN" || status == "DEGRADED"
+}
+
+// Apply will record the event, a check event adds a check result,
+// a DOWN or DEGRADED event opens an incident and an UP event closes it.
+// The other statuses (eg: MONITORED, CHECKING) are published on every check, they do not close an incident
+func (h *History) Apply(event events.Event) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	service, ok := h.services[event.Service]
+	if !ok {
+		service = &ServiceHistory{}
+		h.services[event.Service] = service
+	}
+
+	if event.Kind == events.Check {
+		service.Checks = append(service.Checks, Check{
+			CheckedAt: event.CheckedAt,
+			Status:    event.Status,
+			Latency:   event.Latency,
+			Message:   event.Message,
+		})
+
+		if len(service.Checks) > MaxChecks {
+			service.Checks = service.Checks[len(service.Checks)-MaxChecks:]
+		}
+	}
+
+	var open *Incident
+	if last := len(service.Incidents) - 1; last >= 0 && service.Incidents[last].EndedAt == nil {
+		open = &service.Incidents[last]
+	}
+
+	switch {
+	case isIncident(event.Status) && open == nil:
+		service.Incidents = append(service.Incidents, Incident{
+			Status:    event.Status,
+			Message:   event.Message,
+			StartedAt: event.CheckedAt,
+		})
+
+		if len(service.Incidents) > MaxIncidents {
+			service.Incidents = service.Incidents[len(service.Incidents)-MaxIncidents:]
+		}
+	case isIncident(event.Status):
+		// the worst status of the incident is kept
+		if event.Status == "DOWN" {
+			open.Status = event.Status
+		}
+
+		if event.Message != "" {
+			open.Message = event.Message
+		}
+	case event.Status == "UP" && open != nil:
+		endedAt := event.CheckedAt
+		open.EndedAt = &endedAt
+		open.Duration = int64(endedAt.Sub(open.StartedAt).Seconds())
+		if open.Duration < 0 {
+			open.Duration = 0
+		}
+	}
+}
+
+// Service will return a copy of the history of the service
+func (h *History) Service(name string) ServiceHistory {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	service, ok := h.services[name]
+	if !ok {
+		return ServiceHistory{Checks: []Check{}, Incidents: []Incident{}}
+	}
+
+	history := ServiceHistory{
+		Checks:    append([]Check{}, service.Checks...),
+		Incidents: append([]Incident{}, service.Incidents...),
+	}
+
+	now := time.Now()
+	for i := range history.Incidents {
+		if history.Incidents[i].EndedAt == nil {
+			history.Incidents[i].Duration = int64(now.Sub(history.Incidents[i].StartedAt).Seconds())
+		}
+	}
+
+	return history
+}
@@ -13,8 +13,11 @@ import (
 	"github.com/telkomdev/tob/config"
 	"github.com/telkomdev/tob/dashboard/handler"
 	"github.com/telkomdev/tob/dashboard/middleware"
+	"github.com/telkomdev/tob/dashboard/statuspage"
 	"github.com/telkomdev/tob/dashboard/ui"
 	"github.com/telkomdev/tob/dashboard/utils"
+	"github.com/telkomdev/tob/events"
+	"github.com/telkomdev/tob/services/heartbeat"
 )
 
 var (
@@ -29,11 +32,15 @@ type HTTPServer struct {
 
 	jwtService utils.JwtService
 
+	dashboardEnabled      bool
 	dashboardStaticAssets fs.FS
 	dashboardHTTPHandler  *handler.DashboardHTTPHandler
+
+	// statusPage is nil when the public status page is disabled
+	statusPage *statuspage.StatusPage
 }
 
-func NewHTTPServer(configs config.Config, logger *log.Logger) (*HTTPServer, error) {
+func NewHTTPServer(configs config.Config, logger *log.Logger, bus *events.Bus) (*HTTPServer, error) {
 	// dashboard HTTP Port
 	if parsedDashboardHTTPPort, ok := configs["dashboardHttpPort"].(float64); ok {
 		defaultDashboardHTTPPort = int(parsedDashboardHTTPPort)
@@ -51,17 +58,33 @@ func NewHTTPServer(configs config.Config, logger *log.Logger) (*HTTPServer, erro
 		return nil, err
 	}
 
-	dashboardHTTPHandler, err := handler.NewDashboardHTTPHandler(configs, logger)
+	dashboardHTTPHandler, err := handler.NewDashboardHTTPHandler(configs, logger, bus)
 	if err != nil {
 		return nil, err
 	}
 
+	// the authenticated dashboard is enabled by default
+	dashboardEnabled, ok := configs["dashboardEnable"].(bool)
+	if !ok {
+		dashboardEnabled = true
+	}
+
+	var statusPage *statuspage.StatusPage
+	if statuspage.Enabled(configs) {
+		statusPage, err = statuspage.NewStatusPage(configs, bus, logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &HTTPServer{
 		configs:               configs,
 		port:                  defaultDashboardHTTPPort,
 		logger:                logger,
+		dashboardEnabled:      dashboardEnabled,
 		dashboardStaticAssets: dashboardStaticAssets,
 		dashboardHTTPHandler:  dashboardHTTPHandler,
+		statusPage:            statusPage,
 		jwtService:            jwtService,
 	}, nil
 }
@@ -74,7 +97,19 @@ func (s *HTTPServer) Run() {
 
 	var index = func() http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// the UI has nothing to show without the dashboard and the status page
+			if !s.dashboardEnabled && s.statusPage == nil {
+				http.NotFound(w, r)
+				return
+			}
+
 			if r.URL.Path == "/" {
+				// only the status page is served when the dashboard is disabled
+				if !s.dashboardEnabled {
+					http.Redirect(w, r, statuspage.Path, http.StatusFound)
+					return
+				}
+
 				dashboardFs.ServeHTTP(w, r)
 				return
 			}
@@ -94,9 +129,20 @@ func (s *HTTPServer) Run() {
 	// mux.Handle("/", http.StripPrefix("/", dashboardFs))
 	mux.Handle("/", index())
 
-	mux.HandleFunc("/api/login", s.dashboardHTTPHandler.Login(s.jwtService))
-	mux.Handle("/api/services", middleware.JWTMiddleware(s.jwtService, s.dashboardHTTPHandler.GetServices()))
+	if s.dashboardEnabled {
+		mux.HandleFunc("/api/login", s.dashboardHTTPHandler.Login(s.jwtService))
+		mux.Handle("/api/services", middleware.JWTMiddleware(s.jwtService, s.dashboardHTTPHandler.GetServices()))
+		mux.Handle(handler.ServicePath, middleware.JWTMiddleware(s.jwtService, s.dashboardHTTPHandler.GetService()))
+		mux.Handle("/api/events", middleware.JWTMiddleware(s.jwtService, s.dashboardHTTPHandler.StreamEvents()))
+	}
+
+	if s.statusPage != nil {
+		mux.HandleFunc(statuspage.APIPath, s.statusPage.GetStatus())
+	}
+
 	mux.HandleFunc("/api/tob/webhook", s.dashboardHTTPHandler.HandleTobWebhook())
+	mux.HandleFunc("/api/tob/agent/report", s.dashboardHTTPHandler.HandleAgentReport())
+	mux.HandleFunc(heartbeat.PingPath, s.dashboardHTTPHandler.HandleHeartbeat())
 
 	log.Printf("Dashboard HTTP server running on port %d\n", s.port)
 
@@ -107,4 +153,11 @@ func (s *HTTPServer) Run() {
 // Exit will exit and cleanup Dashboard HTTP Server
 func (s *HTTPServer) Exit() {
 	s.logger.Print("exiting Dashboard HTTP server\n")
+
+	if s.statusPage != nil {
+		err := s.statusPage.Close()
+		if err != nil {
+			s.logger.Printf("status page: save uptime error: %s\n", err.Error())
+		}
+	}
 }
@@ -0,0 +1,439 @@
+package statuspage
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/telkomdev/tob/config"
+	"github.com/telkomdev/tob/dashboard/shared"
+	"github.com/telkomdev/tob/events"
+)
+
+const (
+	// Path the path of the public status page
+	Path = "/status"
+
+	// APIPath the path of the public status API
+	APIPath = "/api/status"
+
+	// saveInterval the interval of saving the uptime to the uptimeFile
+	saveInterval = 5 * time.Minute
+
+	// eventsBufferSize the events buffered before they are dropped
+	eventsBufferSize = 256
+)
+
+const (
+	// Operational every service of the component is UP
+	Operational = "operational"
+
+	// Degraded a service of the component is DEGRADED
+	Degraded = "degraded"
+
+	// Outage a service of the component is DOWN
+	Outage = "outage"
+
+	// Unknown the services of the component are not checked yet
+	Unknown = "unknown"
+)
+
+var (
+	defaultStatusPageTitle = "Status"
+)
+
+// Incident represent an incident note published on the status page
+type Incident struct {
+	Title      string    `json:"title"`
+	Message    string    `json:"message"`
+	Status     string    `json:"status"`
+	Components []string  `json:"components"`
+	CreatedAt  time.Time `json:"createdAt"`
+
+	// ResolvedAt is nil while the incident is not resolved
+	ResolvedAt *time.Time `json:"resolvedAt"`
+}
+
+// DayStatus represent the uptime of a component on a day
+type DayStatus struct {
+	Date string `json:"date"`
+
+	// Uptime percent, nil when there is no check on the day
+	Uptime *float64 `json:"uptime"`
+	Status string   `json:"status"`
+}
+
+// ComponentStatus represent the status of a component, the services of the component are not exposed
+type ComponentStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+
+	// Uptime percent of the last UptimeDays, nil when there is no check
+	Uptime *float64    `json:"uptime"`
+	Days   []DayStatus `json:"days"`
+}
+
+// Status represent the public status page
+type Status struct {
+	Title      string            `json:"title"`
+	Status     string            `json:"status"`
+	Components []ComponentStatus `json:"components"`
+	Incidents  []Incident        `json:"incidents"`
+	UpdatedAt  time.Time         `json:"updatedAt"`
+}
+
+// component represent a named group of services selected by tags
+type component struct {
+	name     string
+	services []string
+}
+
+// StatusPage the public read-only status page
+type StatusPage struct {
+	title      string
+	components []component
+	incidents  []Incident
+	uptimeFile string
+	uptime     *Uptime
+	logger     *log.Logger
+
+	statusesMutex sync.RWMutex
+
+	// statuses the current status of each service
+	statuses map[string]string
+}
+
+// Enabled will return true when the status page is enabled in the config
+func Enabled(configs config.Config) bool {
+	statusPageConfig, _ := configs["statusPage"].(map[string]interface{})
+	enabled, _ := statusPageConfig["enable"].(bool)
+	return enabled
+}
+
+// NewStatusPage StatusPage's constructor, the status page follows the checks of the bus
+func NewStatusPage(configs config.Config, bus *events.Bus, logger *log.Logger) (*StatusPage, error) {
+	statusPageConfig, ok := configs["statusPage"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("cannot parse statusPage from configs")
+	}
+
+	title, ok := statusPageConfig["title"].(string)
+	if !ok || title == "" {
+		title = defaultStatusPageTitle
+	}
+
+	uptimeFile, _ := statusPageConfig["uptimeFile"].(string)
+
+	components, err := parseComponents(configs, statusPageConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	incidents, err := parseIncidents(statusPageConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	uptime := NewUptime()
+	if uptimeFile != "" {
+		err = uptime.Load(uptimeFile)
+		if err != nil {
+			return nil, fmt.Errorf("statusPage uptimeFile: %s", err.Error())
+		}
+	}
+
+	s := &StatusPage{
+		title:      title,
+		components: components,
+		incidents:  incidents,
+		uptimeFile: uptimeFile,
+		uptime:     uptime,
+		logger:     logger,
+		statuses:   make(map[string]string),
+	}
+
+	serviceEvents, _ := bus.Subscribe(eventsBufferSize)
+	go s.watch(serviceEvents)
+
+	if uptimeFile != "" {
+		go s.saveEvery(saveInterval)
+	}
+
+	return s, nil
+}
+
+// parseComponents will parse the components, a component has the enabled services with one of its tags,
+// eg: "components": [{"name": "API", "tags": ["api"]}]
+func parseComponents(configs config.Config, statusPageConfig map[string]interface{}) ([]component, error) {
+	componentsInterface, ok := statusPageConfig["components"].([]interface{})
+	if !ok || len(componentsInterface) == 0 {
+		return nil, errors.New("statusPage components is required")
+	}
+
+	services, _ := configs["service"].(map[string]interface{})
+
+	var components []component
+	for _, componentInterface := range componentsInterface {
+		conf, ok := componentInterface.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("statusPage component is not valid")
+		}
+
+		name, ok := conf["name"].(string)
+		if !ok || name == "" {
+			return nil, errors.New("statusPage component name is required")
+		}
+
+		tags := make(map[string]bool)
+		tagsInterface, _ := conf["tags"].([]interface{})
+		for _, tag := range tagsInterface {
+			if tagStr, ok := tag.(string); ok {
+				tags[tagStr] = true
+			}
+		}
+
+		if len(tags) == 0 {
+			return nil, fmt.Errorf("statusPage component %s: tags is required", name)
+		}
+
+		c := component{name: name}
+		for serviceName, serviceInterface := range services {
+			serviceConfig, ok := serviceInterface.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			if enabled, _ := serviceConfig["enable"].(bool); !enabled {
+				continue
+			}
+
+			serviceTags, _ := serviceConfig["tags"].([]interface{})
+			for _, tag := range serviceTags {
+				if tagStr, ok := tag.(string); ok && tags[tagStr] {
+					c.services = append(c.services, serviceName)
+					break
+				}
+			}
+		}
+
+		sort.Strings(c.services)
+		components = append(components, c)
+	}
+
+	return components, nil
+}
+
+// parseIncidents will parse the published incident notes, the latest is the first
+func parseIncidents(statusPageConfig map[string]interface{}) ([]Incident, error) {
+	incidentsInterface, _ := statusPageConfig["incidents"].([]interface{})
+
+	incidents := []Incident{}
+	for _, incidentInterface := range incidentsInterface {
+		conf, ok := incidentInterface.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("statusPage incident is not valid")
+		}
+
+		var incident Incident
+		incident.Title, _ = conf["title"].(string)
+		incident.Message, _ = conf["message"].(string)
+		incident.Status, _ = conf["status"].(string)
+
+		componentsInterface, _ := conf["components"].([]interface{})
+		for _, c := range componentsInterface {
+			if name, ok := c.(string); ok {
+				incident.Components = append(incident.Components, name)
+			}
+		}
+
+		createdAt, _ := conf["createdAt"].(string)
+		t, err := time.Parse(time.RFC3339, createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("statusPage incident %s: createdAt must be RFC3339, eg: 2024-01-02T15:04:05+07:00", incident.Title)
+		}
+
+		incident.CreatedAt = t
+
+		if resolvedAt, ok := conf["resolvedAt"].(string); ok && resolvedAt != "" {
+			t, err := time.Parse(time.RFC3339, resolvedAt)
+			if err != nil {
+				return nil, fmt.Errorf("statusPage incident %s: resolvedAt must be RFC3339, eg: 2024-01-02T15:04:05+07:00", incident.Title)
+			}
+
+			incident.ResolvedAt = &t
+		}
+
+		incidents = append(incidents, incident)
+	}
+
+	sort.SliceStable(incidents, func(i, j int) bool {
+		return incidents[i].CreatedAt.After(incidents[j].CreatedAt)
+	})
+
+	return incidents, nil
+}
+
+// watch will apply the check events to the current statuses and the uptime,
+// the status events (eg: MONITORED, CHECKING) do not tell whether the service is up
+func (s *StatusPage) watch(serviceEvents <-chan events.Event) {
+	for event := range serviceEvents {
+		if event.Kind != events.Check {
+			continue
+		}
+
+		s.uptime.Apply(event)
+
+		s.statusesMutex.Lock()
+		s.statuses[event.Service] = event.Status
+		s.statusesMutex.Unlock()
+	}
+}
+
+func (s *StatusPage) saveEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		err := s.uptime.Save(s.uptimeFile)
+		if err != nil {
+			s.logger.Printf("status page: save uptime error: %s\n", err.Error())
+		}
+	}
+}
+
+// Close will save the uptime to the uptimeFile
+func (s *StatusPage) Close() error {
+	if s.uptimeFile == "" {
+		return nil
+	}
+
+	return s.uptime.Save(s.uptimeFile)
+}
+
+// worse will return the worse status
+func worse(a, b string) string {
+	rank := map[string]int{Unknown: 0, Operational: 1, Degraded: 2, Outage: 3}
+	if rank[b] > rank[a] {
+		return b
+	}
+
+	return a
+}
+
+// componentStatus will return the current status of the component
+func (s *StatusPage) componentStatus(c component) string {
+	s.statusesMutex.RLock()
+	defer s.statusesMutex.RUnlock()
+
+	status := Unknown
+	for _, service := range c.services {
+		switch s.statuses[service] {
+		case "":
+		case "DOWN":
+			status = worse(status, Outage)
+		case "DEGRADED":
+			status = worse(status, Degraded)
+		default:
+			status = worse(status, Operational)
+		}
+	}
+
+	return status
+}
+
+// uptimePercent will return the percent of the checks that are not DOWN
+func uptimePercent(count DayCount) *float64 {
+	if count.Total() == 0 {
+		return nil
+	}
+
+	percent := float64(count.Up+count.Degraded) * 100 / float64(count.Total())
+	return &percent
+}
+
+// dayStatus will return the status of a day, a day with less than 99% uptime is an outage
+func dayStatus(count DayCount) string {
+	percent := uptimePercent(count)
+	switch {
+	case percent == nil:
+		return Unknown
+	case *percent < 99:
+		return Outage
+	case count.Down > 0 || count.Degraded > 0:
+		return Degraded
+	default:
+		return Operational
+	}
+}
+
+// Status will return the current status of the status page
+func (s *StatusPage) Status() Status {
+	now := time.Now()
+
+	status := Status{
+		Title:      s.title,
+		Status:     Unknown,
+		Components: []ComponentStatus{},
+		Incidents:  s.incidents,
+		UpdatedAt:  now,
+	}
+
+	for _, c := range s.components {
+		componentStatus := ComponentStatus{
+			Name:   c.name,
+			Status: s.componentStatus(c),
+			Days:   make([]DayStatus, 0, UptimeDays),
+		}
+
+		var total DayCount
+		for i := UptimeDays - 1; i >= 0; i-- {
+			day := now.AddDate(0, 0, -i)
+			count := s.uptime.Day(c.services, day)
+
+			total.Up += count.Up
+			total.Degraded += count.Degraded
+			total.Down += count.Down
+
+			componentStatus.Days = append(componentStatus.Days, DayStatus{
+				Date:   day.Format(dayLayout),
+				Uptime: uptimePercent(count),
+				Status: dayStatus(count),
+			})
+		}
+
+		componentStatus.Uptime = uptimePercent(total)
+
+		status.Status = worse(status.Status, componentStatus.Status)
+		status.Components = append(status.Components, componentStatus)
+	}
+
+	return status
+}
+
+// GetStatus will return the public status, it does not require authentication
+func (s *StatusPage) GetStatus() http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			shared.BuildJSONResponse(resp, shared.Response[shared.EmptyJSON]{
+				Success: false,
+				Code:    405,
+				Message: "http method not valid",
+				Data:    shared.EmptyJSON{},
+			}, 405)
+			return
+		}
+
+		resp.Header().Set("Cache-Control", "no-cache")
+
+		shared.BuildJSONResponse(resp, shared.Response[Status]{
+			Success: true,
+			Code:    200,
+			Message: "get status succeed",
+			Data:    s.Status(),
+		}, 200)
+	}
+}
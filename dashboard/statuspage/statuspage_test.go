@@ -0,0 +1,99 @@
+package statuspage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/telkomdev/tob/events"
+)
+
+func TestStatusPageWatch(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		events []events.Event
+		status string
+	}{
+		{
+			name:   "not checked yet",
+			status: Unknown,
+		},
+		{
+			name: "up",
+			events: []events.Event{
+				{Kind: events.Check, Service: "ssl", Status: "UP", CheckedAt: now},
+			},
+			status: Operational,
+		},
+		{
+			name: "monitored after a down check",
+			events: []events.Event{
+				{Kind: events.Check, Service: "ssl", Status: "DOWN", CheckedAt: now},
+				{Kind: events.Status, Service: "ssl", Status: "MONITORED", CheckedAt: now},
+			},
+			status: Outage,
+		},
+		{
+			name: "checking after a degraded check",
+			events: []events.Event{
+				{Kind: events.Check, Service: "airflow", Status: "DEGRADED", CheckedAt: now},
+				{Kind: events.Status, Service: "airflow", Status: "CHECKING", CheckedAt: now},
+			},
+			status: Degraded,
+		},
+		{
+			name: "webhook status without check",
+			events: []events.Event{
+				{Kind: events.Status, Service: "ssl", Status: "UP", CheckedAt: now},
+			},
+			status: Unknown,
+		},
+		{
+			name: "worst service of the component",
+			events: []events.Event{
+				{Kind: events.Check, Service: "ssl", Status: "UP", CheckedAt: now},
+				{Kind: events.Check, Service: "airflow", Status: "DOWN", CheckedAt: now},
+				{Kind: events.Status, Service: "airflow", Status: "CHECKING", CheckedAt: now},
+			},
+			status: Outage,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &StatusPage{
+				components: []component{{name: "API", services: []string{"airflow", "ssl"}}},
+				incidents:  []Incident{},
+				uptime:     NewUptime(),
+				statuses:   make(map[string]string),
+			}
+
+			serviceEvents := make(chan events.Event, len(test.events))
+			for _, event := range test.events {
+				serviceEvents <- event
+			}
+
+			close(serviceEvents)
+			s.watch(serviceEvents)
+
+			status := s.Status()
+			if status.Status != test.status || status.Components[0].Status != test.status {
+				t.Errorf("expected status %s, got %s (component %s)", test.status, status.Status, status.Components[0].Status)
+			}
+		})
+	}
+}
+
+func TestUptimeIgnoresStatusEvents(t *testing.T) {
+	now := time.Now()
+
+	uptime := NewUptime()
+	uptime.Apply(events.Event{Kind: events.Check, Service: "ssl", Status: "DOWN", CheckedAt: now})
+	uptime.Apply(events.Event{Kind: events.Status, Service: "ssl", Status: "MONITORED", CheckedAt: now})
+
+	count := uptime.Day([]string{"ssl"}, now)
+	if count.Down != 1 || count.Total() != 1 {
+		t.Errorf("expected a single down check, got %+v", count)
+	}
+}
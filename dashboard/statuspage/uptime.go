@@ -0,0 +1,150 @@
+package statuspage
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/telkomdev/tob/events"
+)
+
+const (
+	// UptimeDays the days of uptime shown by the status page
+	UptimeDays = 90
+
+	// dayLayout the layout of the uptime days
+	dayLayout = "2006-01-02"
+)
+
+// DayCount represent the check results of a service on a day
+type DayCount struct {
+	Up       int `json:"up"`
+	Degraded int `json:"degraded"`
+	Down     int `json:"down"`
+}
+
+// Total will return the number of checks of the day
+func (d DayCount) Total() int {
+	return d.Up + d.Degraded + d.Down
+}
+
+// Uptime keeps the daily check results of every service
+type Uptime struct {
+	mutex sync.RWMutex
+
+	// days the check results of each service by day
+	days map[string]map[string]*DayCount
+}
+
+// NewUptime Uptime's constructor
+func NewUptime() *Uptime {
+	return &Uptime{
+		days: make(map[string]map[string]*DayCount),
+	}
+}
+
+// Apply will count the result of a check event on the day of the check
+func (u *Uptime) Apply(event events.Event) {
+	if event.Kind != events.Check {
+		return
+	}
+
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	days := u.days[event.Service]
+	if days == nil {
+		days = make(map[string]*DayCount)
+		u.days[event.Service] = days
+	}
+
+	day := event.CheckedAt.Format(dayLayout)
+	count, ok := days[day]
+	if !ok {
+		count = &DayCount{}
+		days[day] = count
+	}
+
+	switch event.Status {
+	case "UP":
+		count.Up++
+	case "DEGRADED":
+		count.Degraded++
+	default:
+		count.Down++
+	}
+}
+
+// Day will return the sum of the check results of the services on the day
+func (u *Uptime) Day(services []string, day time.Time) DayCount {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+
+	var sum DayCount
+	for _, service := range services {
+		if count, ok := u.days[service][day.Format(dayLayout)]; ok {
+			sum.Up += count.Up
+			sum.Degraded += count.Degraded
+			sum.Down += count.Down
+		}
+	}
+
+	return sum
+}
+
+// prune will remove the days older than UptimeDays
+func (u *Uptime) prune(now time.Time) {
+	oldest := now.AddDate(0, 0, -UptimeDays).Format(dayLayout)
+
+	for _, days := range u.days {
+		for day := range days {
+			if day < oldest {
+				delete(days, day)
+			}
+		}
+	}
+}
+
+// Load will load the uptime saved in the file, a missing file is an empty uptime
+func (u *Uptime) Load(file string) error {
+	content, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	err = json.Unmarshal(content, &u.days)
+	if u.days == nil {
+		u.days = make(map[string]map[string]*DayCount)
+	}
+
+	return err
+}
+
+// Save will save the uptime of the last UptimeDays to the file
+func (u *Uptime) Save(file string) error {
+	u.mutex.Lock()
+	u.prune(time.Now())
+	content, err := json.Marshal(u.days)
+	u.mutex.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	// write then rename, so a crash never leaves a truncated file
+	tmp := file + ".tmp"
+	err = os.WriteFile(tmp, content, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, file)
+}
@@ -0,0 +1,37 @@
+// subscribeEvents will read the server-sent events of /api/events and call onEvent with each event,
+// fetch is used instead of EventSource to send the Authorization header
+export async function subscribeEvents(token, signal, onEvent) {
+  const response = await fetch('/api/events', {
+      method: 'GET',
+      headers: {
+          'Authorization': token
+      },
+      signal,
+  });
+
+  if (!response.ok || !response.body) {
+    throw new Error('Failed to subscribe to service events');
+  }
+
+  const reader = response.body.getReader();
+  const decoder = new TextDecoder();
+  let buffer = '';
+
+  while (true) {
+    const { value, done } = await reader.read();
+    if (done) {
+      return;
+    }
+
+    buffer += decoder.decode(value, { stream: true });
+    const messages = buffer.split('\n\n');
+    buffer = messages.pop();
+
+    messages.forEach(message => {
+      const dataLine = message.split('\n').find(line => line.startsWith('data: '));
+      if (dataLine) {
+        onEvent(JSON.parse(dataLine.slice('data: '.length)));
+      }
+    });
+  }
+}
@@ -1,7 +1,7 @@
 import React from 'react';
 import ReactDOM from 'react-dom/client';
 import reportWebVitals from './reportWebVitals';
-import { Dashboard, ErrorPage, Login } from './pages'
+import { Dashboard, ErrorPage, Login, Service, Status } from './pages'
 import {
   createBrowserRouter,
   RouterProvider
@@ -18,6 +18,16 @@ const router = createBrowserRouter([
     element: <Dashboard/>,
     errorElement: <ErrorPage/>
   },
+  {
+    path: "/service/:name",
+    element: <Service/>,
+    errorElement: <ErrorPage/>
+  },
+  {
+    path: "/status",
+    element: <Status/>,
+    errorElement: <ErrorPage/>
+  },
 ]);
 
 const root = ReactDOM.createRoot(document.getElementById('root'));
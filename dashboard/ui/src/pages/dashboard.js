@@ -1,5 +1,6 @@
 import React, { useEffect, useState } from 'react';
 import { useNavigate } from 'react-router-dom';
+import { subscribeEvents } from '../events';
 
 function Dashboard() {
   const [services, setServices] = useState([]);
@@ -20,6 +21,24 @@ function Dashboard() {
   }, [username]);
 
   useEffect(() => {
+    const statusPriority = {
+      DOWN: 0,
+      DEGRADED: 1,
+      CHECKING: 2,
+      MONITORED: 3,
+      UP: 4,
+    };
+
+    const sortServices = (serviceArray) => {
+      return [...serviceArray].sort((a, b) => {
+        return statusPriority[a.status] - statusPriority[b.status];
+      });
+    };
+
+    const formatCheckTime = (lastCheckTime) => {
+      return lastCheckTime ? new Date(lastCheckTime).toLocaleString() : 'not checked yet';
+    };
+
     const fetchServiceData = async () => {
       try {
 
@@ -37,7 +56,6 @@ function Dashboard() {
         if (result.success) {
           const serviceArray = Object.keys(result.data.data).map(key => {
             const service = result.data.data[key];
-            const latestCheckTime = new Date(Date.now() - service.checkInterval * 1000);
 
             if (service.tags) {
               service.tags.push(service.kind);
@@ -46,22 +64,11 @@ function Dashboard() {
             return {
               name: key,
               ...service,
-              latestCheckTime: latestCheckTime.toLocaleString(),
+              latestCheckTime: formatCheckTime(service.lastCheckTime),
             };
           });
 
-          const statusPriority = {
-            DOWN: 0,
-            CHECKING: 1,
-            MONITORED: 2,
-            UP: 3,
-          };
-          
-          serviceArray.sort((a, b) => {
-            return statusPriority[a.status] - statusPriority[b.status];
-          });
-
-          setServices(serviceArray);
+          setServices(sortServices(serviceArray));
           setDashboardTitle(result.data.dashboardTitle);
         } else {
           if (result.message) {
@@ -82,9 +89,54 @@ function Dashboard() {
       }
     };
 
-    fetchServiceData();
-    const intervalId = setInterval(fetchServiceData, 5000);
-    return () => clearInterval(intervalId);
+    // applyEvent will apply a check or status event of /api/events to its service
+    const applyEvent = (event) => {
+      setServices(previous => sortServices(previous.map(service => {
+        if (service.name !== event.service) {
+          return service;
+        }
+
+        if (event.kind === 'check') {
+          return {
+            ...service,
+            lastCheckTime: event.checkedAt,
+            latency: event.latency,
+            message: event.message,
+            latestCheckTime: formatCheckTime(event.checkedAt),
+          };
+        }
+
+        return {
+          ...service,
+          status: event.status,
+          messageDetails: event.message,
+          lastStatusChange: event.checkedAt,
+        };
+      })));
+    };
+
+    const controller = new AbortController();
+
+    const run = async () => {
+      while (!controller.signal.aborted) {
+        // the snapshot catches up the events missed while disconnected
+        await fetchServiceData();
+
+        try {
+          await subscribeEvents(token, controller.signal, applyEvent);
+        } catch (err) {
+          if (controller.signal.aborted) {
+            return;
+          }
+        }
+
+        // reconnect after 5 seconds
+        await new Promise(resolve => setTimeout(resolve, 5000));
+      }
+    };
+
+    run();
+    return () => controller.abort();
   }, [token]);
 
   const logout = () => {
@@ -122,6 +174,7 @@ function Dashboard() {
       backgroundColor:
         status === 'UP' ? '#28a745' :
         status === 'DOWN' ? '#dc3545' :
+        status === 'DEGRADED' ? '#fd7e14' :
         status === 'MONITORED' ? '#ffc107' : 
         '#b4e83a', 
       whiteSpace: 'nowrap',
@@ -136,6 +189,7 @@ function Dashboard() {
       color:
         service.status === 'UP' ? '#28a745' :
         service.status === 'DOWN' ? '#dc3545' :
+        service.status === 'DEGRADED' ? '#fd7e14' :
         service.status === 'MONITORED' ? '#ffc107' :
         '#b4e83a',
       marginBottom: '10px',
@@ -282,13 +336,20 @@ function Dashboard() {
                 overflowWrap: 'break-word', // IE and Edge support
                 maxWidth: 'calc(100% - 120px)', // Adjust based on status box size
               }}>
-                {service.name}
+                <span
+                  onClick={() => navigate(`/service/${encodeURIComponent(service.name)}`)}
+                  title="Show the service history"
+                  style={{ cursor: 'pointer' }}>
+                  {service.name}
+                </span>
               </span>
               <span style={getStatusStyle(service.status)}>
                 {service.status === 'UP'
                   ? 'OK'
                   : service.status === 'DOWN'
                   ? 'Not OK'
+                  : service.status === 'DEGRADED'
+                  ? 'Degraded'
                   : service.status === 'MONITORED'
                   ? 'Monitored'
                   : service.status == 'CHECKING'
@@ -385,6 +446,7 @@ function Dashboard() {
                 Last checked:
               </span>{' '}
               {service.latestCheckTime}
+              {service.latency !== undefined && ` (${service.latency} ms)`}
             </span>
             
             {service.tags && (
@@ -1,5 +1,7 @@
 import Login from './login';
 import Dashboard from './dashboard';
 import ErrorPage from './errorpage';
+import Service from './service';
+import Status from './status';
 
-export{ Dashboard, ErrorPage, Login };
\ No newline at end of file
+export{ Dashboard, ErrorPage, Login, Service, Status };
\ No newline at end of file
@@ -0,0 +1,310 @@
+import React, { useEffect, useState } from 'react';
+import { useNavigate, useParams } from 'react-router-dom';
+import { subscribeEvents } from '../events';
+
+// the fields added by the dashboard, the other fields are the service config
+const dashboardFields = ['status', 'messageDetails', 'message', 'lastCheckTime', 'latency', 'lastStatusChange', 'results'];
+
+const statusColors = {
+  UP: '#28a745',
+  DOWN: '#dc3545',
+  DEGRADED: '#fd7e14',
+  MONITORED: '#ffc107',
+  CHECKING: '#b4e83a',
+};
+
+const statusColor = (status) => statusColors[status] || '#b4e83a';
+
+const formatDuration = (seconds) => {
+  if (seconds < 60) {
+    return `${seconds}s`;
+  }
+
+  const minutes = Math.floor(seconds / 60);
+  if (minutes < 60) {
+    return `${minutes}m ${seconds % 60}s`;
+  }
+
+  const hours = Math.floor(minutes / 60);
+  if (hours < 24) {
+    return `${hours}h ${minutes % 60}m`;
+  }
+
+  return `${Math.floor(hours / 24)}d ${hours % 24}h`;
+};
+
+// flappingSummary will describe the incidents of the last 24 hours, eg: 6 incidents since 03:00, every ~10 minutes
+const flappingSummary = (incidents) => {
+  const since = Date.now() - 24 * 60 * 60 * 1000;
+  const recent = incidents.filter(incident => new Date(incident.startedAt).getTime() >= since);
+
+  if (recent.length === 0) {
+    return 'No incident in the last 24 hours';
+  }
+
+  const first = new Date(recent[0].startedAt);
+  if (recent.length === 1) {
+    return `1 incident since ${first.toLocaleTimeString()}`;
+  }
+
+  const last = new Date(recent[recent.length - 1].startedAt);
+  const everyMinutes = Math.round((last - first) / (recent.length - 1) / 60000);
+
+  return `${recent.length} incidents since ${first.toLocaleTimeString()}, every ~${everyMinutes} minutes`;
+};
+
+function LatencyChart({ checks }) {
+  const width = 760;
+  const height = 160;
+
+  if (checks.length < 2) {
+    return <p style={{ color: '#aaa' }}>Not enough checks yet</p>;
+  }
+
+  const maxLatency = Math.max(1, ...checks.map(check => check.latency));
+  const points = checks.map((check, index) => {
+    const x = (index / (checks.length - 1)) * width;
+    const y = height - (check.latency / maxLatency) * (height - 10);
+    return `${x.toFixed(1)},${y.toFixed(1)}`;
+  }).join(' ');
+
+  return (
+    <div style={{ overflowX: 'auto' }}>
+      <svg viewBox={`0 0 ${width} ${height}`} style={{ width: '100%', maxWidth: `${width}px`, backgroundColor: '#1e1e1e', borderRadius: '4px' }}>
+        <polyline points={points} fill="none" stroke="#04a0bf" strokeWidth="2" />
+        <text x="5" y="14" fill="#aaa" fontSize="12">{maxLatency} ms</text>
+        <text x="5" y={height - 5} fill="#aaa" fontSize="12">0 ms</text>
+      </svg>
+      <div style={{ display: 'flex', justifyContent: 'space-between', fontSize: '12px', color: '#aaa', maxWidth: `${width}px` }}>
+        <span>{new Date(checks[0].checkedAt).toLocaleString()}</span>
+        <span>{new Date(checks[checks.length - 1].checkedAt).toLocaleString()}</span>
+      </div>
+    </div>
+  );
+}
+
+function Service() {
+  const { name } = useParams();
+  const [detail, setDetail] = useState(null);
+  const [error, setError] = useState(null);
+  const [token] = useState(localStorage.getItem('token'));
+  const navigate = useNavigate();
+
+  useEffect(() => {
+    if (localStorage.getItem('username') == null) {
+      navigate('/');
+    }
+  }, [navigate]);
+
+  useEffect(() => {
+    const fetchService = async () => {
+      try {
+        const response = await fetch(`/api/services/${encodeURIComponent(name)}`, {
+            method: 'GET',
+            headers: {
+                'Authorization': token
+            },
+        });
+
+        const result = await response.json();
+        if (result.success) {
+          setError(null);
+          setDetail(result.data);
+        } else {
+          setError(result.message || 'Failed to retrieve service');
+        }
+      } catch (err) {
+        setError(err.message);
+      }
+    };
+
+    // the detail is fetched again at most once per second on the events of this service
+    let refetchTimeout = null;
+    const onEvent = (event) => {
+      if (event.service !== name || refetchTimeout) {
+        return;
+      }
+
+      refetchTimeout = setTimeout(() => {
+        refetchTimeout = null;
+        fetchService();
+      }, 1000);
+    };
+
+    const controller = new AbortController();
+
+    const run = async () => {
+      while (!controller.signal.aborted) {
+        await fetchService();
+
+        try {
+          await subscribeEvents(token, controller.signal, onEvent);
+        } catch (err) {
+          if (controller.signal.aborted) {
+            return;
+          }
+        }
+
+        // reconnect after 5 seconds
+        await new Promise(resolve => setTimeout(resolve, 5000));
+      }
+    };
+
+    run();
+    return () => {
+      controller.abort();
+      clearTimeout(refetchTimeout);
+    };
+  }, [name, token]);
+
+  const sectionStyle = {
+    backgroundColor: '#353535',
+    margin: '10px 0',
+    padding: '15px',
+    borderRadius: '8px',
+    boxShadow: '0 2px 8px rgba(0, 0, 0, 0.6)',
+  };
+
+  const cellStyle = { border: '1px solid #555', padding: '6px', textAlign: 'left', verticalAlign: 'top' };
+
+  const config = detail ? Object.keys(detail.service)
+    .filter(key => !dashboardFields.includes(key))
+    .reduce((result, key) => ({ ...result, [key]: detail.service[key] }), {}) : {};
+
+  return (
+    <div style={{ padding: '20px', fontFamily: 'Arial, sans-serif', backgroundColor: '#212121', color: '#f5f5f5', minHeight: '100vh' }}>
+      <div style={{ maxWidth: '800px', margin: '0 auto' }}>
+        <button
+          onClick={() => navigate('/dashboard')}
+          style={{
+            padding: '10px 20px',
+            backgroundColor: '#04a0bf',
+            color: '#fff',
+            border: 'none',
+            borderRadius: '5px',
+            fontWeight: 'bold',
+            cursor: 'pointer',
+            boxShadow: '0 2px 4px rgba(0, 0, 0, 0.5)',
+          }}
+        >
+          Back
+        </button>
+
+        {error && <p>Error: {error}</p>}
+        {!detail && !error && <p>Loading service...</p>}
+        {detail && (
+          <>
+            <div style={sectionStyle}>
+              <div style={{ display: 'flex', justifyContent: 'space-between', flexWrap: 'wrap', marginBottom: '10px' }}>
+                <span style={{ fontSize: '20px', fontWeight: 'bold', wordWrap: 'break-word', maxWidth: 'calc(100% - 120px)' }}>{detail.name}</span>
+                <span style={{ padding: '5px 10px', borderRadius: '4px', color: '#000', fontWeight: 'bold', backgroundColor: statusColor(detail.service.status) }}>
+                  {detail.service.status}
+                </span>
+              </div>
+              <div style={{ fontSize: '14px', color: '#ccc' }}>kind: {detail.service.kind}</div>
+              <div style={{ fontSize: '14px', color: '#ccc' }}>
+                last checked: {detail.service.lastCheckTime ? new Date(detail.service.lastCheckTime).toLocaleString() : 'not checked yet'}
+                {detail.service.latency !== undefined && ` (${detail.service.latency} ms)`}
+              </div>
+              <div style={{ fontSize: '14px', color: '#d4af37', marginTop: '10px', fontWeight: 'bold' }}>
+                {flappingSummary(detail.incidents)}
+              </div>
+              {detail.service.message && (
+                <div style={{ fontSize: '14px', marginTop: '10px', whiteSpace: 'pre-line', wordWrap: 'break-word', color: statusColor(detail.service.status) }}>
+                  {detail.service.message}
+                </div>
+              )}
+            </div>
+
+            {detail.service.results && detail.service.results.length > 0 && (
+              <div style={sectionStyle}>
+                <h3 style={{ marginTop: 0 }}>Results</h3>
+                <div style={{ overflowX: 'auto' }}>
+                  <table style={{ width: '100%', borderCollapse: 'collapse', fontSize: '13px' }}>
+                    <thead>
+                      <tr>
+                        <th style={cellStyle}>Name</th>
+                        <th style={cellStyle}>Status</th>
+                        <th style={cellStyle}>Since</th>
+                        <th style={cellStyle}>Message</th>
+                      </tr>
+                    </thead>
+                    <tbody>
+                      {detail.service.results.map((result, index) => (
+                        <tr key={index} style={{ color: statusColor(result.status) }}>
+                          <td style={{ ...cellStyle, wordBreak: 'break-word' }}>{result.name}</td>
+                          <td style={cellStyle}>{result.status}</td>
+                          <td style={cellStyle}>{new Date(result.since).toLocaleString()}</td>
+                          <td style={{ ...cellStyle, whiteSpace: 'pre-line', wordBreak: 'break-word' }}>{result.message}</td>
+                        </tr>
+                      ))}
+                    </tbody>
+                  </table>
+                </div>
+              </div>
+            )}
+
+            <div style={sectionStyle}>
+              <h3 style={{ marginTop: 0 }}>Checks</h3>
+              <div style={{ display: 'flex', flexWrap: 'wrap', gap: '2px' }}>
+                {detail.checks.map((check, index) => (
+                  <span
+                    key={index}
+                    title={`${new Date(check.checkedAt).toLocaleString()} ${check.status} ${check.latency} ms${check.message ? `\n${check.message}` : ''}`}
+                    style={{ width: '6px', height: '24px', borderRadius: '2px', backgroundColor: statusColor(check.status) }}
+                  />
+                ))}
+              </div>
+            </div>
+
+            <div style={sectionStyle}>
+              <h3 style={{ marginTop: 0 }}>Latency</h3>
+              <LatencyChart checks={detail.checks} />
+            </div>
+
+            <div style={sectionStyle}>
+              <h3 style={{ marginTop: 0 }}>Incidents</h3>
+              {detail.incidents.length === 0 ? (
+                <p style={{ color: '#aaa' }}>No incident</p>
+              ) : (
+                <div style={{ overflowX: 'auto' }}>
+                  <table style={{ width: '100%', borderCollapse: 'collapse', fontSize: '13px' }}>
+                    <thead>
+                      <tr>
+                        <th style={cellStyle}>Status</th>
+                        <th style={cellStyle}>Started</th>
+                        <th style={cellStyle}>Ended</th>
+                        <th style={cellStyle}>Duration</th>
+                        <th style={cellStyle}>Message</th>
+                      </tr>
+                    </thead>
+                    <tbody>
+                      {[...detail.incidents].reverse().map((incident, index) => (
+                        <tr key={index} style={{ color: statusColor(incident.status) }}>
+                          <td style={cellStyle}>{incident.status}</td>
+                          <td style={cellStyle}>{new Date(incident.startedAt).toLocaleString()}</td>
+                          <td style={cellStyle}>{incident.endedAt ? new Date(incident.endedAt).toLocaleString() : 'ongoing'}</td>
+                          <td style={cellStyle}>{formatDuration(incident.duration)}</td>
+                          <td style={{ ...cellStyle, whiteSpace: 'pre-line', wordBreak: 'break-word' }}>{incident.message}</td>
+                        </tr>
+                      ))}
+                    </tbody>
+                  </table>
+                </div>
+              )}
+            </div>
+
+            <div style={sectionStyle}>
+              <h3 style={{ marginTop: 0 }}>Config</h3>
+              <pre style={{ fontSize: '12px', overflowX: 'auto', backgroundColor: '#1e1e1e', padding: '10px', borderRadius: '4px' }}>
+                {JSON.stringify(config, null, 2)}
+              </pre>
+            </div>
+          </>
+        )}
+      </div>
+    </div>
+  );
+}
+
+export default Service;
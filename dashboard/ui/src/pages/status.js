@@ -0,0 +1,141 @@
+import React, { useEffect, useState } from 'react';
+
+const statusColors = {
+  operational: '#28a745',
+  degraded: '#fd7e14',
+  outage: '#dc3545',
+  unknown: '#6c757d',
+};
+
+const statusLabels = {
+  operational: 'Operational',
+  degraded: 'Degraded Performance',
+  outage: 'Outage',
+  unknown: 'No Data',
+};
+
+const overallLabels = {
+  operational: 'All Systems Operational',
+  degraded: 'Some Systems Degraded',
+  outage: 'Some Systems Have an Outage',
+  unknown: 'Status Unknown',
+};
+
+const formatUptime = (uptime) => {
+  return uptime === null || uptime === undefined ? 'no data' : `${uptime.toFixed(2)}% uptime`;
+};
+
+// Status is the public read-only status page, it does not require a login
+function Status() {
+  const [status, setStatus] = useState(null);
+  const [error, setError] = useState(null);
+
+  useEffect(() => {
+    const fetchStatus = async () => {
+      try {
+        const response = await fetch('/api/status');
+        const result = await response.json();
+        if (result.success) {
+          setError(null);
+          setStatus(result.data);
+          document.title = result.data.title;
+        } else {
+          setError(result.message || 'Failed to retrieve status');
+        }
+      } catch (err) {
+        setError('Status page is not available');
+      }
+    };
+
+    fetchStatus();
+    const intervalId = setInterval(fetchStatus, 60000);
+    return () => clearInterval(intervalId);
+  }, []);
+
+  const sectionStyle = {
+    backgroundColor: '#353535',
+    margin: '10px 0',
+    padding: '15px',
+    borderRadius: '8px',
+    boxShadow: '0 2px 8px rgba(0, 0, 0, 0.6)',
+  };
+
+  return (
+    <div style={{ padding: '20px', fontFamily: 'Arial, sans-serif', backgroundColor: '#212121', color: '#f5f5f5', minHeight: '100vh' }}>
+      <div style={{ maxWidth: '800px', margin: '0 auto' }}>
+        {error && <p>{error}</p>}
+        {!status && !error && <p>Loading status...</p>}
+        {status && (
+          <>
+            <h2 style={{ textAlign: 'center' }}>{status.title}</h2>
+
+            <div style={{
+              ...sectionStyle,
+              backgroundColor: statusColors[status.status],
+              color: '#000',
+              fontWeight: 'bold',
+              fontSize: '18px',
+            }}>
+              {overallLabels[status.status]}
+            </div>
+
+            {status.components.map((component, index) => (
+              <div key={index} style={sectionStyle}>
+                <div style={{ display: 'flex', justifyContent: 'space-between', flexWrap: 'wrap', marginBottom: '10px' }}>
+                  <span style={{ fontSize: '16px', fontWeight: 'bold' }}>{component.name}</span>
+                  <span style={{ color: statusColors[component.status], fontWeight: 'bold' }}>{statusLabels[component.status]}</span>
+                </div>
+                <div style={{ display: 'flex', gap: '2px' }}>
+                  {component.days.map((day, dayIndex) => (
+                    <span
+                      key={dayIndex}
+                      title={`${day.date} ${formatUptime(day.uptime)}`}
+                      style={{ flex: 1, height: '30px', borderRadius: '2px', backgroundColor: statusColors[day.status] }}
+                    />
+                  ))}
+                </div>
+                <div style={{ display: 'flex', justifyContent: 'space-between', fontSize: '12px', color: '#aaa', marginTop: '5px' }}>
+                  <span>{component.days.length} days ago</span>
+                  <span>{formatUptime(component.uptime)}</span>
+                  <span>Today</span>
+                </div>
+              </div>
+            ))}
+
+            <h3>Incidents</h3>
+            {status.incidents.length === 0 && <p style={{ color: '#aaa' }}>No incident reported</p>}
+            {status.incidents.map((incident, index) => (
+              <div key={index} style={sectionStyle}>
+                <div style={{ display: 'flex', justifyContent: 'space-between', flexWrap: 'wrap' }}>
+                  <span style={{ fontWeight: 'bold' }}>{incident.title}</span>
+                  <span style={{ color: incident.resolvedAt ? statusColors.operational : statusColors.degraded, fontWeight: 'bold' }}>
+                    {incident.status || (incident.resolvedAt ? 'resolved' : 'ongoing')}
+                  </span>
+                </div>
+                {incident.components && incident.components.length > 0 && (
+                  <div style={{ fontSize: '13px', color: '#04a0bf', marginTop: '5px' }}>{incident.components.join(', ')}</div>
+                )}
+                <div style={{ fontSize: '14px', marginTop: '10px', whiteSpace: 'pre-line', wordWrap: 'break-word' }}>{incident.message}</div>
+                <div style={{ fontSize: '12px', color: '#aaa', marginTop: '10px' }}>
+                  {new Date(incident.createdAt).toLocaleString()}
+                  {incident.resolvedAt && ` - ${new Date(incident.resolvedAt).toLocaleString()}`}
+                </div>
+              </div>
+            ))}
+          </>
+        )}
+      </div>
+
+      <footer style={{
+        textAlign: 'right',
+        fontSize: '15px',
+        color: '#666',
+        marginTop: '20px',
+      }}>
+        Status Page by <a href="https://github.com/telkomdev/tob" target="_blank" rel="noopener noreferrer" style={{ color: '#007bff', textDecoration: 'none' }}>Tob</a>
+      </footer>
+    </div>
+  );
+}
+
+export default Status;
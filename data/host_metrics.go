@@ -0,0 +1,94 @@
+package data
+
+// HostMetricsRequest represent the host metrics payload sent to tob-http-agent,
+// every field is optional
+type HostMetricsRequest struct {
+	// Mounts limits the disk usage to these paths, all mounts are reported when it is empty
+	Mounts []string `json:"mounts"`
+
+	// Processes the process names (/proc/[pid]/comm) to check
+	Processes []string `json:"processes"`
+
+	// Units the systemd units to check
+	Units []string `json:"units"`
+}
+
+// HostMetrics represent the host metrics collected by tob-http-agent
+type HostMetrics struct {
+	Hostname        string           `json:"hostname"`
+	CollectedAt     int64            `json:"collectedAt"`
+	Load            *Load            `json:"load,omitempty"`
+	Memory          *Memory          `json:"memory,omitempty"`
+	Swap            *Memory          `json:"swap,omitempty"`
+	Mounts          []Mount          `json:"mounts"`
+	Interfaces      []Interface      `json:"interfaces"`
+	FileDescriptors *FileDescriptors `json:"fileDescriptors,omitempty"`
+	Processes       []Process        `json:"processes"`
+	Units           []Unit           `json:"units"`
+
+	// Errors the metrics that could not be collected
+	Errors []string `json:"errors"`
+}
+
+// Load represent the load average
+type Load struct {
+	Load1  float64 `json:"load1"`
+	Load5  float64 `json:"load5"`
+	Load15 float64 `json:"load15"`
+	CPUs   int     `json:"cpus"`
+}
+
+// Memory represent the memory or swap usage in bytes
+type Memory struct {
+	Total       uint64  `json:"total"`
+	Available   uint64  `json:"available"`
+	UsedPercent float64 `json:"usedPercent"`
+}
+
+// Mount represent the disk and inode usage of a mount
+type Mount struct {
+	Path              string  `json:"path"`
+	Device            string  `json:"device"`
+	FSType            string  `json:"fsType"`
+	Total             uint64  `json:"total"`
+	Used              uint64  `json:"used"`
+	Available         uint64  `json:"available"`
+	UsedPercent       float64 `json:"usedPercent"`
+	Inodes            uint64  `json:"inodes"`
+	InodesUsed        uint64  `json:"inodesUsed"`
+	InodesUsedPercent float64 `json:"inodesUsedPercent"`
+}
+
+// Interface represent the error counters of a network interface since boot
+type Interface struct {
+	Name      string `json:"name"`
+	RxErrors  uint64 `json:"rxErrors"`
+	RxDropped uint64 `json:"rxDropped"`
+	TxErrors  uint64 `json:"txErrors"`
+	TxDropped uint64 `json:"txDropped"`
+}
+
+// FileDescriptors represent the file descriptors allocated by the kernel
+type FileDescriptors struct {
+	Allocated   uint64  `json:"allocated"`
+	Max         uint64  `json:"max"`
+	UsedPercent float64 `json:"usedPercent"`
+}
+
+// Process represent the liveness of a process name
+type Process struct {
+	Name    string `json:"name"`
+	Running int    `json:"running"`
+}
+
+// Unit represent the liveness of a systemd unit
+type Unit struct {
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+}
+
+// HostReport represent the host metrics pushed by tob-http-agent in push mode
+type HostReport struct {
+	PushID  string      `json:"pushId"`
+	Metrics HostMetrics `json:"metrics"`
+}
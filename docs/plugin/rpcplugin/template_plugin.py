@@ -0,0 +1,50 @@
+#!/usr/bin/env python3
+"""tob out-of-process plugin template, see docs/plugin/README.md for the protocol."""
+
+import json
+import sys
+import urllib.request
+
+config = {}
+
+
+def handle(method, params):
+    if method == "handshake":
+        if 1 not in params["protocolVersions"]:
+            raise Exception("protocol version 1 is not offered")
+        return {"protocolVersion": 1, "name": "template_plugin_py"}
+
+    if method == "configure":
+        config["url"] = params["url"]
+        config["config"] = params["config"]
+        return {}
+
+    if method == "ping":
+        try:
+            with urllib.request.urlopen(config["url"], timeout=5) as resp:
+                if resp.status >= 500:
+                    return {"status": "NOT_OK", "message": "status %d" % resp.status}
+                return {"status": "OK", "message": ""}
+        except Exception as e:
+            return {"status": "NOT_OK", "message": str(e)}
+
+    if method == "close":
+        return {}
+
+    raise Exception("method %s is not supported" % method)
+
+
+for line in sys.stdin:
+    request = json.loads(line)
+    response = {"id": request["id"]}
+    try:
+        response["result"] = handle(request["method"], request.get("params"))
+    except Exception as e:
+        response["error"] = {"message": str(e)}
+
+    # stdout is reserved for the protocol, log to stderr
+    sys.stdout.write(json.dumps(response) + "\n")
+    sys.stdout.flush()
+
+    if request["method"] == "close":
+        break
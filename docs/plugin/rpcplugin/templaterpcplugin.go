@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/telkomdev/tob/rpcplugin"
+)
+
+// TemplateRPCPlugin checks that a TCP port of the service url accepts connections
+type TemplateRPCPlugin struct {
+	address string
+}
+
+// Configure will receive the service url and config
+func (p *TemplateRPCPlugin) Configure(serviceURL string, config map[string]interface{}) error {
+	u, err := url.Parse(serviceURL)
+	if err != nil {
+		return err
+	}
+
+	if u.Port() == "" {
+		return errors.New("url has no port")
+	}
+
+	p.address = u.Host
+	return nil
+}
+
+// Ping will check the service
+func (p *TemplateRPCPlugin) Ping() (string, string) {
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", p.address, 5*time.Second)
+	if err != nil {
+		return rpcplugin.StatusNotOK, err.Error()
+	}
+
+	conn.Close()
+
+	elapsed := time.Since(start)
+	if elapsed > time.Second {
+		return rpcplugin.StatusDegraded, fmt.Sprintf("%s connect took %s", p.address, elapsed)
+	}
+
+	return rpcplugin.StatusOK, ""
+}
+
+func main() {
+	// stdout is reserved for the protocol, log to stderr
+	logger := log.New(os.Stderr, "", 0)
+
+	err := rpcplugin.Serve("templaterpcplugin", &TemplateRPCPlugin{})
+	if err != nil {
+		logger.Fatal(err)
+	}
+}
@@ -158,5 +158,8 @@ func (d *TemplatePlugin) Stop() chan bool {
 	return d.stopChan
 }
 
-// Exported
-var Service TemplatePlugin
+// NewService is exported, tob calls it for every service that uses this plugin
+// so each service has its own instance
+func NewService(verbose bool, logger *log.Logger) tob.Service {
+	return NewTemplatePlugin(verbose, logger)
+}
@@ -0,0 +1,95 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Kind represent a kind of event
+type Kind string
+
+const (
+	// Check a service check has completed
+	Check Kind = "check"
+
+	// Status the status of a service has changed
+	Status Kind = "status"
+)
+
+// Event represent a service event published by the runner
+type Event struct {
+	Kind    Kind   `json:"kind"`
+	Service string `json:"service"`
+
+	// Status is UP, DOWN or DEGRADED
+	Status    string    `json:"status"`
+	CheckedAt time.Time `json:"checkedAt"`
+
+	// Latency the check duration in milliseconds
+	Latency int64  `json:"latency"`
+	Message string `json:"message"`
+
+	// Results the result of each target of a check, eg: the domains of sslstatus
+	Results []Result `json:"results,omitempty"`
+}
+
+// Result represent the result of a target checked by a service
+type Result struct {
+	Name string `json:"name"`
+
+	// Status is UP, DOWN or DEGRADED
+	Status  string `json:"status"`
+	Message string `json:"message"`
+
+	// Since the time the target entered its current status
+	Since time.Time `json:"since"`
+}
+
+// Bus is an in-process publish subscribe of events
+type Bus struct {
+	mutex       sync.RWMutex
+	subscribers map[chan Event]struct{}
+}
+
+// NewBus Bus's constructor
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Publish will send the event to every subscriber,
+// the event is dropped for a subscriber whose buffer is full so a slow subscriber never blocks a check
+func (b *Bus) Publish(event Event) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for subscriber := range b.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe will return the events channel with the buffer size and the function to unsubscribe
+func (b *Bus) Subscribe(size int) (<-chan Event, func()) {
+	subscriber := make(chan Event, size)
+
+	b.mutex.Lock()
+	b.subscribers[subscriber] = struct{}{}
+	b.mutex.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mutex.Lock()
+			delete(b.subscribers, subscriber)
+			b.mutex.Unlock()
+
+			close(subscriber)
+		})
+	}
+
+	return subscriber, unsubscribe
+}
@@ -1,9 +1,14 @@
 package httpx
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"time"
 )
 
@@ -32,6 +37,63 @@ func HTTPRequest(req *http.Request, timeout int) (*http.Response, error) {
 	return resp, nil
 }
 
+// NewClient will return http.Client with timeout in seconds and optional TLS config
+func NewClient(timeout int, tlsConfig *tls.Config) *http.Client {
+	// set default http client timeout to 5 seconds
+	if timeout <= 0 {
+		timeout = 5
+	}
+
+	client := &http.Client{
+		Timeout: time.Duration(timeout) * time.Second,
+	}
+
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		client.Transport = transport
+	}
+
+	return client
+}
+
+// TLSConfig will return tls.Config with optional custom CA bundle and client certificate,
+// it returns nil when every option is empty so the default TLS config is used
+func TLSConfig(caFile, certFile, keyFile string, skipVerify bool) (*tls.Config, error) {
+	if caFile == "" && certFile == "" && keyFile == "" && !skipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: skipVerify,
+	}
+
+	if caFile != "" {
+		caPEM, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("error: no certificate found in %s", caFile)
+		}
+
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
 // HTTPPost will execute HTTP POST
 func HTTPPost(url string, body io.Reader, headers map[string]string, timeout int) (*http.Response, error) {
 	req, err := http.NewRequest("POST", url, body)
@@ -63,3 +125,8 @@ func HTTPGet(url string, headers map[string]string, timeout int) (*http.Response
 
 	return HTTPRequest(req, timeout)
 }
+
+// BasicAuth will return the value of HTTP Authorization header for basic authentication
+func BasicAuth(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
@@ -0,0 +1,74 @@
+package httpx
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// HeaderTimestamp the unix time the request was signed
+	HeaderTimestamp = "X-Tob-Timestamp"
+
+	// HeaderSignature the hex HMAC-SHA256 of the request
+	HeaderSignature = "X-Tob-Signature"
+
+	// DefaultSignatureMaxAge how old a signed request may be
+	DefaultSignatureMaxAge = 5 * time.Minute
+)
+
+var (
+	// ErrorMissingSignature error type
+	ErrorMissingSignature = errors.New("error: request is not signed")
+
+	// ErrorInvalidSignature error type
+	ErrorInvalidSignature = errors.New("error: request signature is not valid")
+
+	// ErrorExpiredSignature error type
+	ErrorExpiredSignature = errors.New("error: request signature has expired")
+)
+
+// Signature will return the hex HMAC-SHA256 of timestamp, method, path and body, separated by new lines
+func Signature(secret, timestamp, method, path string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + method + "\n" + path + "\n"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequest will set the timestamp and signature headers, body is the request body
+func SignRequest(req *http.Request, secret string, body []byte) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Signature(secret, timestamp, req.Method, req.URL.Path, body))
+}
+
+// VerifyRequest will verify the signature headers of a request signed with SignRequest, body is the request body
+func VerifyRequest(req *http.Request, secret string, body []byte, maxAge time.Duration) error {
+	timestamp := req.Header.Get(HeaderTimestamp)
+	signature := req.Header.Get(HeaderSignature)
+	if timestamp == "" || signature == "" {
+		return ErrorMissingSignature
+	}
+
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrorInvalidSignature
+	}
+
+	age := time.Since(time.Unix(signedAt, 0))
+	if age > maxAge || age < -maxAge {
+		return ErrorExpiredSignature
+	}
+
+	expected := Signature(secret, timestamp, req.Method, req.URL.Path, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrorInvalidSignature
+	}
+
+	return nil
+}
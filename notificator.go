@@ -22,6 +22,12 @@ type Notificator interface {
 	IsEnabled() bool
 }
 
+// StatusNotificator a Notificator that receives the status of the service with the message
+type StatusNotificator interface {
+	// SendStatus will send the status of the service, msg is the message of Send
+	SendStatus(service, status, details, msg string) error
+}
+
 // InitNotificatorFactory will init all notificator
 func InitNotificatorFactory(configs config.Config, verbose bool) []Notificator {
 	// discord notificator
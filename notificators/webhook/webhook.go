@@ -6,14 +6,23 @@ import (
 	"errors"
 	"io"
 	"log"
+	"time"
 
 	"github.com/telkomdev/tob/config"
 	"github.com/telkomdev/tob/httpx"
 )
 
-// WebhookMessage represent Webhook request
+// WebhookMessage represent Webhook request,
+// the service fields are set when the message is a status of a service
 type WebhookMessage struct {
 	Message string `json:"message"`
+
+	Service string `json:"service,omitempty"`
+
+	// Status is UP, DOWN, DEGRADED, CHECKING or MONITORED
+	Status  string `json:"status,omitempty"`
+	Details string `json:"details,omitempty"`
+	SentAt  int64  `json:"sentAt,omitempty"`
 }
 
 // WebhookResponse represent Webhook response
@@ -99,12 +108,25 @@ func (d *Webhook) Provider() string {
 
 // Send will send notification
 func (d *Webhook) Send(msg string) error {
+	return d.send(WebhookMessage{
+		Message: msg,
+	})
+}
+
+// SendStatus will send notification with the status of the service
+func (d *Webhook) SendStatus(service, status, details, msg string) error {
+	return d.send(WebhookMessage{
+		Message: msg,
+		Service: service,
+		Status:  status,
+		Details: details,
+		SentAt:  time.Now().Unix(),
+	})
+}
+
+func (d *Webhook) send(webhookMessage WebhookMessage) error {
 	for _, conf := range d.configs {
 		if conf.enabled {
-			webhookMessage := WebhookMessage{
-				Message: msg,
-			}
-
 			messageJSON, err := json.Marshal(webhookMessage)
 			if err != nil {
 				return err
@@ -0,0 +1,90 @@
+package tob
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+)
+
+// ServiceFactory will create a new instance of a service kind
+type ServiceFactory func(verbose bool, logger *log.Logger) Service
+
+// Option represent a config option of a service kind
+type Option struct {
+	Name string
+
+	// Type is string, number, bool, array or object
+	Type        string
+	Required    bool
+	Description string
+}
+
+// ServiceKindInfo represent a registered service kind
+type ServiceKindInfo struct {
+	Kind        ServiceKind
+	Description string
+	Options     []Option
+	Factory     ServiceFactory
+
+	// Plugin is true when the kind is provided by a plugin
+	Plugin bool
+}
+
+var (
+	serviceKindsMutex sync.RWMutex
+	serviceKinds      = make(map[ServiceKind]ServiceKindInfo)
+)
+
+// RegisterServiceKind will register a service kind, built in services register themselves in their package init.
+// It panics when the kind is registered twice or has no factory, like database/sql.Register
+func RegisterServiceKind(info ServiceKindInfo) {
+	serviceKindsMutex.Lock()
+	defer serviceKindsMutex.Unlock()
+
+	if info.Factory == nil {
+		panic(fmt.Sprintf("tob: service kind %s has no factory", info.Kind))
+	}
+
+	if _, ok := serviceKinds[info.Kind]; ok {
+		panic(fmt.Sprintf("tob: service kind %s is registered twice", info.Kind))
+	}
+
+	serviceKinds[info.Kind] = info
+}
+
+// LookupServiceKind will return the registered service kind
+func LookupServiceKind(kind ServiceKind) (ServiceKindInfo, bool) {
+	serviceKindsMutex.RLock()
+	defer serviceKindsMutex.RUnlock()
+
+	info, ok := serviceKinds[kind]
+	return info, ok
+}
+
+// ServiceKinds will return every registered service kind sorted by kind
+func ServiceKinds() []ServiceKindInfo {
+	serviceKindsMutex.RLock()
+	defer serviceKindsMutex.RUnlock()
+
+	infos := make([]ServiceKindInfo, 0, len(serviceKinds))
+	for _, info := range serviceKinds {
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Kind < infos[j].Kind
+	})
+
+	return infos
+}
+
+// NewService will create a new instance of the registered service kind
+func NewService(kind ServiceKind, verbose bool) (Service, bool) {
+	info, ok := LookupServiceKind(kind)
+	if !ok {
+		return nil, false
+	}
+
+	return info.Factory(verbose, Logger), true
+}
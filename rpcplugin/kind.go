@@ -0,0 +1,23 @@
+package rpcplugin
+
+import (
+	"log"
+
+	"github.com/telkomdev/tob"
+)
+
+func init() {
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.Plugin,
+		Description: "out-of-process plugin speaking JSON over stdio, or a legacy Go plugin (.so)",
+		Options: []tob.Option{
+			{Name: "pluginCommand", Type: "string", Description: "plugin executable"},
+			{Name: "pluginArgs", Type: "array", Description: "plugin executable arguments"},
+			{Name: "pluginTimeout", Type: "number", Description: "request timeout in seconds, default 30"},
+			{Name: "pluginPath", Type: "string", Description: "legacy Go plugin (.so) path, used when pluginCommand is empty"},
+		},
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewPlugin(verbose, logger)
+		},
+	})
+}
@@ -0,0 +1,482 @@
+package rpcplugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/telkomdev/tob"
+	"github.com/telkomdev/tob/config"
+	"github.com/telkomdev/tob/util"
+)
+
+const (
+	// DefaultTimeout default request timeout in seconds
+	DefaultTimeout = 30
+
+	// closeTimeout how long the plugin has to exit after close
+	closeTimeout = 5 * time.Second
+)
+
+var (
+	// ErrorNoCommand error type
+	ErrorNoCommand = errors.New("error: pluginCommand is not in the plugin config")
+
+	// ErrorPluginExited error type
+	ErrorPluginExited = errors.New("error: plugin process has exited")
+
+	// ErrorTimeout error type
+	ErrorTimeout = errors.New("error: plugin did not respond in time")
+)
+
+// process represent a running plugin process
+type process struct {
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	responses chan Response
+	done      chan struct{}
+
+	// stderrDone is closed when the plugin stderr is read until its end
+	stderrDone chan struct{}
+}
+
+// Plugin service, runs the plugin as a subprocess speaking the JSON over stdio protocol,
+// a plugin that crashes only marks its service down and is restarted on the next check
+type Plugin struct {
+	url               string
+	recovered         bool
+	lastDownTime      string
+	enabled           bool
+	verbose           bool
+	logger            *log.Logger
+	checkInterval     int
+	stopChan          chan bool
+	message           string
+	name              string
+	command           string
+	args              []string
+	timeout           time.Duration
+	process           *process
+	nextID            uint64
+	mutex             sync.Mutex
+	configs           config.Config
+	notificatorConfig config.Config
+}
+
+// NewPlugin Plugin's constructor
+func NewPlugin(verbose bool, logger *log.Logger) *Plugin {
+	stopChan := make(chan bool, 1)
+	return &Plugin{
+		logger:  logger,
+		verbose: verbose,
+		name:    "plugin",
+
+		// by default service is recovered
+		recovered:     true,
+		checkInterval: 0,
+		stopChan:      stopChan,
+	}
+}
+
+// NewPluginKind will return the factory of a plugin kind, every service of the kind runs its own process of command
+func NewPluginKind(kind tob.ServiceKind, command string, args []string) tob.ServiceFactory {
+	return func(verbose bool, logger *log.Logger) tob.Service {
+		d := NewPlugin(verbose, logger)
+		d.name = string(kind)
+		d.command = command
+		d.args = append([]string(nil), args...)
+		return d
+	}
+}
+
+// Name the name of the service, the kind the plugin is configured as
+func (d *Plugin) Name() string {
+	return d.name
+}
+
+// Ping will try to ping the service
+func (d *Plugin) Ping() []byte {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.process == nil {
+		err := d.start()
+		if err != nil {
+			d.SetMessage(fmt.Sprintf("plugin %s cannot start: %s", d.command, err.Error()))
+			if d.verbose {
+				d.logger.Println(d.message)
+			}
+
+			return []byte("NOT_OK")
+		}
+	}
+
+	var result PingResult
+	err := d.call(MethodPing, nil, &result, d.timeout)
+	if err != nil {
+		// the plugin is restarted on the next check
+		d.kill()
+		d.SetMessage(fmt.Sprintf("plugin %s ping failed: %s", d.name, err.Error()))
+		if d.verbose {
+			d.logger.Println(d.message)
+		}
+
+		return []byte("NOT_OK")
+	}
+
+	d.SetMessage(result.Message)
+
+	switch result.Status {
+	case StatusOK, StatusDegraded, StatusNotOK:
+		return []byte(result.Status)
+	default:
+		d.SetMessage(fmt.Sprintf("plugin %s returned invalid status %s: %s", d.name, result.Status, result.Message))
+		return []byte("NOT_OK")
+	}
+}
+
+// start will start the plugin process, negotiate the protocol version and send the config
+func (d *Plugin) start() error {
+	cmd := exec.Command(d.command, d.args...)
+	cmd.Env = os.Environ()
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	p := &process{
+		cmd:        cmd,
+		stdin:      stdin,
+		responses:  make(chan Response),
+		done:       make(chan struct{}),
+		stderrDone: make(chan struct{}),
+	}
+
+	go d.readResponses(p, stdout)
+	go d.logStderr(p, stderr)
+
+	d.process = p
+
+	var handshake HandshakeResult
+	err = d.call(MethodHandshake, HandshakeParams{ProtocolVersions: SupportedVersions}, &handshake, d.timeout)
+	if err != nil {
+		d.kill()
+		return fmt.Errorf("handshake failed: %s", err.Error())
+	}
+
+	if !supported(handshake.ProtocolVersion) {
+		d.kill()
+		return fmt.Errorf("protocol version %d is not supported", handshake.ProtocolVersion)
+	}
+
+	// notificator config is not needed by the plugin
+	pluginConfig := make(map[string]interface{})
+	for key, value := range d.configs {
+		if key != "notificator" {
+			pluginConfig[key] = value
+		}
+	}
+
+	err = d.call(MethodConfigure, ConfigureParams{URL: d.url, Config: pluginConfig}, nil, d.timeout)
+	if err != nil {
+		d.kill()
+		return fmt.Errorf("configure failed: %s", err.Error())
+	}
+
+	if d.verbose {
+		d.logger.Printf("plugin %s started as %s with protocol version %d\n", d.name, handshake.Name, handshake.ProtocolVersion)
+	}
+
+	return nil
+}
+
+// readResponses will read the plugin stdout until the process exits
+func (d *Plugin) readResponses(p *process, stdout io.Reader) {
+	defer close(p.done)
+
+	reader := bufio.NewReader(stdout)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+
+		var response Response
+		err = json.Unmarshal(line, &response)
+		if err != nil {
+			if d.verbose {
+				d.logger.Printf("plugin %s invalid response: %s\n", d.name, string(line))
+			}
+
+			continue
+		}
+
+		select {
+		case p.responses <- response:
+		case <-time.After(closeTimeout):
+			// nobody waits for this response anymore
+		}
+	}
+}
+
+// logStderr will log every line the plugin writes to stderr
+func (d *Plugin) logStderr(p *process, stderr io.Reader) {
+	defer close(p.stderrDone)
+
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		d.logger.Printf("plugin %s: %s\n", d.name, scanner.Text())
+	}
+}
+
+// call will send the request and wait for its response until the timeout
+func (d *Plugin) call(method string, params interface{}, result interface{}, timeout time.Duration) error {
+	p := d.process
+	if p == nil {
+		return ErrorPluginExited
+	}
+
+	d.nextID++
+	request := Request{ID: d.nextID, Method: method}
+
+	if params != nil {
+		var err error
+		request.Params, err = json.Marshal(params)
+		if err != nil {
+			return err
+		}
+	}
+
+	line, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.stdin.Write(append(line, '\n'))
+	if err != nil {
+		return ErrorPluginExited
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case response := <-p.responses:
+			// a late response of a previous request
+			if response.ID != request.ID {
+				continue
+			}
+
+			if response.Error != nil {
+				return errors.New(response.Error.Message)
+			}
+
+			if result == nil || len(response.Result) == 0 {
+				return nil
+			}
+
+			return json.Unmarshal(response.Result, result)
+		case <-p.done:
+			return ErrorPluginExited
+		case <-timer.C:
+			return ErrorTimeout
+		}
+	}
+}
+
+// kill will stop the plugin process
+func (d *Plugin) kill() {
+	p := d.process
+	if p == nil {
+		return
+	}
+
+	d.process = nil
+
+	p.stdin.Close()
+	p.cmd.Process.Kill()
+
+	// Wait closes stdout and stderr, so the readers must be done with them first,
+	// unless a child of the plugin keeps them open
+	timeout := time.After(closeTimeout)
+	for _, done := range []chan struct{}{p.done, p.stderrDone} {
+		select {
+		case <-done:
+		case <-timeout:
+		}
+	}
+
+	p.cmd.Wait()
+}
+
+func supported(version int) bool {
+	for _, v := range SupportedVersions {
+		if v == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetURL will set the service URL
+func (d *Plugin) SetURL(url string) {
+	d.url = url
+}
+
+// Connect to service if needed, the plugin process is started by the first Ping
+// so a plugin that fails to start does not stop tob
+func (d *Plugin) Connect() error {
+	if d.verbose {
+		d.logger.Println("connect Plugin")
+	}
+
+	// a plugin kind has its command already, the service config may still override it
+	if command, ok := d.configs["pluginCommand"].(string); ok && command != "" {
+		d.command = command
+	}
+
+	if d.command == "" {
+		return ErrorNoCommand
+	}
+
+	if args, ok := d.configs["pluginArgs"].([]interface{}); ok {
+		d.args = make([]string, 0, len(args))
+		for _, arg := range args {
+			d.args = append(d.args, fmt.Sprintf("%v", arg))
+		}
+	}
+
+	timeout := int(util.InterfaceToFloat64(d.configs["pluginTimeout"]))
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	d.timeout = time.Duration(timeout) * time.Second
+
+	return nil
+}
+
+// Close will close the service resources if needed
+func (d *Plugin) Close() error {
+	if d.verbose {
+		d.logger.Println("close Plugin")
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	p := d.process
+	if p == nil {
+		return nil
+	}
+
+	// give the plugin a chance to release its resources
+	d.call(MethodClose, nil, nil, closeTimeout)
+	p.stdin.Close()
+
+	select {
+	case <-p.done:
+	case <-time.After(closeTimeout):
+	}
+
+	d.kill()
+
+	return nil
+}
+
+// SetRecover will set recovered status
+func (d *Plugin) SetRecover(recovered bool) {
+	d.recovered = recovered
+}
+
+// IsRecover will return recovered status
+func (d *Plugin) IsRecover() bool {
+	return d.recovered
+}
+
+// LastDownTime will set last down time of service to current time
+func (d *Plugin) SetLastDownTimeNow() {
+	if d.recovered {
+		d.lastDownTime = time.Now().Format(util.YYMMDD)
+	}
+}
+
+// GetDownTimeDiff will return down time service difference in minutes
+func (d *Plugin) GetDownTimeDiff() string {
+	return util.TimeDifference(d.lastDownTime, time.Now().Format(util.YYMMDD))
+}
+
+// SetCheckInterval will set check interval to service
+func (d *Plugin) SetCheckInterval(interval int) {
+	d.checkInterval = interval
+}
+
+// GetCheckInterval will return check interval to service
+func (d *Plugin) GetCheckInterval() int {
+	return d.checkInterval
+}
+
+// Enable will set enabled status to service
+func (d *Plugin) Enable(enabled bool) {
+	d.enabled = enabled
+}
+
+// IsEnabled will return enable status
+func (d *Plugin) IsEnabled() bool {
+	return d.enabled
+}
+
+// SetMessage will set additional message
+func (d *Plugin) SetMessage(message string) {
+	d.message = message
+}
+
+// GetMessage will return additional message
+func (d *Plugin) GetMessage() string {
+	return d.message
+}
+
+// SetConfig will set config
+func (d *Plugin) SetConfig(configs config.Config) {
+	d.configs = configs
+}
+
+// SetNotificatorConfig will set config
+func (d *Plugin) SetNotificatorConfig(configs config.Config) {
+	d.notificatorConfig = configs
+}
+
+// GetNotificators will return notificators
+func (d *Plugin) GetNotificators() []tob.Notificator {
+	return tob.InitNotificatorFactory(d.notificatorConfig, d.verbose)
+}
+
+// Stop will receive stop channel
+func (d *Plugin) Stop() chan bool {
+	return d.stopChan
+}
@@ -0,0 +1,253 @@
+package rpcplugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/telkomdev/tob/config"
+)
+
+// pluginModeEnv when set, the test binary runs as a plugin instead of running the tests
+const pluginModeEnv = "TOB_TEST_PLUGIN_MODE"
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(pluginModeEnv) {
+	case "":
+		os.Exit(m.Run())
+	case "serve":
+		err := Serve("test-plugin", &helperHandler{})
+		if err != nil {
+			os.Exit(1)
+		}
+	case "version":
+		serveUnknownVersion()
+	case "exit":
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+}
+
+// helperHandler the handler of the test plugin, its behaviour is set by the service config:
+// status the status returned by ping, crashFile when the file exists it is removed and the plugin crashes
+// on ping, sleep the seconds ping takes
+type helperHandler struct {
+	url    string
+	config map[string]interface{}
+}
+
+func (h *helperHandler) Configure(url string, config map[string]interface{}) error {
+	if url == "invalid" {
+		return errors.New("url is not valid")
+	}
+
+	h.url = url
+	h.config = config
+	return nil
+}
+
+func (h *helperHandler) Ping() (string, string) {
+	if crashFile, ok := h.config["crashFile"].(string); ok {
+		if os.Remove(crashFile) == nil {
+			os.Exit(2)
+		}
+	}
+
+	if sleep, ok := h.config["sleep"].(float64); ok {
+		time.Sleep(time.Duration(sleep) * time.Second)
+	}
+
+	status, ok := h.config["status"].(string)
+	if !ok {
+		status = StatusOK
+	}
+
+	return status, "pong " + h.url
+}
+
+// serveUnknownVersion will answer the handshake with a protocol version tob does not support
+func serveUnknownVersion() {
+	reader := bufio.NewReader(os.Stdin)
+	encoder := json.NewEncoder(os.Stdout)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+
+		var request Request
+		if json.Unmarshal(line, &request) != nil {
+			return
+		}
+
+		result, _ := json.Marshal(HandshakeResult{ProtocolVersion: 99, Name: "future-plugin"})
+		encoder.Encode(Response{ID: request.ID, Result: result})
+	}
+}
+
+// newTestPlugin will return a plugin service running the test binary in the mode
+func newTestPlugin(t *testing.T, mode string, url string, configs config.Config) *Plugin {
+	t.Setenv(pluginModeEnv, mode)
+
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configs["pluginCommand"] = executable
+
+	d := NewPlugin(false, log.New(io.Discard, "", 0))
+	d.SetURL(url)
+	d.SetConfig(configs)
+
+	err = d.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+func TestPluginPing(t *testing.T) {
+	d := newTestPlugin(t, "serve", "ldap://example.com", config.Config{"pluginTimeout": 5.0})
+
+	if status := string(d.Ping()); status != StatusOK || d.GetMessage() != "pong ldap://example.com" {
+		t.Fatalf("expected OK pong ldap://example.com, got %s %q", status, d.GetMessage())
+	}
+
+	// the process is kept between the checks
+	pid := d.process.cmd.Process.Pid
+	d.Ping()
+	if d.process == nil || d.process.cmd.Process.Pid != pid {
+		t.Error("expected the plugin process to be reused")
+	}
+
+	err := d.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.process != nil {
+		t.Error("expected the plugin process to be stopped after close")
+	}
+
+	if d.timeout != 5*time.Second {
+		t.Errorf("expected the timeout to be kept after close, got %s", d.timeout)
+	}
+
+	// a closed plugin is started again by the next check
+	if status := string(d.Ping()); status != StatusOK {
+		t.Errorf("expected OK after close, got %s %q", status, d.GetMessage())
+	}
+}
+
+func TestPluginStatus(t *testing.T) {
+	tests := []struct {
+		status   string
+		expected string
+		message  string
+	}{
+		{status: StatusDegraded, expected: StatusDegraded, message: "pong ldap://example.com"},
+		{status: StatusNotOK, expected: StatusNotOK, message: "pong ldap://example.com"},
+		{status: "UNKNOWN", expected: StatusNotOK, message: "plugin plugin returned invalid status UNKNOWN: pong ldap://example.com"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.status, func(t *testing.T) {
+			d := newTestPlugin(t, "serve", "ldap://example.com", config.Config{"status": test.status})
+
+			if status := string(d.Ping()); status != test.expected || d.GetMessage() != test.message {
+				t.Errorf("expected %s %q, got %s %q", test.expected, test.message, status, d.GetMessage())
+			}
+		})
+	}
+}
+
+func TestPluginStartFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		url     string
+		message string
+	}{
+		{name: "unknown version", mode: "version", message: "protocol version 99 is not supported"},
+		{name: "exit", mode: "exit", message: "handshake failed: " + ErrorPluginExited.Error()},
+		{name: "configure", mode: "serve", url: "invalid", message: "configure failed: url is not valid"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := newTestPlugin(t, test.mode, test.url, config.Config{"pluginTimeout": 5.0})
+
+			status := string(d.Ping())
+			if status != StatusNotOK || !strings.HasSuffix(d.GetMessage(), test.message) {
+				t.Errorf("expected NOT_OK %q, got %s %q", test.message, status, d.GetMessage())
+			}
+
+			if d.process != nil {
+				t.Error("expected the plugin process to be stopped")
+			}
+		})
+	}
+}
+
+func TestPluginCrashRestart(t *testing.T) {
+	crashFile := filepath.Join(t.TempDir(), "crash")
+
+	err := os.WriteFile(crashFile, nil, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newTestPlugin(t, "serve", "ldap://example.com", config.Config{"crashFile": crashFile, "pluginTimeout": 5.0})
+
+	// the plugin crashes on the first ping, only its service is down
+	status := string(d.Ping())
+	if status != StatusNotOK || !strings.HasSuffix(d.GetMessage(), ErrorPluginExited.Error()) {
+		t.Fatalf("expected NOT_OK %q, got %s %q", ErrorPluginExited.Error(), status, d.GetMessage())
+	}
+
+	if d.process != nil {
+		t.Fatal("expected the crashed plugin process to be removed")
+	}
+
+	// the next check restarts the plugin
+	if status := string(d.Ping()); status != StatusOK {
+		t.Errorf("expected OK after the restart, got %s %q", status, d.GetMessage())
+	}
+}
+
+func TestPluginTimeout(t *testing.T) {
+	d := newTestPlugin(t, "serve", "ldap://example.com", config.Config{"sleep": 30.0, "pluginTimeout": 1.0})
+
+	start := time.Now()
+
+	status := string(d.Ping())
+	if status != StatusNotOK || !strings.HasSuffix(d.GetMessage(), ErrorTimeout.Error()) {
+		t.Errorf("expected NOT_OK %q, got %s %q", ErrorTimeout.Error(), status, d.GetMessage())
+	}
+
+	// the plugin is killed, not waited for
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected the plugin to be killed after the timeout, took %s", elapsed)
+	}
+}
+
+func TestPluginNoCommand(t *testing.T) {
+	d := NewPlugin(false, log.New(io.Discard, "", 0))
+	d.SetConfig(config.Config{})
+
+	if err := d.Connect(); err != ErrorNoCommand {
+		t.Errorf("expected %v, got %v", ErrorNoCommand, err)
+	}
+}
@@ -0,0 +1,86 @@
+package rpcplugin
+
+import (
+	"encoding/json"
+)
+
+// The protocol between tob and an out-of-process plugin is newline delimited JSON over stdio.
+// tob writes one Request per line to the plugin stdin, the plugin writes one Response per line to its stdout.
+// Anything the plugin writes to stderr is logged by tob.
+//
+// A session is
+//
+//	handshake  -> the plugin chooses one of the protocol versions offered by tob
+//	configure  -> the service url and config
+//	ping       -> repeated on every check interval
+//	close      -> the plugin should exit
+const (
+	// ProtocolVersion the latest protocol version
+	ProtocolVersion = 1
+
+	// MethodHandshake negotiates the protocol version
+	MethodHandshake = "handshake"
+
+	// MethodConfigure sends the service url and config
+	MethodConfigure = "configure"
+
+	// MethodPing checks the service
+	MethodPing = "ping"
+
+	// MethodClose asks the plugin to exit
+	MethodClose = "close"
+
+	// StatusOK the service is up
+	StatusOK = "OK"
+
+	// StatusDegraded the service is degraded
+	StatusDegraded = "DEGRADED"
+
+	// StatusNotOK the service is down
+	StatusNotOK = "NOT_OK"
+)
+
+// SupportedVersions the protocol versions supported by this tob build
+var SupportedVersions = []int{ProtocolVersion}
+
+// Request represent a message sent by tob
+type Request struct {
+	ID     uint64          `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Response represent a message sent by the plugin, either Result or Error is set
+type Response struct {
+	ID     uint64          `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *ResponseError  `json:"error,omitempty"`
+}
+
+// ResponseError represent a failed request
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+// HandshakeParams params of handshake request
+type HandshakeParams struct {
+	ProtocolVersions []int `json:"protocolVersions"`
+}
+
+// HandshakeResult result of handshake request
+type HandshakeResult struct {
+	ProtocolVersion int    `json:"protocolVersion"`
+	Name            string `json:"name"`
+}
+
+// ConfigureParams params of configure request
+type ConfigureParams struct {
+	URL    string                 `json:"url"`
+	Config map[string]interface{} `json:"config"`
+}
+
+// PingResult result of ping request
+type PingResult struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,101 @@
+package rpcplugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Handler is implemented by a plugin written in Go, plugins in other languages implement the protocol directly
+type Handler interface {
+
+	// Configure will receive the service url and config
+	Configure(url string, config map[string]interface{}) error
+
+	// Ping will check the service and return StatusOK, StatusDegraded or StatusNotOK with a message
+	Ping() (string, string)
+}
+
+// Serve will serve the protocol on stdin and stdout until tob sends close or closes stdin
+func Serve(name string, handler Handler) error {
+	return serve(name, handler, os.Stdin, os.Stdout)
+}
+
+func serve(name string, handler Handler, in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	encoder := json.NewEncoder(out)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		var request Request
+		err = json.Unmarshal(line, &request)
+		if err != nil {
+			return err
+		}
+
+		result, err := handle(name, handler, request)
+
+		response := Response{ID: request.ID}
+		if err != nil {
+			response.Error = &ResponseError{Message: err.Error()}
+		} else {
+			response.Result, err = json.Marshal(result)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = encoder.Encode(response)
+		if err != nil {
+			return err
+		}
+
+		if request.Method == MethodClose {
+			return nil
+		}
+	}
+}
+
+func handle(name string, handler Handler, request Request) (interface{}, error) {
+	switch request.Method {
+	case MethodHandshake:
+		var params HandshakeParams
+		err := json.Unmarshal(request.Params, &params)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, version := range params.ProtocolVersions {
+			if version == ProtocolVersion {
+				return HandshakeResult{ProtocolVersion: ProtocolVersion, Name: name}, nil
+			}
+		}
+
+		return nil, fmt.Errorf("protocol versions %v are not supported", params.ProtocolVersions)
+	case MethodConfigure:
+		var params ConfigureParams
+		err := json.Unmarshal(request.Params, &params)
+		if err != nil {
+			return nil, err
+		}
+
+		return struct{}{}, handler.Configure(params.URL, params.Config)
+	case MethodPing:
+		status, message := handler.Ping()
+		return PingResult{Status: status, Message: message}, nil
+	case MethodClose:
+		return struct{}{}, nil
+	default:
+		return nil, fmt.Errorf("method %s is not supported", request.Method)
+	}
+}
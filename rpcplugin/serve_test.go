@@ -0,0 +1,166 @@
+package rpcplugin
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// testHandler answers the pings with its status, and fails to configure a url named invalid
+type testHandler struct {
+	url    string
+	config map[string]interface{}
+	status string
+}
+
+func (h *testHandler) Configure(url string, config map[string]interface{}) error {
+	if url == "invalid" {
+		return errors.New("url is not valid")
+	}
+
+	h.url = url
+	h.config = config
+	return nil
+}
+
+func (h *testHandler) Ping() (string, string) {
+	return h.status, "checked " + h.url
+}
+
+// servePipe will run serve in-process, requests are written to the returned encoder
+// and responses are read from the returned decoder
+func servePipe(t *testing.T, handler Handler) (*json.Encoder, *json.Decoder, chan error) {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+
+	served := make(chan error, 1)
+	go func() {
+		err := serve("test", handler, inReader, outWriter)
+		outWriter.Close()
+		served <- err
+	}()
+
+	t.Cleanup(func() { inWriter.Close() })
+
+	return json.NewEncoder(inWriter), json.NewDecoder(outReader), served
+}
+
+func request(t *testing.T, encoder *json.Encoder, decoder *json.Decoder, id uint64, method string, params interface{}) Response {
+	t.Helper()
+
+	req := Request{ID: id, Method: method}
+	if params != nil {
+		var err error
+		req.Params, err = json.Marshal(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := encoder.Encode(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var response Response
+	err = decoder.Decode(&response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.ID != id {
+		t.Fatalf("expected response %d, got %d", id, response.ID)
+	}
+
+	return response
+}
+
+func TestServeSession(t *testing.T) {
+	handler := &testHandler{status: StatusDegraded}
+	encoder, decoder, served := servePipe(t, handler)
+
+	// tob offers several versions, the plugin chooses the one it speaks
+	response := request(t, encoder, decoder, 1, MethodHandshake, HandshakeParams{ProtocolVersions: []int{ProtocolVersion + 1, ProtocolVersion}})
+
+	var handshake HandshakeResult
+	if response.Error != nil || json.Unmarshal(response.Result, &handshake) != nil {
+		t.Fatalf("handshake failed: %+v", response)
+	}
+
+	if handshake != (HandshakeResult{ProtocolVersion: ProtocolVersion, Name: "test"}) {
+		t.Errorf("unexpected handshake %+v", handshake)
+	}
+
+	response = request(t, encoder, decoder, 2, MethodConfigure, ConfigureParams{URL: "ldap://example.com", Config: map[string]interface{}{"baseDn": "dc=example"}})
+	if response.Error != nil || handler.url != "ldap://example.com" || handler.config["baseDn"] != "dc=example" {
+		t.Errorf("configure failed: %+v, handler %+v", response, handler)
+	}
+
+	response = request(t, encoder, decoder, 3, MethodConfigure, ConfigureParams{URL: "invalid"})
+	if response.Error == nil || response.Error.Message != "url is not valid" {
+		t.Errorf("expected the configure error, got %+v", response)
+	}
+
+	response = request(t, encoder, decoder, 4, MethodPing, nil)
+
+	var ping PingResult
+	if response.Error != nil || json.Unmarshal(response.Result, &ping) != nil {
+		t.Fatalf("ping failed: %+v", response)
+	}
+
+	if ping != (PingResult{Status: StatusDegraded, Message: "checked ldap://example.com"}) {
+		t.Errorf("unexpected ping %+v", ping)
+	}
+
+	response = request(t, encoder, decoder, 5, "reload", nil)
+	if response.Error == nil || response.Error.Message != "method reload is not supported" {
+		t.Errorf("expected an unsupported method error, got %+v", response)
+	}
+
+	// close is answered, then serve returns
+	response = request(t, encoder, decoder, 6, MethodClose, nil)
+	if response.Error != nil {
+		t.Errorf("close failed: %+v", response)
+	}
+
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("expected serve to return after close, got %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return after close")
+	}
+}
+
+func TestServeUnknownVersion(t *testing.T) {
+	encoder, decoder, _ := servePipe(t, &testHandler{})
+
+	response := request(t, encoder, decoder, 1, MethodHandshake, HandshakeParams{ProtocolVersions: []int{ProtocolVersion + 1}})
+	if response.Error == nil || len(response.Result) != 0 {
+		t.Errorf("expected the handshake to fail, got %+v", response)
+	}
+}
+
+func TestServeEndOfInput(t *testing.T) {
+	inReader, inWriter := io.Pipe()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- serve("test", &testHandler{}, inReader, io.Discard)
+	}()
+
+	// tob closes the plugin stdin without close
+	inWriter.Close()
+
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("expected serve to return at the end of stdin, got %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return at the end of stdin")
+	}
+}
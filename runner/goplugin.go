@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"fmt"
+	"log"
+	"plugin"
+	"sync"
+
+	"github.com/telkomdev/tob"
+)
+
+var (
+	goPluginsMutex sync.Mutex
+
+	// goPlugins the factory of every opened Go plugin by its path, a .so file can only be opened once
+	goPlugins = make(map[string]tob.ServiceFactory)
+)
+
+// goPluginFactory will open the Go plugin (.so) and return its service factory.
+// A plugin exports NewService, func(verbose bool, logger *log.Logger) tob.Service, which is called for every service.
+// A legacy plugin exports the Service variable only, so every service of the plugin shares the same instance
+func goPluginFactory(pluginPath string) (tob.ServiceFactory, error) {
+	goPluginsMutex.Lock()
+	defer goPluginsMutex.Unlock()
+
+	if factory, ok := goPlugins[pluginPath]; ok {
+		return factory, nil
+	}
+
+	plug, err := plugin.Open(pluginPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open plugin %s: %s", pluginPath, err.Error())
+	}
+
+	var factory tob.ServiceFactory
+
+	if newServiceSymbol, err := plug.Lookup("NewService"); err == nil {
+		newService, ok := newServiceSymbol.(func(bool, *log.Logger) tob.Service)
+		if !ok {
+			return nil, fmt.Errorf("plugin %s: NewService is not func(verbose bool, logger *log.Logger) tob.Service", pluginPath)
+		}
+
+		factory = func(verbose bool, logger *log.Logger) tob.Service {
+			return newService(verbose, logger)
+		}
+	} else {
+		serviceSymbol, err := plug.Lookup("Service")
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s exports neither NewService nor Service", pluginPath)
+		}
+
+		s, ok := serviceSymbol.(tob.Service)
+		if !ok {
+			return nil, fmt.Errorf("plugin %s: Service is not a valid tob.Service", pluginPath)
+		}
+
+		var used bool
+		factory = func(verbose bool, logger *log.Logger) tob.Service {
+			if used {
+				logger.Printf("plugin %s exports Service only, its services share the same instance, export NewService instead\n", pluginPath)
+			}
+
+			used = true
+			return s
+		}
+	}
+
+	goPlugins[pluginPath] = factory
+
+	return factory, nil
+}
@@ -5,24 +5,34 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
-	"plugin"
 	"time"
 
 	"github.com/telkomdev/tob"
 	"github.com/telkomdev/tob/config"
-	"github.com/telkomdev/tob/services/airflow"
-	"github.com/telkomdev/tob/services/diskstatus"
-	"github.com/telkomdev/tob/services/dummy"
-	"github.com/telkomdev/tob/services/elasticsearch"
-	"github.com/telkomdev/tob/services/kafka"
-	"github.com/telkomdev/tob/services/mongodb"
-	"github.com/telkomdev/tob/services/mysqldb"
-	"github.com/telkomdev/tob/services/oracle"
-	"github.com/telkomdev/tob/services/postgres"
-	"github.com/telkomdev/tob/services/redisdb"
-	"github.com/telkomdev/tob/services/sslstatus"
-	"github.com/telkomdev/tob/services/web"
+	"github.com/telkomdev/tob/events"
+	"github.com/telkomdev/tob/rpcplugin"
 	"github.com/telkomdev/tob/util"
+
+	// service kinds register themselves
+	_ "github.com/telkomdev/tob/services/airflow"
+	_ "github.com/telkomdev/tob/services/certfile"
+	_ "github.com/telkomdev/tob/services/diskstatus"
+	_ "github.com/telkomdev/tob/services/domainexpiry"
+	_ "github.com/telkomdev/tob/services/dummy"
+	_ "github.com/telkomdev/tob/services/elasticsearch"
+	_ "github.com/telkomdev/tob/services/execcheck"
+	_ "github.com/telkomdev/tob/services/heartbeat"
+	_ "github.com/telkomdev/tob/services/hoststatus"
+	_ "github.com/telkomdev/tob/services/kafka"
+	_ "github.com/telkomdev/tob/services/mongodb"
+	_ "github.com/telkomdev/tob/services/mysqldb"
+	_ "github.com/telkomdev/tob/services/nats"
+	_ "github.com/telkomdev/tob/services/oracle"
+	_ "github.com/telkomdev/tob/services/postgres"
+	_ "github.com/telkomdev/tob/services/rabbitmq"
+	_ "github.com/telkomdev/tob/services/redisdb"
+	_ "github.com/telkomdev/tob/services/sslstatus"
+	_ "github.com/telkomdev/tob/services/web"
 )
 
 // Runner the tob runner
@@ -33,6 +43,7 @@ type Runner struct {
 	verbose     bool
 	initialized bool
 	waiter      tob.Waiter
+	events      *events.Bus
 }
 
 // NewRunner Runner's constructor
@@ -48,55 +59,93 @@ func NewRunner(configs config.Config, verbose bool) (*Runner, error) {
 
 	runner.verbose = verbose
 
+	runner.events = events.NewBus()
+
 	return runner, nil
 }
 
-func lookupPlugin(pluginPath string) (tob.Service, error) {
-	plug, err := plugin.Open(pluginPath)
-	if err != nil {
-		return nil, err
+func initServiceKind(serviceKind tob.ServiceKind, pluginPath, pluginCommand string, verbose bool) (tob.Service, bool, error) {
+	// an out-of-process plugin is preferred, a Go plugin (.so) is the legacy mode
+	if serviceKind == tob.Plugin && pluginCommand == "" && pluginPath != "" {
+		factory, err := goPluginFactory(pluginPath)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return factory(verbose, tob.Logger), true, nil
 	}
 
-	serviceSymbol, err := plug.Lookup("Service")
-	if err != nil {
-		return nil, err
+	s, ok := tob.NewService(serviceKind, verbose)
+	return s, ok, nil
+}
+
+// RegisterPluginKinds will register every plugin of the plugins config as its own service kind,
+// eg: "plugins": {"ldap": {"pluginCommand": "/opt/tob/ldap-check"}}
+func RegisterPluginKinds(configs config.Config) error {
+	pluginsInterface, ok := configs["plugins"]
+	if !ok {
+		return nil
 	}
 
-	s, ok := serviceSymbol.(tob.Service)
+	pluginConfigs, ok := pluginsInterface.(map[string]interface{})
 	if !ok {
-		return nil, errors.New("symbol is not valid tob.Service")
+		return errors.New("field plugins is not valid")
 	}
 
-	return s, nil
-}
+	for name, confInterface := range pluginConfigs {
+		conf, ok := confInterface.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("plugin %s config is not valid", name)
+		}
 
-func initServiceKind(serviceKind tob.ServiceKind, pluginPath string, verbose bool) (tob.Service, bool) {
-	services := make(map[tob.ServiceKind]tob.Service)
-	services[tob.Airflow] = airflow.NewAirflow(verbose, tob.Logger)
-	services[tob.AirflowFlower] = airflow.NewAirflowFlower(verbose, tob.Logger)
-	services[tob.Dummy] = dummy.NewDummy(verbose, tob.Logger)
-	services[tob.DiskStatus] = diskstatus.NewDiskStatus(verbose, tob.Logger)
-	services[tob.Kafka] = kafka.NewKafka(verbose, tob.Logger)
-	services[tob.MongoDB] = mongodb.NewMongo(verbose, tob.Logger)
-	services[tob.MySQL] = mysqldb.NewMySQL(verbose, tob.Logger)
-	services[tob.Postgresql] = postgres.NewPostgres(verbose, tob.Logger)
-	services[tob.Oracle] = oracle.NewOracle(verbose, tob.Logger)
-	services[tob.Redis] = redisdb.NewRedis(verbose, tob.Logger)
-	services[tob.Web] = web.NewWeb(verbose, tob.Logger)
-	services[tob.SSLStatus] = sslstatus.NewSSLStatus(verbose, tob.Logger)
-	services[tob.Elasticsearch] = elasticsearch.NewElasticsearch(verbose, tob.Logger)
-
-	if pluginPath != "" {
-		servicePlugin, err := lookupPlugin(pluginPath)
-		if err != nil {
-			panic(err)
+		kind := tob.ServiceKind(name)
+		if _, ok := tob.LookupServiceKind(kind); ok {
+			return fmt.Errorf("plugin %s: service kind %s is already registered", name, kind)
 		}
 
-		services[tob.Plugin] = servicePlugin
+		description, _ := conf["description"].(string)
+		pluginPath, _ := conf["pluginPath"].(string)
+		pluginCommand, _ := conf["pluginCommand"].(string)
+
+		info := tob.ServiceKindInfo{
+			Kind:        kind,
+			Description: description,
+			Plugin:      true,
+		}
+
+		switch {
+		case pluginCommand != "":
+			var args []string
+			argsInterface, _ := conf["pluginArgs"].([]interface{})
+			for _, arg := range argsInterface {
+				args = append(args, fmt.Sprintf("%v", arg))
+			}
+
+			info.Factory = rpcplugin.NewPluginKind(kind, pluginCommand, args)
+			if info.Description == "" {
+				info.Description = fmt.Sprintf("plugin %s", pluginCommand)
+			}
+		case pluginPath != "":
+			factory, err := goPluginFactory(pluginPath)
+			if err != nil {
+				// like a service plugin, a plugin kind that cannot be opened does not stop the other services
+				tob.Logger.Printf("plugin %s: %s, the kind is not registered\n", name, err.Error())
+				continue
+			}
+
+			info.Factory = factory
+
+			if info.Description == "" {
+				info.Description = fmt.Sprintf("Go plugin %s", pluginPath)
+			}
+		default:
+			return fmt.Errorf("plugin %s: pluginCommand or pluginPath is required", name)
+		}
+
+		tob.RegisterServiceKind(info)
 	}
 
-	s, ok := services[serviceKind]
-	return s, ok
+	return nil
 }
 
 // Add will add new service to Runner
@@ -108,6 +157,11 @@ func (r *Runner) Add(service tob.Service) {
 
 // InitServices will init initial services
 func (r *Runner) InitServices() error {
+	err := RegisterPluginKinds(r.configs)
+	if err != nil {
+		return err
+	}
+
 	serviceConfigInterface, ok := r.configs["service"]
 	if !ok {
 		return errors.New("field service not found in config file")
@@ -157,14 +211,26 @@ func (r *Runner) InitServices() error {
 			pluginPath = ""
 		}
 
+		pluginCommand, ok := conf["pluginCommand"].(string)
+		if !ok {
+			pluginCommand = ""
+		}
+
 		serviceEnabled, ok := conf["enable"].(bool)
 		if !ok {
 			return errors.New("invalid config file")
 		}
 
 		if serviceEnabled {
-			if s, ok := initServiceKind(tob.ServiceKind(serviceKind), pluginPath, r.verbose); ok {
+			s, ok, err := initServiceKind(tob.ServiceKind(serviceKind), pluginPath, pluginCommand, r.verbose)
+			switch {
+			case err != nil:
+				// a plugin that cannot be opened does not stop the other services
+				tob.Logger.Printf("service %s: %s, the service is skipped\n", name, err.Error())
+			case ok:
 				r.services[name] = s
+			default:
+				tob.Logger.Printf("service %s: unknown kind %s, run tob list-kinds to see the available kinds\n", name, serviceKind)
 			}
 		}
 
@@ -206,7 +272,37 @@ func (r *Runner) InitServices() error {
 	return nil
 }
 
-func healthCheck(n string, s tob.Service, t *time.Ticker, waiter tob.Waiter) {
+// Events will return the events bus of the checks
+func (r *Runner) Events() *events.Bus {
+	return r.events
+}
+
+// eventStatus will convert the Ping result to the status of an event
+func eventStatus(resp string) string {
+	switch resp {
+	case tob.OK:
+		return "UP"
+	case tob.Degraded:
+		return "DEGRADED"
+	default:
+		return "DOWN"
+	}
+}
+
+// send will send the notification message, a tob.StatusNotificator receives the status of the service as well
+func send(notificator tob.Notificator, name, status, details, msg string) error {
+	if statusNotificator, ok := notificator.(tob.StatusNotificator); ok {
+		return statusNotificator.SendStatus(name, status, details, msg)
+	}
+
+	return notificator.Send(msg)
+}
+
+func healthCheck(n string, s tob.Service, t *time.Ticker, waiter tob.Waiter, bus *events.Bus) {
+
+	// lastStatus keeps the previous Ping result,
+	// so a change between DEGRADED and NOT_OK is notified as well
+	lastStatus := tob.OK
 
 	for {
 		select {
@@ -224,26 +320,59 @@ func healthCheck(n string, s tob.Service, t *time.Ticker, waiter tob.Waiter) {
 			// set message to empty
 			s.SetMessage("")
 
+			checkedAt := time.Now()
 			resp := s.Ping()
 			respStr := string(resp)
+			latency := time.Since(checkedAt).Milliseconds()
+
+			checkEvent := events.Event{
+				Kind:      events.Check,
+				Service:   n,
+				Status:    eventStatus(respStr),
+				CheckedAt: checkedAt,
+				Latency:   latency,
+				Message:   s.GetMessage(),
+			}
+
+			if resultService, ok := s.(tob.ResultService); ok {
+				checkEvent.Results = resultService.Results()
+			}
+
+			bus.Publish(checkEvent)
+
+			// publish will publish the status of the service to the events bus
+			publish := func(status, details string) {
+				bus.Publish(events.Event{
+					Kind:      events.Status,
+					Service:   n,
+					Status:    status,
+					CheckedAt: checkedAt,
+					Latency:   latency,
+					Message:   details,
+				})
+			}
 
 			// Airflow Monitoring
 			if s.Name() == string(tob.Airflow) {
+				state := "DOWN"
+				notificatorMessage := fmt.Sprintf("%s is DOWN", n)
+				if s.GetMessage() != "" {
+					state = "CHECKING"
+					if respStr == tob.NotOk {
+						state = "DOWN"
+					}
+
+					notificatorMessage = fmt.Sprintf("%s is %s | %s", n, state, s.GetMessage())
+				}
+
+				publish(state, s.GetMessage())
+
 				for _, notificator := range s.GetNotificators() {
 					if !util.IsNilish(notificator) {
 						if notificator.IsEnabled() && notificator.Provider() == "webhook" {
-							notificatorMessage := fmt.Sprintf("%s is DOWN", n)
-							if s.GetMessage() != "" {
-								notificatorMessage = fmt.Sprintf("%s is CHECKING | %s", n, s.GetMessage())
-								if respStr == tob.NotOk {
-									notificatorMessage = fmt.Sprintf("%s is DOWN | %s", n, s.GetMessage())
-								}
-							}
-							if notificator.IsEnabled() && s.Name() != string(tob.SSLStatus) {
-								err := notificator.Send(notificatorMessage)
-								if err != nil {
-									tob.Logger.Printf("notificator %s error: %s", notificator.Provider(), err.Error())
-								}
+							err := send(notificator, n, state, s.GetMessage(), notificatorMessage)
+							if err != nil {
+								tob.Logger.Printf("notificator %s error: %s", notificator.Provider(), err.Error())
 							}
 						}
 					}
@@ -252,14 +381,19 @@ func healthCheck(n string, s tob.Service, t *time.Ticker, waiter tob.Waiter) {
 
 			// SSL Monitoring
 			if s.Name() == string(tob.SSLStatus) {
+				state := "DOWN"
+				notificatorMessage := fmt.Sprintf("%s is DOWN", n)
+				if s.GetMessage() != "" {
+					state = "MONITORED"
+					notificatorMessage = fmt.Sprintf("%s is MONITORED | %s", n, s.GetMessage())
+				}
+
+				publish(state, s.GetMessage())
+
 				for _, notificator := range s.GetNotificators() {
 					if !util.IsNilish(notificator) {
 						if notificator.IsEnabled() && notificator.Provider() == "webhook" {
-							notificatorMessage := fmt.Sprintf("%s is DOWN", n)
-							if s.GetMessage() != "" {
-								notificatorMessage = fmt.Sprintf("%s is MONITORED | %s", n, s.GetMessage())
-							}
-							err := notificator.Send(notificatorMessage)
+							err := send(notificator, n, state, s.GetMessage(), notificatorMessage)
 							if err != nil {
 								tob.Logger.Printf("notificator %s error: %s", notificator.Provider(), err.Error())
 							}
@@ -268,21 +402,29 @@ func healthCheck(n string, s tob.Service, t *time.Ticker, waiter tob.Waiter) {
 				}
 			}
 
-			if respStr == tob.NotOk && s.IsRecover() {
+			unhealthy := respStr == tob.NotOk || respStr == tob.Degraded
+			if unhealthy && (s.IsRecover() || respStr != lastStatus) {
 				// set last downtime
 				s.SetLastDownTimeNow()
 				// set recover to false
 				s.SetRecover(false)
 
-				notificatorMessage := fmt.Sprintf("%s is DOWN", n)
+				state := eventStatus(respStr)
+
+				notificatorMessage := fmt.Sprintf("%s is %s", n, state)
 				if s.GetMessage() != "" {
-					notificatorMessage = fmt.Sprintf("%s is DOWN | %s", n, s.GetMessage())
+					notificatorMessage = fmt.Sprintf("%s is %s | %s", n, state, s.GetMessage())
+				}
+
+				// the SSL status is published by the SSL Monitoring
+				if s.Name() != string(tob.SSLStatus) {
+					publish(state, s.GetMessage())
 				}
 
 				for _, notificator := range s.GetNotificators() {
 					if !util.IsNilish(notificator) {
 						if notificator.IsEnabled() && s.Name() != string(tob.SSLStatus) {
-							err := notificator.Send(notificatorMessage)
+							err := send(notificator, n, state, s.GetMessage(), notificatorMessage)
 							if err != nil {
 								tob.Logger.Printf("notificator %s error: %s", notificator.Provider(), err.Error())
 							}
@@ -301,10 +443,14 @@ func healthCheck(n string, s tob.Service, t *time.Ticker, waiter tob.Waiter) {
 					notificatorMessage = fmt.Sprintf("%s is UP | %s", n, s.GetMessage())
 				}
 
+				if s.Name() != string(tob.SSLStatus) {
+					publish("UP", "")
+				}
+
 				for _, notificator := range s.GetNotificators() {
 					if !util.IsNilish(notificator) {
 						if notificator.IsEnabled() && s.Name() != string(tob.SSLStatus) {
-							err := notificator.Send(notificatorMessage)
+							err := send(notificator, n, "UP", s.GetMessage(), notificatorMessage)
 							if err != nil {
 								tob.Logger.Printf("notificator %s error: %s\n", notificator.Provider(), err.Error())
 							}
@@ -313,6 +459,8 @@ func healthCheck(n string, s tob.Service, t *time.Ticker, waiter tob.Waiter) {
 				}
 			}
 
+			lastStatus = respStr
+
 			tob.Logger.Printf("%s => %s\n", n, respStr)
 		}
 	}
@@ -333,7 +481,7 @@ func (r *Runner) Run(ctx context.Context) {
 			ticker := time.NewTicker(time.Second * time.Duration(service.GetCheckInterval()))
 
 			// run all services health check on its goroutine
-			go healthCheck(name, service, ticker, r.waiter)
+			go healthCheck(name, service, ticker, r.waiter, r.events)
 
 		}
 	}
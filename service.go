@@ -2,6 +2,7 @@ package tob
 
 import (
 	"github.com/telkomdev/tob/config"
+	"github.com/telkomdev/tob/events"
 )
 
 // ServiceKind represent a type/kind of service
@@ -29,6 +30,9 @@ var (
 	// Elasticsearch service kind
 	Elasticsearch ServiceKind = "elasticsearch"
 
+	// OpenSearch service kind
+	OpenSearch ServiceKind = "opensearch"
+
 	// Airflow service kind
 	Airflow ServiceKind = "airflow"
 
@@ -38,15 +42,36 @@ var (
 	// DiskStatus service kind
 	DiskStatus ServiceKind = "diskstatus"
 
+	// Heartbeat service kind
+	Heartbeat ServiceKind = "heartbeat"
+
+	// HostStatus service kind
+	HostStatus ServiceKind = "hoststatus"
+
 	// Kafka servie kind
 	Kafka ServiceKind = "kafka"
 
+	// RabbitMQ service kind
+	RabbitMQ ServiceKind = "rabbitmq"
+
+	// NATS service kind
+	NATS ServiceKind = "nats"
+
 	// Plugin service kind
 	Plugin ServiceKind = "plugin"
 
 	// DiskStatus service kind
 	SSLStatus ServiceKind = "sslstatus"
 
+	// CertFile service kind
+	CertFile ServiceKind = "certfile"
+
+	// DomainExpiry service kind
+	DomainExpiry ServiceKind = "domainexpiry"
+
+	// Exec service kind
+	Exec ServiceKind = "exec"
+
 	// Dummy service kind
 	Dummy ServiceKind = "dummy"
 )
@@ -111,3 +136,9 @@ type Service interface {
 	// Stop will receive stop channel
 	Stop() chan bool
 }
+
+// ResultService a Service that checks several targets and reports the result of each target
+type ResultService interface {
+	// Results will return the latest result of every target
+	Results() []events.Result
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/telkomdev/tob"
@@ -29,6 +30,7 @@ type Airflow struct {
 	checkInterval            int
 	stopChan                 chan bool
 	message                  string
+	configs                  config.Config
 	notificatorConfig        config.Config
 }
 
@@ -183,6 +185,24 @@ func (a *Airflow) Ping() []byte {
 		a.logger.Printf("airflow: scheduler (%s), metadatabase (%s)\n", a.schedulerStatus, a.metadatabaseStatus)
 	}
 
+	api, ok := a.configs["api"].(map[string]interface{})
+	if !ok {
+		return []byte("OK")
+	}
+
+	criticals, warnings := a.checkAPI(parseAPIConfig(a.url, api))
+
+	// the API problems are appended to the scheduler and metadatabase status
+	if len(criticals) > 0 {
+		a.SetMessage(fmt.Sprintf("%s\n\n%s", a.GetMessage(), strings.Join(append(criticals, warnings...), "\n")))
+		return []byte("NOT_OK")
+	}
+
+	if len(warnings) > 0 {
+		a.SetMessage(fmt.Sprintf("%s\n\n%s", a.GetMessage(), strings.Join(warnings, "\n")))
+		return []byte("DEGRADED")
+	}
+
 	return []byte("OK")
 }
 
@@ -263,7 +283,7 @@ func (a *Airflow) GetMessage() string {
 
 // SetConfig will set config
 func (a *Airflow) SetConfig(configs config.Config) {
-
+	a.configs = configs
 }
 
 // SetNotificatorConfig will set config
@@ -0,0 +1,324 @@
+package airflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/telkomdev/tob/httpx"
+	"github.com/telkomdev/tob/util"
+)
+
+const (
+	// SeverityWarning failed check marks the service DEGRADED
+	SeverityWarning = "warning"
+
+	// SeverityCritical failed check marks the service DOWN
+	SeverityCritical = "critical"
+)
+
+// apiConfig represent api field of the service config
+type apiConfig struct {
+	url                     string
+	headers                 map[string]string
+	dags                    []dagConfig
+	checkImportErrors       bool
+	warningPoolUsedPercent  float64
+	criticalPoolUsedPercent float64
+}
+
+// dagConfig represent an item of api.dags field of the service config
+type dagConfig struct {
+	dagID                string
+	failedWithinMinutes  float64
+	maxSuccessAgeMinutes float64
+	severity             string
+}
+
+// dagRun represent the part of DAG run reply used by tob
+type dagRun struct {
+	DagRunID string     `json:"dag_run_id"`
+	State    string     `json:"state"`
+	EndDate  *time.Time `json:"end_date"`
+}
+
+func parseAPIConfig(serviceURL string, api map[string]interface{}) apiConfig {
+	apiURL, ok := api["url"].(string)
+	if !ok || apiURL == "" {
+		// the REST API lives next to the /health endpoint of the webserver
+		apiURL = strings.TrimSuffix(strings.TrimSuffix(serviceURL, "/"), "/health") + "/api/v1"
+	}
+
+	headers := make(map[string]string)
+	headers["Accept"] = "application/json"
+
+	username, _ := api["username"].(string)
+	password, _ := api["password"].(string)
+	token, _ := api["token"].(string)
+
+	if token != "" {
+		headers["Authorization"] = "Bearer " + token
+	} else if username != "" {
+		headers["Authorization"] = httpx.BasicAuth(username, password)
+	}
+
+	checkImportErrors, _ := api["checkImportErrors"].(bool)
+
+	var dags []dagConfig
+	dagInterfaces, _ := api["dags"].([]interface{})
+	for _, dagInterface := range dagInterfaces {
+		dag, ok := dagInterface.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		dagID, ok := dag["dagId"].(string)
+		if !ok || dagID == "" {
+			continue
+		}
+
+		severity, ok := dag["severity"].(string)
+		if !ok || severity == "" {
+			severity = SeverityCritical
+		}
+
+		dags = append(dags, dagConfig{
+			dagID:                dagID,
+			failedWithinMinutes:  util.InterfaceToFloat64(dag["failedWithinMinutes"]),
+			maxSuccessAgeMinutes: util.InterfaceToFloat64(dag["maxSuccessAgeMinutes"]),
+			severity:             severity,
+		})
+	}
+
+	return apiConfig{
+		url:                     strings.TrimSuffix(apiURL, "/"),
+		headers:                 headers,
+		dags:                    dags,
+		checkImportErrors:       checkImportErrors,
+		warningPoolUsedPercent:  util.InterfaceToFloat64(api["warningPoolUsedPercent"]),
+		criticalPoolUsedPercent: util.InterfaceToFloat64(api["criticalPoolUsedPercent"]),
+	}
+}
+
+// get will execute HTTP GET to the REST API and decode the JSON reply
+func (a *Airflow) get(api apiConfig, path string, query url.Values, v interface{}) error {
+	apiURL := api.url + path
+	if len(query) > 0 {
+		apiURL += "?" + query.Encode()
+	}
+
+	resp, err := httpx.HTTPGet(apiURL, api.headers, 10)
+	if err != nil {
+		return err
+	}
+
+	defer func() { resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("airflow API %s status: %d", path, resp.StatusCode)
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// checkAPI will check DAG runs, import errors and pools through the REST API
+func (a *Airflow) checkAPI(api apiConfig) ([]string, []string) {
+	var (
+		criticals []string
+		warnings  []string
+	)
+
+	for _, dag := range api.dags {
+		messages := a.checkDag(api, dag)
+		if dag.severity == SeverityWarning {
+			warnings = append(warnings, messages...)
+		} else {
+			criticals = append(criticals, messages...)
+		}
+	}
+
+	if api.checkImportErrors {
+		c, w := a.checkImportErrors(api)
+		criticals = append(criticals, c...)
+		warnings = append(warnings, w...)
+	}
+
+	if api.warningPoolUsedPercent > 0 || api.criticalPoolUsedPercent > 0 {
+		c, w := a.checkPools(api)
+		criticals = append(criticals, c...)
+		warnings = append(warnings, w...)
+	}
+
+	return criticals, warnings
+}
+
+// checkDag will check failed runs in the last failedWithinMinutes,
+// and whether the latest successful run is older than maxSuccessAgeMinutes
+func (a *Airflow) checkDag(api apiConfig, dag dagConfig) []string {
+	var messages []string
+
+	path := fmt.Sprintf("/dags/%s/dagRuns", url.PathEscape(dag.dagID))
+	now := time.Now().UTC()
+
+	if dag.failedWithinMinutes > 0 {
+		since := now.Add(-time.Duration(dag.failedWithinMinutes * float64(time.Minute)))
+
+		var failed struct {
+			DagRuns      []dagRun `json:"dag_runs"`
+			TotalEntries int      `json:"total_entries"`
+		}
+
+		err := a.get(api, path, url.Values{
+			"state":        []string{"failed"},
+			"end_date_gte": []string{since.Format(time.RFC3339)},
+			"order_by":     []string{"-end_date"},
+			"limit":        []string{"1"},
+		}, &failed)
+		if err != nil {
+			return []string{fmt.Sprintf("DAG %s: cannot read DAG runs: %s", dag.dagID, err.Error())}
+		}
+
+		if failed.TotalEntries > 0 && len(failed.DagRuns) > 0 {
+			latest := failed.DagRuns[0]
+			messages = append(messages, fmt.Sprintf("DAG %s has %d failed runs in the last %.0f minutes, latest %s failed tasks: %s",
+				dag.dagID, failed.TotalEntries, dag.failedWithinMinutes, latest.DagRunID, a.failedTasks(api, dag.dagID, latest.DagRunID)))
+		}
+	}
+
+	if dag.maxSuccessAgeMinutes > 0 {
+		var success struct {
+			DagRuns []dagRun `json:"dag_runs"`
+		}
+
+		err := a.get(api, path, url.Values{
+			"state":    []string{"success"},
+			"order_by": []string{"-end_date"},
+			"limit":    []string{"1"},
+		}, &success)
+		if err != nil {
+			return append(messages, fmt.Sprintf("DAG %s: cannot read DAG runs: %s", dag.dagID, err.Error()))
+		}
+
+		if len(success.DagRuns) == 0 || success.DagRuns[0].EndDate == nil {
+			return append(messages, fmt.Sprintf("DAG %s has never run successfully", dag.dagID))
+		}
+
+		age := now.Sub(*success.DagRuns[0].EndDate).Minutes()
+
+		if a.verbose {
+			a.logger.Printf("airflow DAG %s latest success: %.0f minutes ago\n", dag.dagID, age)
+		}
+
+		if age > dag.maxSuccessAgeMinutes {
+			messages = append(messages, fmt.Sprintf("DAG %s latest successful run was %.0f minutes ago, expected within %.0f minutes",
+				dag.dagID, age, dag.maxSuccessAgeMinutes))
+		}
+	}
+
+	return messages
+}
+
+// failedTasks will return the failed task ids of the DAG run
+func (a *Airflow) failedTasks(api apiConfig, dagID, dagRunID string) string {
+	var tasks struct {
+		TaskInstances []struct {
+			TaskID string `json:"task_id"`
+		} `json:"task_instances"`
+	}
+
+	path := fmt.Sprintf("/dags/%s/dagRuns/%s/taskInstances", url.PathEscape(dagID), url.PathEscape(dagRunID))
+	err := a.get(api, path, url.Values{"state": []string{"failed"}}, &tasks)
+	if err != nil {
+		return "unknown"
+	}
+
+	var taskIDs []string
+	for _, task := range tasks.TaskInstances {
+		taskIDs = append(taskIDs, task.TaskID)
+	}
+
+	if len(taskIDs) == 0 {
+		return "none"
+	}
+
+	return strings.Join(taskIDs, ", ")
+}
+
+// checkImportErrors will check DAG files that cannot be imported by the scheduler
+func (a *Airflow) checkImportErrors(api apiConfig) ([]string, []string) {
+	var importErrors struct {
+		ImportErrors []struct {
+			Filename string `json:"filename"`
+		} `json:"import_errors"`
+		TotalEntries int `json:"total_entries"`
+	}
+
+	err := a.get(api, "/importErrors", nil, &importErrors)
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read import errors: %s", err.Error())}, nil
+	}
+
+	if importErrors.TotalEntries == 0 {
+		return nil, nil
+	}
+
+	var filenames []string
+	for _, importError := range importErrors.ImportErrors {
+		filenames = append(filenames, importError.Filename)
+	}
+
+	// a broken DAG file does not stop the other DAGs
+	return nil, []string{fmt.Sprintf("%d DAG import errors: %s", importErrors.TotalEntries, strings.Join(filenames, ", "))}
+}
+
+// checkPools will check used slots of every pool
+func (a *Airflow) checkPools(api apiConfig) ([]string, []string) {
+	var (
+		criticals []string
+		warnings  []string
+		pools     struct {
+			Pools []struct {
+				Name          string  `json:"name"`
+				Slots         float64 `json:"slots"`
+				OccupiedSlots float64 `json:"occupied_slots"`
+				QueuedSlots   float64 `json:"queued_slots"`
+			} `json:"pools"`
+		}
+	)
+
+	err := a.get(api, "/pools", nil, &pools)
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read pools: %s", err.Error())}, nil
+	}
+
+	for _, pool := range pools.Pools {
+		// -1 means unlimited slots
+		if pool.Slots <= 0 {
+			continue
+		}
+
+		usedPercent := pool.OccupiedSlots / pool.Slots * 100
+
+		if a.verbose {
+			a.logger.Printf("airflow pool %s: %.0f of %.0f slots used, %.0f queued\n", pool.Name, pool.OccupiedSlots, pool.Slots, pool.QueuedSlots)
+		}
+
+		if api.criticalPoolUsedPercent > 0 && usedPercent >= api.criticalPoolUsedPercent {
+			criticals = append(criticals, fmt.Sprintf("pool %s %.0f%% slots used (%.0f queued) exceeds critical threshold %.0f%%",
+				pool.Name, usedPercent, pool.QueuedSlots, api.criticalPoolUsedPercent))
+		} else if api.warningPoolUsedPercent > 0 && usedPercent >= api.warningPoolUsedPercent {
+			warnings = append(warnings, fmt.Sprintf("pool %s %.0f%% slots used (%.0f queued) exceeds warning threshold %.0f%%",
+				pool.Name, usedPercent, pool.QueuedSlots, api.warningPoolUsedPercent))
+		}
+	}
+
+	return criticals, warnings
+}
@@ -0,0 +1,243 @@
+package airflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/telkomdev/tob/config"
+	"github.com/telkomdev/tob/httpx"
+	"github.com/telkomdev/tob/util"
+)
+
+// flowerThreshold represent the Celery thresholds of the airflowflower service config
+type flowerThreshold struct {
+	headers                      map[string]string
+	criticalMinOnlineWorkers     int
+	warningMinOnlineWorkers      int
+	warningActiveTasks           float64
+	criticalActiveTasks          float64
+	warningReservedTasks         float64
+	criticalReservedTasks        float64
+	warningQueueLength           float64
+	criticalQueueLength          float64
+	queues                       []string
+	warningFailedTasksPerMinute  float64
+	criticalFailedTasksPerMinute float64
+}
+
+// failedCounter keeps the cumulative failed task count of the previous check
+type failedCounter struct {
+	failed    float64
+	checkedAt time.Time
+}
+
+func parseFlowerThreshold(configs config.Config) flowerThreshold {
+	headers := make(map[string]string)
+
+	username, _ := configs["username"].(string)
+	password, _ := configs["password"].(string)
+	if username != "" {
+		headers["Authorization"] = httpx.BasicAuth(username, password)
+	}
+
+	// by default at least one worker must be online
+	criticalMinOnlineWorkers := 1
+	if _, ok := configs["criticalMinOnlineWorkers"]; ok {
+		criticalMinOnlineWorkers = int(util.InterfaceToFloat64(configs["criticalMinOnlineWorkers"]))
+	}
+
+	var queues []string
+	queueInterfaces, _ := configs["queues"].([]interface{})
+	for _, queueInterface := range queueInterfaces {
+		if queue, ok := queueInterface.(string); ok {
+			queues = append(queues, queue)
+		}
+	}
+
+	return flowerThreshold{
+		headers:                      headers,
+		criticalMinOnlineWorkers:     criticalMinOnlineWorkers,
+		warningMinOnlineWorkers:      int(util.InterfaceToFloat64(configs["warningMinOnlineWorkers"])),
+		warningActiveTasks:           util.InterfaceToFloat64(configs["warningActiveTasks"]),
+		criticalActiveTasks:          util.InterfaceToFloat64(configs["criticalActiveTasks"]),
+		warningReservedTasks:         util.InterfaceToFloat64(configs["warningReservedTasks"]),
+		criticalReservedTasks:        util.InterfaceToFloat64(configs["criticalReservedTasks"]),
+		warningQueueLength:           util.InterfaceToFloat64(configs["warningQueueLength"]),
+		criticalQueueLength:          util.InterfaceToFloat64(configs["criticalQueueLength"]),
+		queues:                       queues,
+		warningFailedTasksPerMinute:  util.InterfaceToFloat64(configs["warningFailedTasksPerMinute"]),
+		criticalFailedTasksPerMinute: util.InterfaceToFloat64(configs["criticalFailedTasksPerMinute"]),
+	}
+}
+
+// taskThreshold will compare the task count of a worker with the thresholds
+func (threshold flowerThreshold) taskThreshold(worker, name string, value, warning, critical float64) ([]string, []string) {
+	if critical > 0 && value >= critical {
+		return []string{fmt.Sprintf("worker %s %.0f %s exceeds critical threshold %.0f", worker, value, name, critical)}, nil
+	}
+
+	if warning > 0 && value >= warning {
+		return nil, []string{fmt.Sprintf("worker %s %.0f %s exceeds warning threshold %.0f", worker, value, name, warning)}
+	}
+
+	return nil, nil
+}
+
+// taskCount will return the number of tasks, Flower returns either a number or a list of tasks
+func taskCount(tasks interface{}) float64 {
+	if list, ok := tasks.([]interface{}); ok {
+		return float64(len(list))
+	}
+
+	return util.InterfaceToFloat64(tasks)
+}
+
+func workerNames(workers []string) string {
+	if len(workers) == 0 {
+		return "none"
+	}
+
+	return strings.Join(workers, ", ")
+}
+
+// get will execute HTTP GET to the Flower API and decode the JSON reply
+func (af *AirflowFlower) get(threshold flowerThreshold, path string, v interface{}) error {
+	resp, err := httpx.HTTPGet(strings.TrimSuffix(af.url, "/")+path, threshold.headers, 10)
+	if err != nil {
+		return err
+	}
+
+	defer func() { resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("airflow-flower %s status: %d", path, resp.StatusCode)
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// checkFailedTaskRate will compare the failed tasks since the previous check with the thresholds
+func (af *AirflowFlower) checkFailedTaskRate(failed float64, threshold flowerThreshold) ([]string, []string) {
+	now := time.Now()
+	previous := af.lastFailed
+	af.lastFailed = &failedCounter{failed: failed, checkedAt: now}
+
+	// the counter is reset when Flower restarts
+	if previous == nil || failed < previous.failed {
+		return nil, nil
+	}
+
+	elapsed := now.Sub(previous.checkedAt).Minutes()
+	if elapsed <= 0 {
+		return nil, nil
+	}
+
+	rate := (failed - previous.failed) / elapsed
+
+	if af.verbose {
+		af.logger.Printf("airflow-flower failed tasks per minute: %.2f\n", rate)
+	}
+
+	if threshold.criticalFailedTasksPerMinute > 0 && rate >= threshold.criticalFailedTasksPerMinute {
+		return []string{fmt.Sprintf("%.2f failed tasks per minute exceeds critical threshold %.2f",
+			rate, threshold.criticalFailedTasksPerMinute)}, nil
+	}
+
+	if threshold.warningFailedTasksPerMinute > 0 && rate >= threshold.warningFailedTasksPerMinute {
+		return nil, []string{fmt.Sprintf("%.2f failed tasks per minute exceeds warning threshold %.2f",
+			rate, threshold.warningFailedTasksPerMinute)}
+	}
+
+	return nil, nil
+}
+
+// checkReservedTasks will check reserved (prefetched but not started) tasks of every worker
+func (af *AirflowFlower) checkReservedTasks(threshold flowerThreshold) ([]string, []string) {
+	var (
+		criticals []string
+		warnings  []string
+		workers   map[string]struct {
+			Reserved []interface{} `json:"reserved"`
+		}
+	)
+
+	err := af.get(threshold, "/api/workers", &workers)
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read workers: %s", err.Error())}, nil
+	}
+
+	names := make([]string, 0, len(workers))
+	for name := range workers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		c, w := threshold.taskThreshold(name, "reserved tasks", float64(len(workers[name].Reserved)),
+			threshold.warningReservedTasks, threshold.criticalReservedTasks)
+		criticals = append(criticals, c...)
+		warnings = append(warnings, w...)
+	}
+
+	return criticals, warnings
+}
+
+// checkQueues will check the backlog length of the queues, every active queue is checked
+// when no queue is configured
+func (af *AirflowFlower) checkQueues(threshold flowerThreshold) ([]string, []string) {
+	var (
+		criticals []string
+		warnings  []string
+		queues    struct {
+			ActiveQueues []struct {
+				Name     string  `json:"name"`
+				Messages float64 `json:"messages"`
+			} `json:"active_queues"`
+		}
+	)
+
+	err := af.get(threshold, "/api/queues/length", &queues)
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read queues length: %s", err.Error())}, nil
+	}
+
+	for _, queue := range queues.ActiveQueues {
+		if len(threshold.queues) > 0 && !contains(threshold.queues, queue.Name) {
+			continue
+		}
+
+		if af.verbose {
+			af.logger.Printf("airflow-flower queue %s length: %.0f\n", queue.Name, queue.Messages)
+		}
+
+		if threshold.criticalQueueLength > 0 && queue.Messages >= threshold.criticalQueueLength {
+			criticals = append(criticals, fmt.Sprintf("queue %s length %.0f exceeds critical threshold %.0f",
+				queue.Name, queue.Messages, threshold.criticalQueueLength))
+		} else if threshold.warningQueueLength > 0 && queue.Messages >= threshold.warningQueueLength {
+			warnings = append(warnings, fmt.Sprintf("queue %s length %.0f exceeds warning threshold %.0f",
+				queue.Name, queue.Messages, threshold.warningQueueLength))
+		}
+	}
+
+	return criticals, warnings
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
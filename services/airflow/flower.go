@@ -2,11 +2,11 @@ package airflow
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/telkomdev/tob"
@@ -21,13 +21,14 @@ type AirflowFlower struct {
 	recovered         bool
 	lastDownTime      string
 	workers           []map[string]interface{}
-	workerErr         bool
+	lastFailed        *failedCounter
 	enabled           bool
 	verbose           bool
 	logger            *log.Logger
 	checkInterval     int
 	stopChan          chan bool
 	message           string
+	configs           config.Config
 	notificatorConfig config.Config
 }
 
@@ -51,14 +52,14 @@ func (af *AirflowFlower) Name() string {
 }
 
 // checkWorkerStatus will check available worker status in Airflow cluster
-func (af *AirflowFlower) checkWorkerStatus(resp *http.Response) error {
+func (af *AirflowFlower) checkWorkerStatus(resp *http.Response, threshold flowerThreshold) ([]string, []string, error) {
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		if af.verbose {
 			af.logger.Printf("cannot read response body: %v\n", err)
 		}
 
-		return err
+		return nil, nil, err
 	}
 
 	defer func() { resp.Body.Close() }()
@@ -70,40 +71,67 @@ func (af *AirflowFlower) checkWorkerStatus(resp *http.Response) error {
 			af.logger.Printf("cannot read parse JSON body: %v\n", err)
 		}
 
-		return err
+		return nil, nil, err
 	}
 
+	var (
+		criticals      []string
+		warnings       []string
+		onlineWorkers  []string
+		offlineWorkers []string
+		failedTasks    float64
+	)
+
 	af.workers = af.workers[:0]
-	af.workerErr = true
 	for _, worker := range data["data"] {
-		wStatus := worker["status"].(bool)
-		wName := worker["hostname"]
-		if !wStatus && af.verbose {
-			af.logger.Printf("airflow worker %s is offline\n", wName)
+		// a worker without a valid status is reported as offline
+		wStatus, _ := worker["status"].(bool)
+		wName := fmt.Sprintf("%v", worker["hostname"])
+		if !wStatus {
+			if af.verbose {
+				af.logger.Printf("airflow worker %s is offline\n", wName)
+			}
+
+			offlineWorkers = append(offlineWorkers, wName)
 		} else {
-			// if there is any worker alive, means that airflow worker is healthy
-			// but need to check manually for offline worker
-			af.workerErr = false
+			onlineWorkers = append(onlineWorkers, wName)
+
+			c, w := threshold.taskThreshold(wName, "active tasks", taskCount(worker["active"]),
+				threshold.warningActiveTasks, threshold.criticalActiveTasks)
+			criticals = append(criticals, c...)
+			warnings = append(warnings, w...)
 		}
 
+		failedTasks += util.InterfaceToFloat64(worker["task-failed"])
+
 		// also append to workers if needed later
 		af.workers = append(af.workers, worker)
 	}
 
-	if af.workerErr {
-		if af.verbose {
-			af.logger.Println("no available online worker")
-		}
+	if af.verbose {
+		af.logger.Printf("airflow-flower online workers: %d, offline workers: %d\n", len(onlineWorkers), len(offlineWorkers))
+	}
 
-		return errors.New("no available online worker")
+	if len(onlineWorkers) < threshold.criticalMinOnlineWorkers {
+		criticals = append(criticals, fmt.Sprintf("%d online workers, expected at least %d, offline workers: %s",
+			len(onlineWorkers), threshold.criticalMinOnlineWorkers, workerNames(offlineWorkers)))
+	} else if len(onlineWorkers) < threshold.warningMinOnlineWorkers {
+		warnings = append(warnings, fmt.Sprintf("%d online workers, expected at least %d, offline workers: %s",
+			len(onlineWorkers), threshold.warningMinOnlineWorkers, workerNames(offlineWorkers)))
 	}
 
-	return nil
+	c, w := af.checkFailedTaskRate(failedTasks, threshold)
+	criticals = append(criticals, c...)
+	warnings = append(warnings, w...)
+
+	return criticals, warnings, nil
 }
 
 // Ping will try to ping the service
 func (af *AirflowFlower) Ping() []byte {
-	resp, err := httpx.HTTPGet(af.url+"?json=1", nil, 5)
+	threshold := parseFlowerThreshold(af.configs)
+
+	resp, err := httpx.HTTPGet(af.url+"?json=1", threshold.headers, 5)
 	if err != nil {
 		af.SetMessage(err.Error())
 		return []byte("NOT_OK")
@@ -111,6 +139,7 @@ func (af *AirflowFlower) Ping() []byte {
 
 	statusOK := resp.StatusCode >= 200 && resp.StatusCode < 300
 	if !statusOK {
+		resp.Body.Close()
 		af.SetMessage(fmt.Sprintf("airflow-flower Ping status: %d", resp.StatusCode))
 		if af.verbose {
 			af.logger.Printf("airflow-flower Ping status: %d\n", resp.StatusCode)
@@ -119,11 +148,34 @@ func (af *AirflowFlower) Ping() []byte {
 		return []byte("NOT_OK")
 	}
 
-	if err := af.checkWorkerStatus(resp); err != nil {
+	criticals, warnings, err := af.checkWorkerStatus(resp, threshold)
+	if err != nil {
 		af.SetMessage(err.Error())
 		return []byte("NOT_OK")
 	}
 
+	if threshold.warningReservedTasks > 0 || threshold.criticalReservedTasks > 0 {
+		c, w := af.checkReservedTasks(threshold)
+		criticals = append(criticals, c...)
+		warnings = append(warnings, w...)
+	}
+
+	if threshold.warningQueueLength > 0 || threshold.criticalQueueLength > 0 {
+		c, w := af.checkQueues(threshold)
+		criticals = append(criticals, c...)
+		warnings = append(warnings, w...)
+	}
+
+	if len(criticals) > 0 {
+		af.SetMessage(strings.Join(append(criticals, warnings...), "\n"))
+		return []byte("NOT_OK")
+	}
+
+	if len(warnings) > 0 {
+		af.SetMessage(strings.Join(warnings, "\n"))
+		return []byte("DEGRADED")
+	}
+
 	return []byte("OK")
 }
 
@@ -204,7 +256,7 @@ func (af *AirflowFlower) GetMessage() string {
 
 // SetConfig will set config
 func (f *AirflowFlower) SetConfig(configs config.Config) {
-
+	f.configs = configs
 }
 
 // SetNotificatorConfig will set config
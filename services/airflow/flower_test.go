@@ -0,0 +1,123 @@
+package airflow
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/telkomdev/tob/config"
+)
+
+// flowerWorkersPayload is a trimmed reply of Flower's /workers?json=1
+const flowerWorkersPayload = `{
+  "data": [
+    {
+      "hostname": "celery@worker-1",
+      "status": true,
+      "active": 1,
+      "processed": 120,
+      "loadavg": [0.12, 0.2, 0.18],
+      "task-received": 120,
+      "task-started": 120,
+      "task-succeeded": 110,
+      "task-failed": 10,
+      "task-retried": 0
+    },
+    {
+      "hostname": "celery@worker-2",
+      "status": false,
+      "active": 0,
+      "processed": 30,
+      "loadavg": [0.0, 0.0, 0.0],
+      "task-received": 30,
+      "task-started": 30,
+      "task-succeeded": 25,
+      "task-failed": 5,
+      "task-retried": 0
+    }
+  ]
+}`
+
+func newFlowerServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/workers" || req.URL.Query().Get("json") != "1" {
+			http.NotFound(resp, req)
+			return
+		}
+
+		resp.Header().Set("Content-Type", "application/json")
+		io.WriteString(resp, flowerWorkersPayload)
+	}))
+
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAirflowFlowerFailedTasks(t *testing.T) {
+	server := newFlowerServer(t)
+
+	tests := []struct {
+		name           string
+		configs        config.Config
+		previousFailed *failedCounter
+		status         string
+		message        string
+	}{
+		{
+			name:    "first check has no rate",
+			configs: config.Config{"criticalFailedTasksPerMinute": 1.0},
+			status:  "OK",
+		},
+		{
+			name:           "critical rate",
+			configs:        config.Config{"warningFailedTasksPerMinute": 5.0, "criticalFailedTasksPerMinute": 10.0},
+			previousFailed: &failedCounter{failed: 3},
+			status:         "NOT_OK",
+			message:        "failed tasks per minute exceeds critical threshold 10.00",
+		},
+		{
+			name:           "warning rate",
+			configs:        config.Config{"warningFailedTasksPerMinute": 5.0, "criticalFailedTasksPerMinute": 20.0},
+			previousFailed: &failedCounter{failed: 3},
+			status:         "DEGRADED",
+			message:        "failed tasks per minute exceeds warning threshold 5.00",
+		},
+		{
+			name:           "no new failed task",
+			configs:        config.Config{"warningFailedTasksPerMinute": 1.0},
+			previousFailed: &failedCounter{failed: 15},
+			status:         "OK",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			af := NewAirflowFlower(false, log.New(io.Discard, "", 0))
+			af.SetURL(server.URL + "/workers")
+			af.SetConfig(test.configs)
+
+			// the previous check was one minute ago
+			if test.previousFailed != nil {
+				test.previousFailed.checkedAt = time.Now().Add(-time.Minute)
+				af.lastFailed = test.previousFailed
+			}
+
+			status := string(af.Ping())
+			if status != test.status {
+				t.Fatalf("expected status %s, got %s: %s", test.status, status, af.GetMessage())
+			}
+
+			if test.message != "" && !strings.Contains(af.GetMessage(), test.message) {
+				t.Errorf("expected message to contain %q, got %q", test.message, af.GetMessage())
+			}
+
+			if af.lastFailed == nil || af.lastFailed.failed != 15 {
+				t.Errorf("expected 15 failed tasks to be counted, got %v", af.lastFailed)
+			}
+		})
+	}
+}
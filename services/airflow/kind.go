@@ -0,0 +1,43 @@
+package airflow
+
+import (
+	"log"
+
+	"github.com/telkomdev/tob"
+)
+
+func init() {
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.Airflow,
+		Description: "Airflow scheduler and metadatabase health, optionally DAG runs, import errors and pools via the REST API",
+		Options: []tob.Option{
+			{Name: "api", Type: "object", Description: "REST API checks: url, token or username and password, dags, checkImportErrors, warningPoolUsedPercent, criticalPoolUsedPercent"},
+		},
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewAirflow(verbose, logger)
+		},
+	})
+
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.AirflowFlower,
+		Description: "Celery workers and queues via Flower",
+		Options: []tob.Option{
+			{Name: "username", Type: "string", Description: "Flower basic auth username"},
+			{Name: "password", Type: "string", Description: "Flower basic auth password"},
+			{Name: "criticalMinOnlineWorkers", Type: "number", Description: "minimum online workers before the service is down, default 1"},
+			{Name: "warningMinOnlineWorkers", Type: "number", Description: "minimum online workers before the service is degraded"},
+			{Name: "warningActiveTasks", Type: "number", Description: "active tasks per worker before the service is degraded"},
+			{Name: "criticalActiveTasks", Type: "number", Description: "active tasks per worker before the service is down"},
+			{Name: "warningReservedTasks", Type: "number", Description: "reserved tasks per worker before the service is degraded"},
+			{Name: "criticalReservedTasks", Type: "number", Description: "reserved tasks per worker before the service is down"},
+			{Name: "queues", Type: "array", Description: "queue names checked against the queue length thresholds, default every queue"},
+			{Name: "warningQueueLength", Type: "number", Description: "queue length before the service is degraded"},
+			{Name: "criticalQueueLength", Type: "number", Description: "queue length before the service is down"},
+			{Name: "warningFailedTasksPerMinute", Type: "number", Description: "failed tasks per minute before the service is degraded"},
+			{Name: "criticalFailedTasksPerMinute", Type: "number", Description: "failed tasks per minute before the service is down"},
+		},
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewAirflowFlower(verbose, logger)
+		},
+	})
+}
@@ -0,0 +1,239 @@
+package certfile
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/telkomdev/tob"
+	"github.com/telkomdev/tob/config"
+	"github.com/telkomdev/tob/services/sslstatus"
+	"github.com/telkomdev/tob/util"
+)
+
+var (
+	// ErrorNoPaths error type
+	ErrorNoPaths = errors.New("error: paths is not in the certfile config")
+
+	// ErrorNoCertificate error type
+	ErrorNoCertificate = errors.New("error: no certificate found in paths")
+)
+
+// CertFile service
+type CertFile struct {
+	url               string
+	recovered         bool
+	lastDownTime      string
+	enabled           bool
+	verbose           bool
+	logger            *log.Logger
+	checkInterval     int
+	stopChan          chan bool
+	message           string
+	configs           config.Config
+	notificatorConfig config.Config
+}
+
+// NewCertFile CertFile's constructor
+func NewCertFile(verbose bool, logger *log.Logger) *CertFile {
+	stopChan := make(chan bool, 1)
+	return &CertFile{
+		logger:  logger,
+		verbose: verbose,
+
+		// by default service is recovered
+		recovered:     true,
+		checkInterval: 0,
+		stopChan:      stopChan,
+	}
+}
+
+// Name the name of the service
+func (d *CertFile) Name() string {
+	return "certfile"
+}
+
+// Ping will try to ping the service
+func (d *CertFile) Ping() []byte {
+	var paths []string
+	pathInterfaces, _ := d.configs["paths"].([]interface{})
+	for _, pathInterface := range pathInterfaces {
+		if path, ok := pathInterface.(string); ok && path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	if len(paths) == 0 {
+		d.SetMessage(ErrorNoPaths.Error())
+		return []byte("NOT_OK")
+	}
+
+	recursive, _ := d.configs["recursive"].(bool)
+
+	s := &scanner{
+		recursive:  recursive,
+		thresholds: sslstatus.ParseThresholds(d.configs),
+		seen:       make(map[[32]byte]bool),
+		visited:    make(map[string]bool),
+	}
+
+	certificates, errs := s.scan(paths)
+
+	// the certificate that expires first is listed first
+	sort.SliceStable(certificates, func(i, j int) bool {
+		return certificates[i].NotAfter.Before(certificates[j].NotAfter)
+	})
+
+	var (
+		sb    strings.Builder
+		worst = sslstatus.SeverityInfo
+	)
+
+	// a path that cannot be read hides the certificates that may expire
+	for _, err := range errs {
+		worst = sslstatus.SeverityDanger
+		sb.WriteString(fmt.Sprintf("%s | %s\n", sslstatus.SeverityDanger, err))
+	}
+
+	if len(certificates) == 0 && len(errs) == 0 {
+		worst = sslstatus.SeverityDanger
+		sb.WriteString(fmt.Sprintf("%s | %s\n", sslstatus.SeverityDanger, ErrorNoCertificate.Error()))
+	}
+
+	for _, certificate := range certificates {
+		if sslstatus.SeverityRank(certificate.Severity) > sslstatus.SeverityRank(worst) {
+			worst = certificate.Severity
+		}
+
+		expiry := fmt.Sprintf("expire in %d days", certificate.DaysLeft)
+		if certificate.NotAfter.Before(time.Now()) {
+			expiry = "expired"
+		}
+
+		line := fmt.Sprintf("%s | %s | %s | SANs: %s | %s | (%s)", certificate.Severity, certificate.Path,
+			certificate.Subject, sans(certificate.DNSNames), expiry, certificate.NotAfter.Format(time.RFC1123))
+
+		if d.verbose {
+			d.logger.Println(line)
+		}
+
+		sb.WriteString(line + "\n")
+	}
+
+	d.SetMessage(sb.String())
+
+	switch worst {
+	case sslstatus.SeverityDanger, sslstatus.SeverityCritical:
+		return []byte("NOT_OK")
+	case sslstatus.SeverityWarning:
+		return []byte("DEGRADED")
+	default:
+		return []byte("OK")
+	}
+}
+
+func sans(dnsNames []string) string {
+	if len(dnsNames) == 0 {
+		return "-"
+	}
+
+	return strings.Join(dnsNames, ", ")
+}
+
+// SetURL will set the service URL
+func (d *CertFile) SetURL(url string) {
+	d.url = url
+}
+
+// Connect to service if needed
+func (d *CertFile) Connect() error {
+	if d.verbose {
+		d.logger.Println("connect CertFile")
+	}
+
+	return nil
+}
+
+// Close will close the service resources if needed
+func (d *CertFile) Close() error {
+	if d.verbose {
+		d.logger.Println("close CertFile")
+	}
+
+	return nil
+}
+
+// SetRecover will set recovered status
+func (d *CertFile) SetRecover(recovered bool) {
+	d.recovered = recovered
+}
+
+// IsRecover will return recovered status
+func (d *CertFile) IsRecover() bool {
+	return d.recovered
+}
+
+// LastDownTime will set last down time of service to current time
+func (d *CertFile) SetLastDownTimeNow() {
+	if d.recovered {
+		d.lastDownTime = time.Now().Format(util.YYMMDD)
+	}
+}
+
+// GetDownTimeDiff will return down time service difference in minutes
+func (d *CertFile) GetDownTimeDiff() string {
+	return util.TimeDifference(d.lastDownTime, time.Now().Format(util.YYMMDD))
+}
+
+// SetCheckInterval will set check interval to service
+func (d *CertFile) SetCheckInterval(interval int) {
+	d.checkInterval = interval
+}
+
+// GetCheckInterval will return check interval to service
+func (d *CertFile) GetCheckInterval() int {
+	return d.checkInterval
+}
+
+// Enable will set enabled status to service
+func (d *CertFile) Enable(enabled bool) {
+	d.enabled = enabled
+}
+
+// IsEnabled will return enable status
+func (d *CertFile) IsEnabled() bool {
+	return d.enabled
+}
+
+// SetMessage will set additional message
+func (d *CertFile) SetMessage(message string) {
+	d.message = message
+}
+
+// GetMessage will return additional message
+func (d *CertFile) GetMessage() string {
+	return d.message
+}
+
+// SetConfig will set config
+func (d *CertFile) SetConfig(configs config.Config) {
+	d.configs = configs
+}
+
+// SetNotificatorConfig will set config
+func (d *CertFile) SetNotificatorConfig(configs config.Config) {
+	d.notificatorConfig = configs
+}
+
+// GetNotificators will return notificators
+func (d *CertFile) GetNotificators() []tob.Notificator {
+	return tob.InitNotificatorFactory(d.notificatorConfig, d.verbose)
+}
+
+// Stop will receive stop channel
+func (d *CertFile) Stop() chan bool {
+	return d.stopChan
+}
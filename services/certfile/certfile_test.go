@@ -0,0 +1,198 @@
+package certfile
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/telkomdev/tob/config"
+)
+
+// newCertPEM will return a self-signed PEM certificate that expires after the duration
+func newCertPEM(t *testing.T, commonName string, expireIn time.Duration) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		NotBefore:    time.Now().Add(-365 * 24 * time.Hour),
+		NotAfter:     time.Now().Add(expireIn),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(path, data, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func days(n int) time.Duration {
+	// an hour more, so the days left are not rounded down to n-1
+	return time.Duration(n)*24*time.Hour + time.Hour
+}
+
+// newCertDir will write certificates in the layouts scanned by certfile:
+//
+//	warning.pem                    expires in 20 days
+//	bundle.pem                     expires in 100 days, expired, and warning.pem again
+//	key.pem                        a private key, not a certificate
+//	secret/..2024_01_01/tls.crt    expires in 10 days
+//	secret/..data -> ..2024_01_01
+//	secret/tls.crt -> ..data/tls.crt
+func newCertDir(t *testing.T) string {
+	dir := t.TempDir()
+
+	warning := newCertPEM(t, "warning.example.com", days(20))
+	writeFile(t, filepath.Join(dir, "warning.pem"), warning)
+
+	var bundle []byte
+	bundle = append(bundle, newCertPEM(t, "info.example.com", days(100))...)
+	bundle = append(bundle, newCertPEM(t, "expired.example.com", -24*time.Hour)...)
+	bundle = append(bundle, warning...)
+	writeFile(t, filepath.Join(dir, "bundle.pem"), bundle)
+
+	writeFile(t, filepath.Join(dir, "key.pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("not a certificate")}))
+
+	secret := filepath.Join(dir, "secret")
+	writeFile(t, filepath.Join(secret, "..2024_01_01", "tls.crt"), newCertPEM(t, "danger.example.com", days(10)))
+
+	err := os.Symlink("..2024_01_01", filepath.Join(secret, "..data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.Symlink(filepath.Join("..data", "tls.crt"), filepath.Join(secret, "tls.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func TestCertFilePing(t *testing.T) {
+	dir := newCertDir(t)
+
+	tests := []struct {
+		name    string
+		configs config.Config
+		status  string
+
+		// lines the prefix of each line of the message, in order
+		lines []string
+	}{
+		{
+			name:    "no paths",
+			configs: config.Config{},
+			status:  "NOT_OK",
+			lines:   []string{ErrorNoPaths.Error()},
+		},
+		{
+			name:    "warning",
+			configs: config.Config{"paths": []interface{}{filepath.Join(dir, "warning.pem")}},
+			status:  "DEGRADED",
+			lines: []string{
+				"Warning | " + filepath.Join(dir, "warning.pem") + " | CN=warning.example.com | SANs: warning.example.com | expire in 20 days",
+			},
+		},
+		{
+			name:    "custom thresholds",
+			configs: config.Config{"paths": []interface{}{filepath.Join(dir, "warning.pem")}, "warningDays": 10.0},
+			status:  "OK",
+			lines:   []string{"Info | " + filepath.Join(dir, "warning.pem")},
+		},
+		{
+			name:    "file without certificate",
+			configs: config.Config{"paths": []interface{}{filepath.Join(dir, "key.pem")}},
+			status:  "NOT_OK",
+			lines:   []string{"Danger | " + ErrorNoCertificate.Error()},
+		},
+		{
+			name:    "kubernetes secret",
+			configs: config.Config{"paths": []interface{}{filepath.Join(dir, "secret")}},
+			status:  "NOT_OK",
+			lines: []string{
+				"Danger | " + filepath.Join(dir, "secret", "tls.crt") + " | CN=danger.example.com",
+			},
+		},
+		{
+			name: "recursive with missing path",
+			configs: config.Config{
+				"paths":     []interface{}{dir, filepath.Join(dir, "missing.pem")},
+				"recursive": true,
+			},
+			status: "NOT_OK",
+			lines: []string{
+				"Danger | " + filepath.Join(dir, "missing.pem") + ": no such file or directory",
+				"Critical | " + filepath.Join(dir, "bundle.pem") + " | CN=expired.example.com | SANs: expired.example.com | expired",
+				"Danger | " + filepath.Join(dir, "secret", "tls.crt") + " | CN=danger.example.com",
+				"Warning | " + filepath.Join(dir, "bundle.pem") + " | CN=warning.example.com",
+				"Info | " + filepath.Join(dir, "bundle.pem") + " | CN=info.example.com",
+			},
+		},
+		{
+			name:    "glob",
+			configs: config.Config{"paths": []interface{}{filepath.Join(dir, "*.pem")}},
+			status:  "NOT_OK",
+			lines: []string{
+				"Critical | " + filepath.Join(dir, "bundle.pem") + " | CN=expired.example.com",
+				"Warning | " + filepath.Join(dir, "bundle.pem") + " | CN=warning.example.com",
+				"Info | " + filepath.Join(dir, "bundle.pem") + " | CN=info.example.com",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewCertFile(false, log.New(io.Discard, "", 0))
+			c.SetConfig(test.configs)
+
+			status := string(c.Ping())
+			if status != test.status {
+				t.Errorf("expected status %s, got %s", test.status, status)
+			}
+
+			lines := strings.Split(strings.TrimSpace(c.GetMessage()), "\n")
+			if len(lines) != len(test.lines) {
+				t.Fatalf("expected %d lines, got %d:\n%s", len(test.lines), len(lines), c.GetMessage())
+			}
+
+			for i, line := range lines {
+				if !strings.HasPrefix(line, test.lines[i]) {
+					t.Errorf("expected line %d to start with %q, got %q", i, test.lines[i], line)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package certfile
+
+import (
+	"log"
+
+	"github.com/telkomdev/tob"
+)
+
+func init() {
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.CertFile,
+		Description: "expiry of PEM certificates on disk",
+		Options: []tob.Option{
+			{Name: "paths", Type: "array", Required: true, Description: "files, directories or glob patterns to scan"},
+			{Name: "recursive", Type: "bool", Description: "scan directories recursively"},
+			{Name: "warningDays", Type: "number", Description: "days left before the service is degraded"},
+			{Name: "dangerDays", Type: "number", Description: "days left before the service is down"},
+			{Name: "criticalDays", Type: "number", Description: "days left before the service is down as critical"},
+		},
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewCertFile(verbose, logger)
+		},
+	})
+}
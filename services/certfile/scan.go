@@ -0,0 +1,175 @@
+package certfile
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/telkomdev/tob/services/sslstatus"
+)
+
+// maxFileSize files bigger than this size are not read, certificates and bundles are small
+const maxFileSize = 1 << 20
+
+// Certificate represent a certificate found on disk
+type Certificate struct {
+	Path     string
+	Subject  string
+	DNSNames []string
+	NotAfter time.Time
+	DaysLeft int
+	Severity string
+}
+
+// scanner will find PEM certificates in files, directories and globs
+type scanner struct {
+	recursive  bool
+	thresholds sslstatus.Thresholds
+	seen       map[[32]byte]bool
+	visited    map[string]bool
+}
+
+// scan will return the certificates of every path, and the paths that cannot be read
+func (s *scanner) scan(paths []string) ([]Certificate, []string) {
+	var (
+		certificates []Certificate
+		errs         []string
+	)
+
+	for _, path := range paths {
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", path, err.Error()))
+			continue
+		}
+
+		if len(matches) == 0 {
+			errs = append(errs, fmt.Sprintf("%s: no such file or directory", path))
+			continue
+		}
+
+		for _, match := range matches {
+			c, e := s.scanPath(match, true)
+			certificates = append(certificates, c...)
+			errs = append(errs, e...)
+		}
+	}
+
+	return certificates, errs
+}
+
+// scanPath will scan a file, or the files of a directory
+func (s *scanner) scanPath(path string, top bool) ([]Certificate, []string) {
+	// symbolic links are followed, Kubernetes mounts secret keys as links to a timestamped directory
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("%s: %s", path, err.Error())}
+	}
+
+	if !info.IsDir() {
+		return s.scanFile(path, info)
+	}
+
+	if !top && !s.recursive {
+		return nil, nil
+	}
+
+	// avoid symbolic link loops
+	realPath, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		if s.visited[realPath] {
+			return nil, nil
+		}
+
+		s.visited[realPath] = true
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("%s: %s", path, err.Error())}
+	}
+
+	var (
+		certificates []Certificate
+		errs         []string
+	)
+
+	for _, entry := range entries {
+		// the ..data link and ..timestamp directories of a Kubernetes secret hold the same files again
+		if strings.HasPrefix(entry.Name(), "..") {
+			continue
+		}
+
+		c, e := s.scanPath(filepath.Join(path, entry.Name()), false)
+		certificates = append(certificates, c...)
+		errs = append(errs, e...)
+	}
+
+	return certificates, errs
+}
+
+// scanFile will parse every PEM certificate of the file, files without certificate (eg: private keys) are ignored
+func (s *scanner) scanFile(path string, info fs.FileInfo) ([]Certificate, []string) {
+	if !info.Mode().IsRegular() || info.Size() > maxFileSize {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("%s: %s", path, err.Error())}
+	}
+
+	var (
+		certificates []Certificate
+		errs         []string
+	)
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", path, err.Error()))
+			continue
+		}
+
+		// the same certificate is often mounted in several places (eg: a CA inside every bundle)
+		checksum := sha256.Sum256(cert.Raw)
+		if s.seen[checksum] {
+			continue
+		}
+
+		s.seen[checksum] = true
+
+		daysLeft := int(time.Until(cert.NotAfter).Hours() / 24)
+		severity := s.thresholds.Severity(daysLeft)
+		if cert.NotAfter.Before(time.Now()) {
+			severity = sslstatus.SeverityCritical
+		}
+
+		certificates = append(certificates, Certificate{
+			Path:     path,
+			Subject:  cert.Subject.String(),
+			DNSNames: cert.DNSNames,
+			NotAfter: cert.NotAfter,
+			DaysLeft: daysLeft,
+			Severity: severity,
+		})
+	}
+
+	return certificates, errs
+}
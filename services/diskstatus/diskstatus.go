@@ -1,18 +1,17 @@
 package diskstatus
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"log"
 	"net"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/telkomdev/tob"
+	"github.com/telkomdev/tob/agentclient"
 	"github.com/telkomdev/tob/config"
 	"github.com/telkomdev/tob/data"
-	"github.com/telkomdev/tob/httpx"
 	"github.com/telkomdev/tob/util"
 )
 
@@ -27,6 +26,8 @@ type DiskStatus struct {
 	checkInterval     int
 	stopChan          chan bool
 	message           string
+	agent             *agentclient.Client
+	samples           map[string][]usageSample
 	configs           config.Config
 	notificatorConfig config.Config
 }
@@ -48,6 +49,7 @@ func NewDiskStatus(verbose bool, logger *log.Logger) *DiskStatus {
 		recovered:     true,
 		checkInterval: 0,
 		stopChan:      stopChan,
+		samples:       make(map[string][]usageSample),
 	}
 }
 
@@ -89,94 +91,130 @@ func (d *DiskStatus) resolveIPv4() string {
 
 // Ping will try to ping the service
 func (d *DiskStatus) Ping() []byte {
-	fileSystemPathStr, ok := d.configs["fileSystem"].(string)
-	if !ok {
+	thresholds := parsePathThresholds(d.configs)
+	if len(thresholds) == 0 {
 		if d.verbose {
-			d.logger.Println("fileSystemPathStr is not valid")
+			d.logger.Println("paths or fileSystem is not valid")
 		}
+		d.SetMessage("paths or fileSystem is required")
 		return []byte("NOT_OK")
 	}
 
-	if d.verbose {
-		d.logger.Printf("tob-http-agent check %s file system\n", fileSystemPathStr)
+	if d.agent == nil {
+		return []byte("NOT_OK")
 	}
 
-	fileSystemPayload := data.FileSystem{
-		Path: fileSystemPathStr,
+	window := util.InterfaceToFloat64(d.configs["growthWindow"])
+	if window <= 0 {
+		window = DefaultGrowthWindow
 	}
 
-	payloadJSON, err := json.Marshal(fileSystemPayload)
-	if err != nil {
-		if d.verbose {
-			d.logger.Println(err)
-		}
-		return []byte("NOT_OK")
+	var criticals, warnings, lines []string
+	for _, threshold := range thresholds {
+		line, c, w := d.checkPath(threshold, time.Duration(window*float64(time.Minute)))
+		lines = append(lines, line)
+		criticals = append(criticals, c...)
+		warnings = append(warnings, w...)
 	}
 
-	headers := make(map[string]string)
-	headers["Content-Type"] = "application/json"
+	ipv4 := d.resolveIPv4()
 
-	resp, err := httpx.HTTPPost(fmt.Sprintf("%s/check-disk", d.url), bytes.NewBuffer(payloadJSON), headers, 5)
-	if err != nil {
-		if d.verbose {
-			d.logger.Println(err)
-		}
-		return []byte("NOT_OK")
+	if d.verbose {
+		d.logger.Println("IP :", ipv4)
+		d.logger.Println("criticals: ", criticals)
+		d.logger.Println("warnings: ", warnings)
 	}
 
-	statusOK := resp.StatusCode >= 200 && resp.StatusCode < 300
-	if !statusOK {
-		if d.verbose {
-			d.logger.Printf("DiskStatus Ping status: %d\n", resp.StatusCode)
-		}
+	status := "OK"
+	summary := "disk usage is within the thresholds"
+	problems := append(criticals, warnings...)
+	if len(criticals) > 0 {
+		status = "NOT_OK"
+		summary = "disk usage exceeds the critical threshold"
+	} else if len(warnings) > 0 {
+		status = "DEGRADED"
+		summary = "disk usage exceeds the warning threshold"
+	}
 
-		return []byte("NOT_OK")
+	message := fmt.Sprintf("%s\nIP: %s\n%s", summary, ipv4, strings.Join(lines, "\n"))
+	if len(problems) > 0 {
+		message = fmt.Sprintf("%s\n%s", message, strings.Join(problems, "\n"))
 	}
 
+	d.SetMessage(fmt.Sprintf("%s\n%s", message, "-------------------------------------"))
+	return []byte(status)
+}
+
+// checkPath will check the disk usage of a path with tob-http-agent,
+// it returns the message line of the mount with the critical and warning problems
+func (d *DiskStatus) checkPath(threshold pathThreshold, window time.Duration) (string, []string, []string) {
 	if d.verbose {
-		d.logger.Printf("DiskStatus Ping status: %d\n", resp.StatusCode)
+		d.logger.Printf("tob-http-agent check %s file system\n", threshold.path)
 	}
 
-	defer func() { resp.Body.Close() }()
-
 	var target target
 
-	err = json.NewDecoder(resp.Body).Decode(&target)
+	err := d.agent.Post("/check-disk", data.FileSystem{Path: threshold.path}, &target)
 	if err != nil {
 		if d.verbose {
 			d.logger.Println(err)
 		}
-
-		return []byte("NOT_OK")
+		return fmt.Sprintf("%s: check failed", threshold.path), []string{fmt.Sprintf("%s: %s", threshold.path, err.Error())}, nil
 	}
 
 	if d.verbose {
 		d.logger.Println(target)
 	}
 
-	thresholdDiskUsage := util.InterfaceToFloat64(d.configs["thresholdDiskUsage"])
-
 	diskUsed := util.InterfaceToFloat64(target.Data["diskUsed"])
-	filesystem := target.Data["filesystem"]
+	inodesUsed := util.InterfaceToFloat64(target.Data["inodesUsed"])
+	used := util.InterfaceToFloat64(target.Data["used"])
+	available := util.InterfaceToFloat64(target.Data["available"])
 
-	ipv4 := d.resolveIPv4()
+	mounted, _ := target.Data["mounted"].(string)
+	if mounted == "" {
+		mounted = threshold.path
+	}
 
-	if d.verbose {
-		d.logger.Println("IP :", ipv4)
-		d.logger.Println("threshold disk usage: ", thresholdDiskUsage)
-		d.logger.Println("disk used: ", diskUsed)
-		d.logger.Println("file system: ", filesystem)
+	filesystem, _ := target.Data["filesystem"].(string)
+
+	var criticals, warnings []string
+	prefix := func(problems []string) []string {
+		for i := range problems {
+			problems[i] = fmt.Sprintf("%s: %s", threshold.path, problems[i])
+		}
+		return problems
 	}
 
-	if diskUsed >= thresholdDiskUsage {
-		d.SetMessage(fmt.Sprintf("disk used exceeds the threshold\nIP: %s\nthreshold: %d%s\ndisk used: %d%s\nfile system: %s\n%s",
-			ipv4, int(thresholdDiskUsage), "%", int(diskUsed), "%", filesystem, "-------------------------------------"))
-		return []byte("NOT_OK")
+	c, w := compare("disk used %", diskUsed, threshold.warningPercent, threshold.criticalPercent, false)
+	criticals = append(criticals, prefix(c)...)
+	warnings = append(warnings, prefix(w)...)
+
+	// older agents do not report the inode usage
+	if _, ok := target.Data["inodesUsed"]; ok {
+		c, w = compare("inodes used %", inodesUsed, threshold.warningInodePercent, threshold.criticalInodePercent, false)
+		criticals = append(criticals, prefix(c)...)
+		warnings = append(warnings, prefix(w)...)
 	}
 
-	d.SetMessage(fmt.Sprintf("disk storage has been increased\nIP: %s\nthreshold: %d%s\ndisk used: %d%s\nfile system: %s\n%s",
-		ipv4, int(thresholdDiskUsage), "%", int(diskUsed), "%", filesystem, "-------------------------------------"))
-	return []byte("OK")
+	name := mounted
+	if mounted != threshold.path {
+		name = fmt.Sprintf("%s on %s", threshold.path, mounted)
+	}
+
+	line := fmt.Sprintf("%s (%s): disk used %d%%, inodes used %d%%", name, filesystem, int(diskUsed), int(inodesUsed))
+
+	samples, hours, ok := hoursToFull(d.samples[threshold.path], usageSample{used: used, checkedAt: time.Now()}, available, window)
+	d.samples[threshold.path] = samples
+	if ok {
+		line = fmt.Sprintf("%s, full in %.1f hours", line, hours)
+
+		c, w = compare("hours to full", hours, threshold.warningHoursToFull, threshold.criticalHoursToFull, true)
+		criticals = append(criticals, prefix(c)...)
+		warnings = append(warnings, prefix(w)...)
+	}
+
+	return line, criticals, warnings
 }
 
 // SetURL will set the service URL
@@ -190,6 +228,13 @@ func (d *DiskStatus) Connect() error {
 		d.logger.Println("connect DiskStatus")
 	}
 
+	agent, err := agentclient.NewClient(d.url, d.configs, 5)
+	if err != nil {
+		return err
+	}
+
+	d.agent = agent
+
 	return nil
 }
 
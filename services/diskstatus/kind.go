@@ -0,0 +1,35 @@
+package diskstatus
+
+import (
+	"log"
+
+	"github.com/telkomdev/tob"
+)
+
+func init() {
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.DiskStatus,
+		Description: "disk usage reported by tob-http-agent",
+		Options: []tob.Option{
+			{Name: "paths", Type: "array", Description: "paths reported by the agent, an item is a path or an object with the path and its thresholds"},
+			{Name: "warningPercent", Type: "number", Description: "disk usage percent before the service is degraded"},
+			{Name: "criticalPercent", Type: "number", Description: "disk usage percent before the service is down"},
+			{Name: "warningInodePercent", Type: "number", Description: "inode usage percent before the service is degraded"},
+			{Name: "criticalInodePercent", Type: "number", Description: "inode usage percent before the service is down"},
+			{Name: "warningHoursToFull", Type: "number", Description: "projected hours to full before the service is degraded"},
+			{Name: "criticalHoursToFull", Type: "number", Description: "projected hours to full before the service is down"},
+			{Name: "growthWindow", Type: "number", Description: "minutes of usage growth used to project the hours to full, default 60"},
+			{Name: "fileSystem", Type: "string", Description: "single path reported by the agent, used when paths is not set"},
+			{Name: "thresholdDiskUsage", Type: "number", Description: "disk usage percent before the service is down, used when criticalPercent is not set"},
+			{Name: "token", Type: "string", Description: "bearer token of tob-http-agent"},
+			{Name: "hmacSecret", Type: "string", Description: "HMAC secret to sign the requests to tob-http-agent"},
+			{Name: "caFile", Type: "string", Description: "CA bundle used to verify tob-http-agent"},
+			{Name: "certFile", Type: "string", Description: "client certificate for mTLS"},
+			{Name: "keyFile", Type: "string", Description: "client certificate key for mTLS"},
+			{Name: "tlsSkipVerify", Type: "bool", Description: "skip TLS certificate verification"},
+		},
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewDiskStatus(verbose, logger)
+		},
+	})
+}
@@ -0,0 +1,141 @@
+package diskstatus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/telkomdev/tob/config"
+	"github.com/telkomdev/tob/util"
+)
+
+const (
+	// DefaultGrowthWindow default minutes of usage samples used to project when a disk is full
+	DefaultGrowthWindow = 60
+)
+
+// pathThreshold represent the thresholds of a path, a zero threshold is not checked
+type pathThreshold struct {
+	path                 string
+	warningPercent       float64
+	criticalPercent      float64
+	warningInodePercent  float64
+	criticalInodePercent float64
+	warningHoursToFull   float64
+	criticalHoursToFull  float64
+}
+
+// usageSample represent the used kilobytes of a path at a time
+type usageSample struct {
+	used      float64
+	checkedAt time.Time
+}
+
+// parsePathThresholds will parse paths, an item is a path or an object with the path and its own thresholds.
+// The thresholds of the service config are the defaults, the legacy fileSystem and thresholdDiskUsage are a single path
+func parsePathThresholds(configs config.Config) []pathThreshold {
+	defaults := pathThreshold{
+		warningPercent:       util.InterfaceToFloat64(configs["warningPercent"]),
+		criticalPercent:      util.InterfaceToFloat64(configs["criticalPercent"]),
+		warningInodePercent:  util.InterfaceToFloat64(configs["warningInodePercent"]),
+		criticalInodePercent: util.InterfaceToFloat64(configs["criticalInodePercent"]),
+		warningHoursToFull:   util.InterfaceToFloat64(configs["warningHoursToFull"]),
+		criticalHoursToFull:  util.InterfaceToFloat64(configs["criticalHoursToFull"]),
+	}
+
+	if _, ok := configs["criticalPercent"]; !ok {
+		defaults.criticalPercent = util.InterfaceToFloat64(configs["thresholdDiskUsage"])
+	}
+
+	var thresholds []pathThreshold
+
+	paths, _ := configs["paths"].([]interface{})
+	for _, pathInterface := range paths {
+		threshold := defaults
+
+		switch p := pathInterface.(type) {
+		case string:
+			threshold.path = p
+		case map[string]interface{}:
+			threshold.path, _ = p["path"].(string)
+			override(&threshold.warningPercent, p["warningPercent"])
+			override(&threshold.criticalPercent, p["criticalPercent"])
+			override(&threshold.warningInodePercent, p["warningInodePercent"])
+			override(&threshold.criticalInodePercent, p["criticalInodePercent"])
+			override(&threshold.warningHoursToFull, p["warningHoursToFull"])
+			override(&threshold.criticalHoursToFull, p["criticalHoursToFull"])
+		}
+
+		if threshold.path != "" {
+			thresholds = append(thresholds, threshold)
+		}
+	}
+
+	if len(thresholds) == 0 {
+		if fileSystem, ok := configs["fileSystem"].(string); ok && fileSystem != "" {
+			threshold := defaults
+			threshold.path = fileSystem
+			thresholds = append(thresholds, threshold)
+		}
+	}
+
+	return thresholds
+}
+
+func override(threshold *float64, value interface{}) {
+	if value != nil {
+		*threshold = util.InterfaceToFloat64(value)
+	}
+}
+
+// compare will compare value with the thresholds, a lower value is worse when lowerIsWorse is true
+func compare(name string, value, warning, critical float64, lowerIsWorse bool) ([]string, []string) {
+	exceedsText := "exceeds"
+	if lowerIsWorse {
+		exceedsText = "is below"
+	}
+
+	exceeds := func(threshold float64) bool {
+		if lowerIsWorse {
+			return value <= threshold
+		}
+
+		return value >= threshold
+	}
+
+	if critical > 0 && exceeds(critical) {
+		return []string{fmt.Sprintf("%s %.1f %s critical threshold %.1f", name, value, exceedsText, critical)}, nil
+	}
+
+	if warning > 0 && exceeds(warning) {
+		return nil, []string{fmt.Sprintf("%s %.1f %s warning threshold %.1f", name, value, exceedsText, warning)}
+	}
+
+	return nil, nil
+}
+
+// hoursToFull will add the sample and return the hours until the available kilobytes are used at the growth rate of the window,
+// it returns false when there are not enough samples or the usage does not grow
+func hoursToFull(samples []usageSample, sample usageSample, available float64, window time.Duration) ([]usageSample, float64, bool) {
+	samples = append(samples, sample)
+
+	// drop the samples older than the window
+	i := 0
+	for i < len(samples)-1 && sample.checkedAt.Sub(samples[i].checkedAt) > window {
+		i++
+	}
+
+	samples = samples[i:]
+
+	oldest := samples[0]
+	elapsed := sample.checkedAt.Sub(oldest.checkedAt).Hours()
+	if len(samples) < 2 || elapsed <= 0 {
+		return samples, 0, false
+	}
+
+	growthPerHour := (sample.used - oldest.used) / elapsed
+	if growthPerHour <= 0 {
+		return samples, 0, false
+	}
+
+	return samples, available / growthPerHour, true
+}
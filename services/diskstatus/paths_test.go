@@ -0,0 +1,157 @@
+package diskstatus
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/telkomdev/tob/config"
+)
+
+func TestParsePathThresholds(t *testing.T) {
+	tests := []struct {
+		name     string
+		configs  config.Config
+		expected []pathThreshold
+	}{
+		{
+			name:    "no paths",
+			configs: config.Config{"warningPercent": 80.0},
+		},
+		{
+			name:     "legacy fileSystem and thresholdDiskUsage",
+			configs:  config.Config{"fileSystem": "/dev/sda1", "thresholdDiskUsage": 90.0},
+			expected: []pathThreshold{{path: "/dev/sda1", criticalPercent: 90}},
+		},
+		{
+			name:     "criticalPercent replaces thresholdDiskUsage",
+			configs:  config.Config{"fileSystem": "/dev/sda1", "thresholdDiskUsage": 90.0, "criticalPercent": 95.0},
+			expected: []pathThreshold{{path: "/dev/sda1", criticalPercent: 95}},
+		},
+		{
+			name:     "paths replace fileSystem",
+			configs:  config.Config{"fileSystem": "/dev/sda1", "paths": []interface{}{"/", "/data"}, "thresholdDiskUsage": 90.0},
+			expected: []pathThreshold{{path: "/", criticalPercent: 90}, {path: "/data", criticalPercent: 90}},
+		},
+		{
+			name: "per path thresholds",
+			configs: config.Config{
+				"paths": []interface{}{
+					"/",
+					map[string]interface{}{"path": "/data", "criticalPercent": 98.0, "warningHoursToFull": 48.0},
+					map[string]interface{}{"path": "/tmp", "warningPercent": 0.0, "criticalInodePercent": 99.0},
+				},
+				"warningPercent":      80.0,
+				"criticalPercent":     90.0,
+				"warningInodePercent": 85.0,
+				"criticalHoursToFull": 6.0,
+			},
+			expected: []pathThreshold{
+				{path: "/", warningPercent: 80, criticalPercent: 90, warningInodePercent: 85, criticalHoursToFull: 6},
+				{path: "/data", warningPercent: 80, criticalPercent: 98, warningInodePercent: 85, warningHoursToFull: 48, criticalHoursToFull: 6},
+				{path: "/tmp", criticalPercent: 90, warningInodePercent: 85, criticalInodePercent: 99, criticalHoursToFull: 6},
+			},
+		},
+		{
+			name:     "invalid paths are skipped",
+			configs:  config.Config{"paths": []interface{}{"", 1.0, map[string]interface{}{"criticalPercent": 90.0}, "/"}},
+			expected: []pathThreshold{{path: "/"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			thresholds := parsePathThresholds(test.configs)
+			if !reflect.DeepEqual(thresholds, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, thresholds)
+			}
+		})
+	}
+}
+
+func TestHoursToFull(t *testing.T) {
+	now := time.Now()
+	at := func(minutesAgo int) time.Time {
+		return now.Add(-time.Duration(minutesAgo) * time.Minute)
+	}
+
+	tests := []struct {
+		name      string
+		samples   []usageSample
+		sample    usageSample
+		available float64
+
+		hours float64
+		ok    bool
+
+		// kept the number of samples kept for the next check
+		kept int
+	}{
+		{
+			name:      "first sample",
+			sample:    usageSample{used: 100, checkedAt: now},
+			available: 1000,
+			kept:      1,
+		},
+		{
+			name:      "growing usage",
+			samples:   []usageSample{{used: 100, checkedAt: at(60)}, {used: 150, checkedAt: at(30)}},
+			sample:    usageSample{used: 200, checkedAt: now},
+			available: 1000,
+			hours:     10,
+			ok:        true,
+			kept:      3,
+		},
+		{
+			name:      "samples older than the window are pruned",
+			samples:   []usageSample{{used: 0, checkedAt: at(180)}, {used: 100, checkedAt: at(90)}, {used: 150, checkedAt: at(30)}},
+			sample:    usageSample{used: 200, checkedAt: now},
+			available: 200,
+			hours:     2,
+			ok:        true,
+			kept:      2,
+		},
+		{
+			name:      "every previous sample is outside the window",
+			samples:   []usageSample{{used: 0, checkedAt: at(180)}, {used: 100, checkedAt: at(120)}},
+			sample:    usageSample{used: 200, checkedAt: now},
+			available: 1000,
+			kept:      1,
+		},
+		{
+			name:      "usage does not grow",
+			samples:   []usageSample{{used: 200, checkedAt: at(30)}},
+			sample:    usageSample{used: 200, checkedAt: now},
+			available: 1000,
+			kept:      2,
+		},
+		{
+			name:      "usage shrinks",
+			samples:   []usageSample{{used: 300, checkedAt: at(30)}},
+			sample:    usageSample{used: 200, checkedAt: now},
+			available: 1000,
+			kept:      2,
+		},
+		{
+			name:      "samples at the same time",
+			samples:   []usageSample{{used: 100, checkedAt: now}},
+			sample:    usageSample{used: 200, checkedAt: now},
+			available: 1000,
+			kept:      2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			samples, hours, ok := hoursToFull(test.samples, test.sample, test.available, time.Hour)
+
+			if ok != test.ok || hours != test.hours {
+				t.Errorf("expected %.2f hours %v, got %.2f hours %v", test.hours, test.ok, hours, ok)
+			}
+
+			if len(samples) != test.kept || samples[len(samples)-1] != test.sample {
+				t.Errorf("expected %d samples ending with the new one, got %+v", test.kept, samples)
+			}
+		})
+	}
+}
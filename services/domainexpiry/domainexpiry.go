@@ -0,0 +1,247 @@
+package domainexpiry
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/telkomdev/tob"
+	"github.com/telkomdev/tob/config"
+	"github.com/telkomdev/tob/services/sslstatus"
+	"github.com/telkomdev/tob/util"
+)
+
+// DefaultTimeout default RDAP request timeout in seconds
+const DefaultTimeout = 10
+
+var (
+	// ErrorNoDomains error type
+	ErrorNoDomains = errors.New("error: domains is not in the domainexpiry config")
+)
+
+// DomainExpiry service
+type DomainExpiry struct {
+	url               string
+	recovered         bool
+	lastDownTime      string
+	enabled           bool
+	verbose           bool
+	logger            *log.Logger
+	checkInterval     int
+	stopChan          chan bool
+	message           string
+	client            *rdapClient
+	configs           config.Config
+	notificatorConfig config.Config
+}
+
+// NewDomainExpiry DomainExpiry's constructor
+func NewDomainExpiry(verbose bool, logger *log.Logger) *DomainExpiry {
+	stopChan := make(chan bool, 1)
+	return &DomainExpiry{
+		logger:  logger,
+		verbose: verbose,
+
+		// by default service is recovered
+		recovered:     true,
+		checkInterval: 0,
+		stopChan:      stopChan,
+	}
+}
+
+// Name the name of the service
+func (d *DomainExpiry) Name() string {
+	return "domainexpiry"
+}
+
+// Ping will try to ping the service
+func (d *DomainExpiry) Ping() []byte {
+	if d.client == nil {
+		return []byte("NOT_OK")
+	}
+
+	var domains []string
+	domainInterfaces, _ := d.configs["domains"].([]interface{})
+	for _, domainInterface := range domainInterfaces {
+		if domain, ok := domainInterface.(string); ok && domain != "" {
+			domains = append(domains, strings.ToLower(strings.TrimSuffix(domain, ".")))
+		}
+	}
+
+	if len(domains) == 0 {
+		d.SetMessage(ErrorNoDomains.Error())
+		return []byte("NOT_OK")
+	}
+
+	thresholds := sslstatus.ParseThresholds(d.configs)
+
+	var (
+		sb    strings.Builder
+		worst = sslstatus.SeverityInfo
+	)
+
+	// domains are looked up one by one, RDAP servers rate limit their clients
+	for _, domain := range domains {
+		var line string
+
+		result, err := d.client.lookup(domain)
+		if err != nil {
+			// the registry may be unavailable, this does not mean the domain has lapsed
+			line = fmt.Sprintf("%s | %s | cannot read registration: %s", sslstatus.SeverityWarning, domain, err.Error())
+			if sslstatus.SeverityRank(sslstatus.SeverityWarning) > sslstatus.SeverityRank(worst) {
+				worst = sslstatus.SeverityWarning
+			}
+		} else {
+			daysLeft := int(time.Until(result.expiration).Hours() / 24)
+
+			severity := thresholds.Severity(daysLeft)
+			expiry := fmt.Sprintf("expire in %d days", daysLeft)
+			if result.expiration.Before(time.Now()) {
+				severity = sslstatus.SeverityCritical
+				expiry = "expired"
+			}
+
+			registrar := result.registrar
+			if registrar == "" {
+				registrar = "unknown registrar"
+			}
+
+			line = fmt.Sprintf("%s | %s | %s | %s | (%s)", severity, domain, registrar, expiry, result.expiration.Format(time.RFC1123))
+			if sslstatus.SeverityRank(severity) > sslstatus.SeverityRank(worst) {
+				worst = severity
+			}
+		}
+
+		if d.verbose {
+			d.logger.Println(line)
+		}
+
+		sb.WriteString(line + "\n")
+	}
+
+	d.SetMessage(sb.String())
+
+	switch worst {
+	case sslstatus.SeverityDanger, sslstatus.SeverityCritical:
+		return []byte("NOT_OK")
+	case sslstatus.SeverityWarning:
+		return []byte("DEGRADED")
+	default:
+		return []byte("OK")
+	}
+}
+
+// SetURL will set the service URL
+func (d *DomainExpiry) SetURL(url string) {
+	d.url = url
+}
+
+// Connect to service if needed
+func (d *DomainExpiry) Connect() error {
+	if d.verbose {
+		d.logger.Println("connect DomainExpiry")
+	}
+
+	serverURL, _ := d.configs["rdapUrl"].(string)
+
+	bootstrapURL, ok := d.configs["bootstrapUrl"].(string)
+	if !ok || bootstrapURL == "" {
+		bootstrapURL = DefaultBootstrapURL
+	}
+
+	timeout := int(util.InterfaceToFloat64(d.configs["timeout"]))
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	d.client = &rdapClient{
+		serverURL:    serverURL,
+		bootstrapURL: bootstrapURL,
+		timeout:      timeout,
+	}
+
+	return nil
+}
+
+// Close will close the service resources if needed
+func (d *DomainExpiry) Close() error {
+	if d.verbose {
+		d.logger.Println("close DomainExpiry")
+	}
+
+	return nil
+}
+
+// SetRecover will set recovered status
+func (d *DomainExpiry) SetRecover(recovered bool) {
+	d.recovered = recovered
+}
+
+// IsRecover will return recovered status
+func (d *DomainExpiry) IsRecover() bool {
+	return d.recovered
+}
+
+// LastDownTime will set last down time of service to current time
+func (d *DomainExpiry) SetLastDownTimeNow() {
+	if d.recovered {
+		d.lastDownTime = time.Now().Format(util.YYMMDD)
+	}
+}
+
+// GetDownTimeDiff will return down time service difference in minutes
+func (d *DomainExpiry) GetDownTimeDiff() string {
+	return util.TimeDifference(d.lastDownTime, time.Now().Format(util.YYMMDD))
+}
+
+// SetCheckInterval will set check interval to service
+func (d *DomainExpiry) SetCheckInterval(interval int) {
+	d.checkInterval = interval
+}
+
+// GetCheckInterval will return check interval to service
+func (d *DomainExpiry) GetCheckInterval() int {
+	return d.checkInterval
+}
+
+// Enable will set enabled status to service
+func (d *DomainExpiry) Enable(enabled bool) {
+	d.enabled = enabled
+}
+
+// IsEnabled will return enable status
+func (d *DomainExpiry) IsEnabled() bool {
+	return d.enabled
+}
+
+// SetMessage will set additional message
+func (d *DomainExpiry) SetMessage(message string) {
+	d.message = message
+}
+
+// GetMessage will return additional message
+func (d *DomainExpiry) GetMessage() string {
+	return d.message
+}
+
+// SetConfig will set config
+func (d *DomainExpiry) SetConfig(configs config.Config) {
+	d.configs = configs
+}
+
+// SetNotificatorConfig will set config
+func (d *DomainExpiry) SetNotificatorConfig(configs config.Config) {
+	d.notificatorConfig = configs
+}
+
+// GetNotificators will return notificators
+func (d *DomainExpiry) GetNotificators() []tob.Notificator {
+	return tob.InitNotificatorFactory(d.notificatorConfig, d.verbose)
+}
+
+// Stop will receive stop channel
+func (d *DomainExpiry) Stop() chan bool {
+	return d.stopChan
+}
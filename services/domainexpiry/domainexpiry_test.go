@@ -0,0 +1,332 @@
+package domainexpiry
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/telkomdev/tob/config"
+)
+
+// jCard will return the vcardArray of an entity named fn
+func jCard(fn string) []interface{} {
+	return []interface{}{"vcard", []interface{}{
+		[]interface{}{"version", map[string]interface{}{}, "text", "4.0"},
+		[]interface{}{"fn", map[string]interface{}{}, "text", fn},
+	}}
+}
+
+func TestVCardName(t *testing.T) {
+	tests := []struct {
+		name     string
+		vcard    []interface{}
+		expected string
+	}{
+		{name: "fn", vcard: jCard("Example Registrar, Inc."), expected: "Example Registrar, Inc."},
+		{name: "empty", vcard: nil},
+		{name: "no properties", vcard: []interface{}{"vcard"}},
+		{name: "properties not a list", vcard: []interface{}{"vcard", "fn"}},
+		{
+			name: "no fn",
+			vcard: []interface{}{"vcard", []interface{}{
+				[]interface{}{"version", map[string]interface{}{}, "text", "4.0"},
+				[]interface{}{"org", map[string]interface{}{}, "text", "Example"},
+			}},
+		},
+		{
+			name: "short property",
+			vcard: []interface{}{"vcard", []interface{}{
+				[]interface{}{"fn", map[string]interface{}{}},
+				[]interface{}{"fn", map[string]interface{}{}, "text", "Second"},
+			}},
+			expected: "Second",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if name := vcardName(test.vcard); name != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, name)
+			}
+		})
+	}
+}
+
+// newBootstrapServer will serve a bootstrap registry at /dns.json, the number of bootstrap requests is counted
+func newBootstrapServer(t *testing.T, fetches *int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/dns.json" {
+			http.NotFound(resp, req)
+			return
+		}
+
+		atomic.AddInt32(fetches, 1)
+		json.NewEncoder(resp).Encode(map[string]interface{}{
+			"version": "1.0",
+			"services": [][][]string{
+				{{"uk"}, {"https://rdap.uk.example/"}},
+				{{"co.uk"}, {"http://rdap.co-uk.example/", "https://rdap.co-uk.example/"}},
+				{{"COM", "net"}, {"https://rdap.com.example"}},
+				{{"broken"}},
+			},
+		})
+	}))
+
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRDAPClientBaseURL(t *testing.T) {
+	var fetches int32
+	server := newBootstrapServer(t, &fetches)
+
+	client := &rdapClient{bootstrapURL: server.URL + "/dns.json", timeout: 5}
+
+	tests := []struct {
+		domain   string
+		expected string
+		err      error
+	}{
+		{domain: "example.co.uk", expected: "https://rdap.co-uk.example/"},
+		{domain: "example.uk", expected: "https://rdap.uk.example/"},
+		{domain: "www.example.co.uk", expected: "https://rdap.co-uk.example/"},
+		{domain: "example.com", expected: "https://rdap.com.example"},
+		{domain: "example.net", expected: "https://rdap.com.example"},
+		{domain: "example.zz", err: ErrorNoRDAPServer},
+		{domain: "uk", err: ErrorNoRDAPServer},
+	}
+
+	for _, test := range tests {
+		base, err := client.baseURL(test.domain)
+		if err != test.err || base != test.expected {
+			t.Errorf("baseURL(%s) expected %q, %v, got %q, %v", test.domain, test.expected, test.err, base, err)
+		}
+	}
+
+	// the bootstrap registry is cached
+	if fetches != 1 {
+		t.Errorf("expected the bootstrap registry to be fetched once, got %d", fetches)
+	}
+
+	// rdapUrl replaces the bootstrap registry
+	override := &rdapClient{serverURL: "https://rdap.override.example", bootstrapURL: server.URL + "/dns.json", timeout: 5}
+	base, err := override.baseURL("example.zz")
+	if err != nil || base != "https://rdap.override.example" {
+		t.Errorf("expected the rdapUrl, got %q, %v", base, err)
+	}
+
+	if fetches != 1 {
+		t.Errorf("expected the bootstrap registry not to be fetched with rdapUrl, got %d fetches", fetches)
+	}
+}
+
+// newRDAPServer will serve the RDAP responses of the domains under /rdap/domain/<name>
+func newRDAPServer(t *testing.T, domains map[string]interface{}) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		name := strings.TrimPrefix(req.URL.Path, "/rdap/domain/")
+		if name == "unavailable.com" {
+			resp.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		domain, ok := domains[name]
+		if !ok {
+			http.NotFound(resp, req)
+			return
+		}
+
+		resp.Header().Set("Content-Type", "application/rdap+json")
+		json.NewEncoder(resp).Encode(domain)
+	}))
+
+	t.Cleanup(server.Close)
+	return server
+}
+
+// rdapDomainResponse will return the RDAP domain response of a registration
+func rdapDomainResponse(registrar string, expiration time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"objectClassName": "domain",
+		"events": []interface{}{
+			map[string]interface{}{"eventAction": "registration", "eventDate": "2001-02-03T04:05:06Z"},
+			map[string]interface{}{"eventAction": "last changed", "eventDate": "2024-02-03T04:05:06Z"},
+			map[string]interface{}{"eventAction": "expiration", "eventDate": expiration.UTC().Format(time.RFC3339)},
+		},
+		"entities": []interface{}{
+			map[string]interface{}{"roles": []interface{}{"registrant"}, "vcardArray": jCard("Registrant Name")},
+			map[string]interface{}{"roles": []interface{}{"technical", "registrar"}, "vcardArray": jCard(registrar)},
+		},
+	}
+}
+
+func TestRDAPClientLookup(t *testing.T) {
+	expiration := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	server := newRDAPServer(t, map[string]interface{}{
+		"example.com":  rdapDomainResponse("Example Registrar", expiration),
+		"noexpiry.com": map[string]interface{}{"objectClassName": "domain"},
+	})
+
+	client := &rdapClient{serverURL: server.URL + "/rdap/", timeout: 5}
+
+	result, err := client.lookup("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.registrar != "Example Registrar" || !result.expiration.Equal(expiration) {
+		t.Errorf("expected Example Registrar expiring on %s, got %q expiring on %s", expiration, result.registrar, result.expiration)
+	}
+
+	errors := map[string]string{
+		"missing.com":     "domain not found",
+		"noexpiry.com":    ErrorNoExpiration.Error(),
+		"unavailable.com": "RDAP status: 503",
+	}
+
+	for domain, expected := range errors {
+		_, err := client.lookup(domain)
+		if err == nil || err.Error() != expected {
+			t.Errorf("lookup(%s) expected error %q, got %v", domain, expected, err)
+		}
+	}
+}
+
+func TestDomainExpirySeverity(t *testing.T) {
+	// half a day is added, so the days left are not rounded down
+	expireIn := func(days int) time.Time {
+		return time.Now().Add(time.Duration(days)*24*time.Hour + 12*time.Hour)
+	}
+
+	server := newRDAPServer(t, map[string]interface{}{
+		"info.com":     rdapDomainResponse("Registrar A", expireIn(120)),
+		"warning.com":  rdapDomainResponse("Registrar A", expireIn(25)),
+		"danger.com":   rdapDomainResponse("Registrar A", expireIn(12)),
+		"critical.com": rdapDomainResponse("Registrar A", expireIn(2)),
+		"expired.com":  rdapDomainResponse("Registrar A", time.Now().Add(-48*time.Hour)),
+		"noname.com":   map[string]interface{}{"events": []interface{}{map[string]interface{}{"eventAction": "expiration", "eventDate": expireIn(120).UTC().Format(time.RFC3339)}}},
+	})
+
+	tests := []struct {
+		name    string
+		domains []interface{}
+		configs config.Config
+		status  string
+
+		// severities of each domain, by domain
+		severities map[string]string
+	}{
+		{
+			name:       "info",
+			domains:    []interface{}{"info.com", "noname.com"},
+			status:     "OK",
+			severities: map[string]string{"info.com": "Info", "noname.com": "Info"},
+		},
+		{
+			name:       "warning",
+			domains:    []interface{}{"info.com", "warning.com"},
+			status:     "DEGRADED",
+			severities: map[string]string{"info.com": "Info", "warning.com": "Warning"},
+		},
+		{
+			name:       "danger",
+			domains:    []interface{}{"warning.com", "danger.com"},
+			status:     "NOT_OK",
+			severities: map[string]string{"warning.com": "Warning", "danger.com": "Danger"},
+		},
+		{
+			name:       "critical and expired",
+			domains:    []interface{}{"Critical.COM.", "expired.com"},
+			status:     "NOT_OK",
+			severities: map[string]string{"critical.com": "Critical", "expired.com": "Critical"},
+		},
+		{
+			name:       "custom thresholds",
+			domains:    []interface{}{"danger.com", "critical.com"},
+			configs:    config.Config{"warningDays": 14.0, "dangerDays": 7.0, "criticalDays": 1.0},
+			status:     "NOT_OK",
+			severities: map[string]string{"danger.com": "Warning", "critical.com": "Danger"},
+		},
+		{
+			name:       "registry error is a warning",
+			domains:    []interface{}{"missing.com", "unavailable.com"},
+			status:     "DEGRADED",
+			severities: map[string]string{"missing.com": "Warning", "unavailable.com": "Warning"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			configs := config.Config{"domains": test.domains, "rdapUrl": server.URL + "/rdap"}
+			for key, value := range test.configs {
+				configs[key] = value
+			}
+
+			d := NewDomainExpiry(false, log.New(io.Discard, "", 0))
+			d.SetConfig(configs)
+			if err := d.Connect(); err != nil {
+				t.Fatal(err)
+			}
+
+			status := string(d.Ping())
+			if status != test.status {
+				t.Errorf("expected status %s, got %s:\n%s", test.status, status, d.GetMessage())
+			}
+
+			// a line is severity | domain | registrar or error | ...
+			severities := make(map[string]string)
+			for _, line := range strings.Split(strings.TrimSpace(d.GetMessage()), "\n") {
+				fields := strings.Split(line, " | ")
+				if len(fields) < 3 {
+					t.Fatalf("invalid line %q", line)
+				}
+
+				severities[fields[1]] = fields[0]
+			}
+
+			for domain, severity := range test.severities {
+				if severities[domain] != severity {
+					t.Errorf("expected %s to be %s, got %q:\n%s", domain, severity, severities[domain], d.GetMessage())
+				}
+			}
+		})
+	}
+}
+
+func TestDomainExpiryMessage(t *testing.T) {
+	expiration := time.Now().Add(40*24*time.Hour + 12*time.Hour)
+
+	server := newRDAPServer(t, map[string]interface{}{
+		"example.com": rdapDomainResponse("Example Registrar", expiration),
+		"noname.com":  map[string]interface{}{"events": []interface{}{map[string]interface{}{"eventAction": "expiration", "eventDate": expiration.UTC().Format(time.RFC3339)}}},
+	})
+
+	d := NewDomainExpiry(false, log.New(io.Discard, "", 0))
+	d.SetConfig(config.Config{"domains": []interface{}{"example.com", "noname.com", "missing.com"}, "rdapUrl": server.URL + "/rdap/"})
+	if err := d.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	d.Ping()
+
+	date := expiration.UTC().Truncate(time.Second).Format(time.RFC1123)
+	expected := "Info | example.com | Example Registrar | expire in 40 days | (" + date + ")\n" +
+		"Info | noname.com | unknown registrar | expire in 40 days | (" + date + ")\n" +
+		"Warning | missing.com | cannot read registration: domain not found\n"
+
+	if d.GetMessage() != expected {
+		t.Errorf("expected message:\n%s\ngot:\n%s", expected, d.GetMessage())
+	}
+
+	// the domains are required
+	d.SetConfig(config.Config{"rdapUrl": server.URL + "/rdap/"})
+	if status := string(d.Ping()); status != "NOT_OK" || d.GetMessage() != ErrorNoDomains.Error() {
+		t.Errorf("expected NOT_OK %q, got %s %q", ErrorNoDomains.Error(), status, d.GetMessage())
+	}
+}
@@ -0,0 +1,26 @@
+package domainexpiry
+
+import (
+	"log"
+
+	"github.com/telkomdev/tob"
+)
+
+func init() {
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.DomainExpiry,
+		Description: "domain registration expiry via RDAP",
+		Options: []tob.Option{
+			{Name: "domains", Type: "array", Required: true, Description: "domains to check"},
+			{Name: "rdapUrl", Type: "string", Description: "RDAP server used for every domain instead of the bootstrap registry"},
+			{Name: "bootstrapUrl", Type: "string", Description: "RDAP bootstrap registry, default IANA"},
+			{Name: "timeout", Type: "number", Description: "lookup timeout in seconds, default 10"},
+			{Name: "warningDays", Type: "number", Description: "days left before the service is degraded"},
+			{Name: "dangerDays", Type: "number", Description: "days left before the service is down"},
+			{Name: "criticalDays", Type: "number", Description: "days left before the service is down as critical"},
+		},
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewDomainExpiry(verbose, logger)
+		},
+	})
+}
@@ -0,0 +1,212 @@
+package domainexpiry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/telkomdev/tob/httpx"
+)
+
+const (
+	// DefaultBootstrapURL the IANA RDAP bootstrap registry of domain names
+	DefaultBootstrapURL = "https://data.iana.org/rdap/dns.json"
+
+	// bootstrapTTL how long the bootstrap registry is cached
+	bootstrapTTL = 24 * time.Hour
+)
+
+var (
+	// ErrorNoRDAPServer error type
+	ErrorNoRDAPServer = errors.New("error: no RDAP server found for the TLD")
+
+	// ErrorNoExpiration error type
+	ErrorNoExpiration = errors.New("error: RDAP response has no expiration event")
+)
+
+// registration represent the registration data of a domain
+type registration struct {
+	registrar  string
+	expiration time.Time
+}
+
+// rdapDomain represent the part of RDAP domain response used by tob
+type rdapDomain struct {
+	Events []struct {
+		EventAction string    `json:"eventAction"`
+		EventDate   time.Time `json:"eventDate"`
+	} `json:"events"`
+	Entities []struct {
+		Roles      []string      `json:"roles"`
+		VCardArray []interface{} `json:"vcardArray"`
+	} `json:"entities"`
+}
+
+// rdapClient will find the RDAP server of a domain and lookup its registration
+type rdapClient struct {
+	// serverURL when not empty, every domain is looked up on this server instead of the bootstrap registry
+	serverURL    string
+	bootstrapURL string
+	timeout      int
+
+	mutex     sync.Mutex
+	services  map[string]string
+	fetchedAt time.Time
+}
+
+// baseURL will return the RDAP server url of the domain
+func (c *rdapClient) baseURL(domain string) (string, error) {
+	if c.serverURL != "" {
+		return c.serverURL, nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.services == nil || time.Since(c.fetchedAt) > bootstrapTTL {
+		services, err := c.fetchBootstrap()
+		if err != nil {
+			return "", err
+		}
+
+		c.services = services
+		c.fetchedAt = time.Now()
+	}
+
+	// the longest matching label wins, eg: co.uk before uk
+	labels := strings.Split(domain, ".")
+	for i := 1; i < len(labels); i++ {
+		if base, ok := c.services[strings.Join(labels[i:], ".")]; ok {
+			return base, nil
+		}
+	}
+
+	return "", ErrorNoRDAPServer
+}
+
+// fetchBootstrap will read the bootstrap registry (RFC 9224) into TLD => RDAP server url
+func (c *rdapClient) fetchBootstrap() (map[string]string, error) {
+	var bootstrap struct {
+		Services [][][]string `json:"services"`
+	}
+
+	err := c.get(c.bootstrapURL, &bootstrap)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read RDAP bootstrap: %s", err.Error())
+	}
+
+	services := make(map[string]string)
+	for _, service := range bootstrap.Services {
+		if len(service) < 2 || len(service[1]) == 0 {
+			continue
+		}
+
+		// prefer https when the registry lists several urls
+		base := service[1][0]
+		for _, u := range service[1] {
+			if strings.HasPrefix(u, "https://") {
+				base = u
+				break
+			}
+		}
+
+		for _, tld := range service[0] {
+			services[strings.ToLower(tld)] = base
+		}
+	}
+
+	return services, nil
+}
+
+// lookup will return the registrar and expiration date of the domain
+func (c *rdapClient) lookup(domain string) (registration, error) {
+	var result registration
+
+	base, err := c.baseURL(domain)
+	if err != nil {
+		return result, err
+	}
+
+	var response rdapDomain
+	err = c.get(strings.TrimSuffix(base, "/")+"/domain/"+url.PathEscape(domain), &response)
+	if err != nil {
+		return result, err
+	}
+
+	for _, event := range response.Events {
+		if event.EventAction == "expiration" {
+			result.expiration = event.EventDate
+		}
+	}
+
+	for _, entity := range response.Entities {
+		for _, role := range entity.Roles {
+			if role == "registrar" {
+				result.registrar = vcardName(entity.VCardArray)
+			}
+		}
+	}
+
+	if result.expiration.IsZero() {
+		return result, ErrorNoExpiration
+	}
+
+	return result, nil
+}
+
+// vcardName will return the fn property of a jCard (RFC 7095), eg:
+// ["vcard", [["version", {}, "text", "4.0"], ["fn", {}, "text", "Example Registrar"]]]
+func vcardName(vcardArray []interface{}) string {
+	if len(vcardArray) < 2 {
+		return ""
+	}
+
+	properties, ok := vcardArray[1].([]interface{})
+	if !ok {
+		return ""
+	}
+
+	for _, propertyInterface := range properties {
+		property, ok := propertyInterface.([]interface{})
+		if !ok || len(property) < 4 {
+			continue
+		}
+
+		if name, _ := property[0].(string); name == "fn" {
+			value, _ := property[3].(string)
+			return value
+		}
+	}
+
+	return ""
+}
+
+// get will execute HTTP GET and decode the JSON reply
+func (c *rdapClient) get(u string, v interface{}) error {
+	resp, err := httpx.HTTPGet(u, map[string]string{"Accept": "application/rdap+json, application/json"}, c.timeout)
+	if err != nil {
+		return err
+	}
+
+	defer func() { resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == 404 {
+		return errors.New("domain not found")
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("RDAP status: %d", resp.StatusCode)
+	}
+
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,17 @@
+package dummy
+
+import (
+	"log"
+
+	"github.com/telkomdev/tob"
+)
+
+func init() {
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.Dummy,
+		Description: "randomly NOT_OK, useful to test notificators",
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewDummy(verbose, logger)
+		},
+	})
+}
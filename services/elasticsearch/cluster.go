@@ -0,0 +1,236 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/telkomdev/tob/util"
+)
+
+const (
+	// clusterHealthPath the cluster health API path
+	clusterHealthPath = "/_cluster/health"
+
+	// nodesStatsPath the nodes stats API path, only JVM and file system metrics are requested
+	nodesStatsPath = "/_nodes/stats/jvm,fs"
+)
+
+// clusterHealthThreshold represent clusterHealth field of the service config
+type clusterHealthThreshold struct {
+	yellowAsDegraded         bool
+	minNodes                 float64
+	warningUnassignedShards  float64
+	criticalUnassignedShards float64
+	warningRelocatingShards  float64
+	criticalRelocatingShards float64
+}
+
+// nodesStatsThreshold represent nodesStats field of the service config
+type nodesStatsThreshold struct {
+	warningHeapPercent  float64
+	criticalHeapPercent float64
+	warningDiskPercent  float64
+	criticalDiskPercent float64
+}
+
+// clusterHealth represent the part of _cluster/health reply used by tob,
+// Elasticsearch and OpenSearch share the same format
+type clusterHealth struct {
+	ClusterName      string  `json:"cluster_name"`
+	Status           string  `json:"status"`
+	NumberOfNodes    float64 `json:"number_of_nodes"`
+	UnassignedShards float64 `json:"unassigned_shards"`
+	RelocatingShards float64 `json:"relocating_shards"`
+}
+
+// nodesStats represent the part of _nodes/stats reply used by tob
+type nodesStats struct {
+	Nodes map[string]struct {
+		Name string `json:"name"`
+		JVM  struct {
+			Mem struct {
+				HeapUsedPercent float64 `json:"heap_used_percent"`
+			} `json:"mem"`
+		} `json:"jvm"`
+		FS struct {
+			Total struct {
+				TotalInBytes     float64 `json:"total_in_bytes"`
+				AvailableInBytes float64 `json:"available_in_bytes"`
+			} `json:"total"`
+		} `json:"fs"`
+	} `json:"nodes"`
+}
+
+func parseClusterHealthThreshold(health map[string]interface{}) clusterHealthThreshold {
+	yellowAsDegraded, _ := health["yellowAsDegraded"].(bool)
+
+	return clusterHealthThreshold{
+		yellowAsDegraded:         yellowAsDegraded,
+		minNodes:                 util.InterfaceToFloat64(health["minNodes"]),
+		warningUnassignedShards:  util.InterfaceToFloat64(health["warningUnassignedShards"]),
+		criticalUnassignedShards: util.InterfaceToFloat64(health["criticalUnassignedShards"]),
+		warningRelocatingShards:  util.InterfaceToFloat64(health["warningRelocatingShards"]),
+		criticalRelocatingShards: util.InterfaceToFloat64(health["criticalRelocatingShards"]),
+	}
+}
+
+func parseNodesStatsThreshold(stats map[string]interface{}) nodesStatsThreshold {
+	return nodesStatsThreshold{
+		warningHeapPercent:  util.InterfaceToFloat64(stats["warningHeapPercent"]),
+		criticalHeapPercent: util.InterfaceToFloat64(stats["criticalHeapPercent"]),
+		warningDiskPercent:  util.InterfaceToFloat64(stats["warningDiskPercent"]),
+		criticalDiskPercent: util.InterfaceToFloat64(stats["criticalDiskPercent"]),
+	}
+}
+
+// apiURL will return the url of the API path, the service url may point to the cluster root
+// or to the cluster health API (eg: http://localhost:9200/_cluster/health?pretty)
+func apiURL(serviceURL, path string) (string, error) {
+	u, err := url.Parse(serviceURL)
+	if err != nil {
+		return "", err
+	}
+
+	// keep any path prefix of a reverse proxy in front of the cluster
+	if index := strings.Index(u.Path, clusterHealthPath); index >= 0 {
+		u.Path = u.Path[:index]
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	u.RawPath = ""
+	u.RawQuery = ""
+
+	return u.String(), nil
+}
+
+// get will execute HTTP GET to the API path and decode the JSON reply
+func (e *Elasticsearch) get(path string, v interface{}) error {
+	apiURLStr, err := apiURL(e.url, path)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("GET", apiURLStr, nil)
+	if err != nil {
+		return err
+	}
+
+	if e.authorization != "" {
+		req.Header.Set("Authorization", e.authorization)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() { resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("error: %s %s status: %d", e.Name(), path, resp.StatusCode)
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// checkClusterHealth checks the status, nodes and shards of the cluster
+func (e *Elasticsearch) checkClusterHealth(health clusterHealth, threshold clusterHealthThreshold) ([]string, []string) {
+	var (
+		criticals []string
+		warnings  []string
+	)
+
+	switch health.Status {
+	case "green":
+	case "yellow":
+		if threshold.yellowAsDegraded {
+			warnings = append(warnings, fmt.Sprintf("cluster %s status is yellow", health.ClusterName))
+		}
+	default:
+		criticals = append(criticals, fmt.Sprintf("cluster %s status is %s", health.ClusterName, health.Status))
+	}
+
+	if threshold.minNodes > 0 && health.NumberOfNodes < threshold.minNodes {
+		criticals = append(criticals, fmt.Sprintf("%.0f nodes in the cluster, expected at least %.0f",
+			health.NumberOfNodes, threshold.minNodes))
+	}
+
+	check := func(name string, value, warning, critical float64) {
+		if critical > 0 && value >= critical {
+			criticals = append(criticals, fmt.Sprintf("%.0f %s exceeds critical threshold %.0f", value, name, critical))
+		} else if warning > 0 && value >= warning {
+			warnings = append(warnings, fmt.Sprintf("%.0f %s exceeds warning threshold %.0f", value, name, warning))
+		}
+	}
+
+	check("unassigned shards", health.UnassignedShards, threshold.warningUnassignedShards, threshold.criticalUnassignedShards)
+	check("relocating shards", health.RelocatingShards, threshold.warningRelocatingShards, threshold.criticalRelocatingShards)
+
+	return criticals, warnings
+}
+
+// checkNodesStats checks JVM heap and disk usage of every node,
+// the disk thresholds are usually set below the cluster disk watermarks
+// so the alert comes before shards are moved away or indices are made read only
+func (e *Elasticsearch) checkNodesStats(threshold nodesStatsThreshold) ([]string, []string) {
+	var (
+		criticals []string
+		warnings  []string
+		stats     nodesStats
+	)
+
+	err := e.get(nodesStatsPath, &stats)
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read nodes stats: %s", err.Error())}, nil
+	}
+
+	check := func(node, name string, value, warning, critical float64) {
+		if critical > 0 && value >= critical {
+			criticals = append(criticals, fmt.Sprintf("node %s %s %.0f%% exceeds critical threshold %.0f%%", node, name, value, critical))
+		} else if warning > 0 && value >= warning {
+			warnings = append(warnings, fmt.Sprintf("node %s %s %.0f%% exceeds warning threshold %.0f%%", node, name, value, warning))
+		}
+	}
+
+	// sort the nodes so the message is stable between checks
+	ids := make([]string, 0, len(stats.Nodes))
+	for id := range stats.Nodes {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		node := stats.Nodes[id]
+		name := node.Name
+		if name == "" {
+			name = id
+		}
+
+		heapPercent := node.JVM.Mem.HeapUsedPercent
+
+		var diskPercent float64
+		if total := node.FS.Total.TotalInBytes; total > 0 {
+			diskPercent = (total - node.FS.Total.AvailableInBytes) / total * 100
+		}
+
+		if e.verbose {
+			e.logger.Printf("%s node %s heap: %.0f%%, disk: %.0f%%\n", e.Name(), name, heapPercent, diskPercent)
+		}
+
+		check(name, "JVM heap", heapPercent, threshold.warningHeapPercent, threshold.criticalHeapPercent)
+		check(name, "disk", diskPercent, threshold.warningDiskPercent, threshold.criticalDiskPercent)
+	}
+
+	return criticals, warnings
+}
@@ -1,11 +1,10 @@
 package elasticsearch
 
 import (
-	"encoding/json"
-	"fmt"
-	"io"
+	"errors"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/telkomdev/tob"
@@ -14,7 +13,21 @@ import (
 	"github.com/telkomdev/tob/util"
 )
 
-// Elasticsearch service
+const (
+	// DistributionElasticsearch Elasticsearch cluster
+	DistributionElasticsearch = "elasticsearch"
+
+	// DistributionOpenSearch OpenSearch cluster, OpenSearch shares the cluster health
+	// and nodes stats API of Elasticsearch but does not support API key authentication
+	DistributionOpenSearch = "opensearch"
+)
+
+var (
+	// ErrorAPIKeyNotSupported error type
+	ErrorAPIKeyNotSupported = errors.New("error: apiKey is not supported by OpenSearch, use username and password")
+)
+
+// Elasticsearch service, also used for OpenSearch
 type Elasticsearch struct {
 	url               string
 	recovered         bool
@@ -25,15 +38,29 @@ type Elasticsearch struct {
 	checkInterval     int
 	stopChan          chan bool
 	message           string
+	distribution      string
+	client            *http.Client
+	authorization     string
+	configs           config.Config
 	notificatorConfig config.Config
 }
 
-// Elasticsearch's constructor
+// NewElasticsearch Elasticsearch's constructor
 func NewElasticsearch(verbose bool, logger *log.Logger) *Elasticsearch {
+	return newCluster(DistributionElasticsearch, verbose, logger)
+}
+
+// NewOpenSearch OpenSearch's constructor
+func NewOpenSearch(verbose bool, logger *log.Logger) *Elasticsearch {
+	return newCluster(DistributionOpenSearch, verbose, logger)
+}
+
+func newCluster(distribution string, verbose bool, logger *log.Logger) *Elasticsearch {
 	stopChan := make(chan bool, 1)
 	return &Elasticsearch{
-		logger:  logger,
-		verbose: verbose,
+		logger:       logger,
+		verbose:      verbose,
+		distribution: distribution,
 
 		// by default service is recovered
 		recovered:     true,
@@ -44,78 +71,47 @@ func NewElasticsearch(verbose bool, logger *log.Logger) *Elasticsearch {
 
 // Name the name of the service
 func (e *Elasticsearch) Name() string {
-	return "elasticsearch"
+	return e.distribution
 }
 
-// checkClusterStatus checks the status of an Elasticsearch cluster by checking cluster index status.
-func (e *Elasticsearch) checkClusterStatus(resp *http.Response) (string, error) {
-	cStatus := ""
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		if e.verbose {
-			e.logger.Printf("cannot read response body: %v", err)
-		}
-
-		return cStatus, err
+// Ping will try to ping the service
+func (e *Elasticsearch) Ping() []byte {
+	if e.client == nil {
+		return []byte("NOT_OK")
 	}
-	defer func() { resp.Body.Close() }()
 
-	var data map[string]interface{}
-	err = json.Unmarshal(body, &data)
+	var health clusterHealth
+	err := e.get(clusterHealthPath, &health)
 	if err != nil {
+		e.SetMessage(err.Error())
 		if e.verbose {
-			e.logger.Printf("cannot parse JSON body: %v", err)
-		}
-
-		return cStatus, err
-	}
-
-	cStatus, ok := data["status"].(string)
-	if !ok {
-		if e.verbose {
-			e.logger.Printf("cannot read cluster status: %v", err)
-		}
-
-		return cStatus, err
-	}
-
-	if cStatus != "green" && cStatus != "yellow" {
-		if e.verbose {
-			e.logger.Println("elasticsearch cluster is unhealthy: ", cStatus)
+			e.logger.Printf("%s cluster health error: %v", e.Name(), err)
 		}
 
-		return cStatus, err
+		return []byte("NOT_OK")
 	}
 
-	return cStatus, nil
-}
-
-// Ping will try to ping the service
-func (e *Elasticsearch) Ping() []byte {
-	resp, err := httpx.HTTPGet(e.url, nil, 5)
-	if err != nil {
-		e.SetMessage(err.Error())
-		return []byte("NOT_OK")
+	if e.verbose {
+		e.logger.Printf("%s cluster status: %s", e.Name(), health.Status)
 	}
 
-	statusOK := resp.StatusCode >= 200 && resp.StatusCode < 300
-	if !statusOK {
-		e.SetMessage(fmt.Sprintf("error: elasticsearch Ping status: %d", resp.StatusCode))
-		if e.verbose {
-			e.logger.Printf("elasticsearch Ping status: %d", resp.StatusCode)
-		}
+	healthThreshold, _ := e.configs["clusterHealth"].(map[string]interface{})
+	criticals, warnings := e.checkClusterHealth(health, parseClusterHealthThreshold(healthThreshold))
 
-		return []byte("NOT_OK")
+	if stats, ok := e.configs["nodesStats"].(map[string]interface{}); ok {
+		c, w := e.checkNodesStats(parseNodesStatsThreshold(stats))
+		criticals = append(criticals, c...)
+		warnings = append(warnings, w...)
 	}
 
-	cStatus, err := e.checkClusterStatus(resp)
-	if err != nil {
-		e.SetMessage(err.Error())
+	if len(criticals) > 0 {
+		e.SetMessage(strings.Join(append(criticals, warnings...), "\n"))
 		return []byte("NOT_OK")
 	}
 
-	if e.verbose {
-		e.logger.Printf("elasticsearch cluster status: %s", cStatus)
+	if len(warnings) > 0 {
+		e.SetMessage(strings.Join(warnings, "\n"))
+		return []byte("DEGRADED")
 	}
 
 	return []byte("OK")
@@ -129,16 +125,46 @@ func (e *Elasticsearch) SetURL(url string) {
 // Connect to service if needed
 func (e *Elasticsearch) Connect() error {
 	if e.verbose {
-		e.logger.Println("connecting to Elasticsearch server")
+		e.logger.Printf("connecting to %s server", e.Name())
+	}
+
+	caFile, _ := e.configs["caFile"].(string)
+	certFile, _ := e.configs["certFile"].(string)
+	keyFile, _ := e.configs["keyFile"].(string)
+	tlsSkipVerify, _ := e.configs["tlsSkipVerify"].(bool)
+
+	tlsConfig, err := httpx.TLSConfig(caFile, certFile, keyFile, tlsSkipVerify)
+	if err != nil {
+		return err
 	}
 
+	// credentials in the url are used when neither apiKey nor username is set
+	username, _ := e.configs["username"].(string)
+	password, _ := e.configs["password"].(string)
+	apiKey, _ := e.configs["apiKey"].(string)
+
+	switch {
+	case apiKey != "" && e.distribution == DistributionOpenSearch:
+		return ErrorAPIKeyNotSupported
+	case apiKey != "":
+		e.authorization = "ApiKey " + apiKey
+	case username != "":
+		e.authorization = httpx.BasicAuth(username, password)
+	}
+
+	e.client = httpx.NewClient(5, tlsConfig)
+
 	return nil
 }
 
 // Close will close the service resources if needed
 func (e *Elasticsearch) Close() error {
 	if e.verbose {
-		e.logger.Println("close Elasticsearch")
+		e.logger.Printf("close %s", e.Name())
+	}
+
+	if e.client != nil {
+		e.client.CloseIdleConnections()
 	}
 
 	return nil
@@ -198,7 +224,7 @@ func (e *Elasticsearch) GetMessage() string {
 
 // SetConfig will set config
 func (e *Elasticsearch) SetConfig(configs config.Config) {
-
+	e.configs = configs
 }
 
 // SetNotificatorConfig will set config
@@ -0,0 +1,47 @@
+package elasticsearch
+
+import (
+	"log"
+
+	"github.com/telkomdev/tob"
+)
+
+func init() {
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.Elasticsearch,
+		Description: "Elasticsearch cluster health and node stats",
+		Options: []tob.Option{
+			{Name: "username", Type: "string", Description: "basic auth username"},
+			{Name: "password", Type: "string", Description: "basic auth password"},
+			{Name: "apiKey", Type: "string", Description: "API key, not supported by OpenSearch"},
+			{Name: "caFile", Type: "string", Description: "CA bundle used to verify the server"},
+			{Name: "certFile", Type: "string", Description: "client certificate"},
+			{Name: "keyFile", Type: "string", Description: "client certificate key"},
+			{Name: "tlsSkipVerify", Type: "bool", Description: "skip TLS certificate verification"},
+			{Name: "clusterHealth", Type: "object", Description: "cluster health thresholds: yellowAsDegraded, minNodes, unassigned and relocating shards"},
+			{Name: "nodesStats", Type: "object", Description: "node thresholds: heap and disk percent"},
+		},
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewElasticsearch(verbose, logger)
+		},
+	})
+
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.OpenSearch,
+		Description: "OpenSearch cluster health and node stats",
+		Options: []tob.Option{
+			{Name: "username", Type: "string", Description: "basic auth username"},
+			{Name: "password", Type: "string", Description: "basic auth password"},
+			{Name: "apiKey", Type: "string", Description: "API key, not supported by OpenSearch"},
+			{Name: "caFile", Type: "string", Description: "CA bundle used to verify the server"},
+			{Name: "certFile", Type: "string", Description: "client certificate"},
+			{Name: "keyFile", Type: "string", Description: "client certificate key"},
+			{Name: "tlsSkipVerify", Type: "bool", Description: "skip TLS certificate verification"},
+			{Name: "clusterHealth", Type: "object", Description: "cluster health thresholds: yellowAsDegraded, minNodes, unassigned and relocating shards"},
+			{Name: "nodesStats", Type: "object", Description: "node thresholds: heap and disk percent"},
+		},
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewOpenSearch(verbose, logger)
+		},
+	})
+}
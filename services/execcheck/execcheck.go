@@ -0,0 +1,364 @@
+package execcheck
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/telkomdev/tob"
+	"github.com/telkomdev/tob/config"
+	"github.com/telkomdev/tob/util"
+)
+
+const (
+	// StateOK Nagios OK exit code
+	StateOK = 0
+
+	// StateWarning Nagios WARNING exit code
+	StateWarning = 1
+
+	// StateCritical Nagios CRITICAL exit code
+	StateCritical = 2
+
+	// StateUnknown Nagios UNKNOWN exit code
+	StateUnknown = 3
+
+	// DefaultTimeout default command timeout in seconds
+	DefaultTimeout = 30
+)
+
+var (
+	// ErrorNoCommand error type
+	ErrorNoCommand = errors.New("error: command is not in the exec config")
+)
+
+// Exec service, runs a command and interprets its Nagios plugin exit code
+type Exec struct {
+	url               string
+	recovered         bool
+	lastDownTime      string
+	enabled           bool
+	verbose           bool
+	logger            *log.Logger
+	checkInterval     int
+	stopChan          chan bool
+	message           string
+	command           string
+	args              []string
+	env               []string
+	dir               string
+	timeout           time.Duration
+	parsePerfdata     bool
+	configs           config.Config
+	notificatorConfig config.Config
+}
+
+// NewExec Exec's constructor
+func NewExec(verbose bool, logger *log.Logger) *Exec {
+	stopChan := make(chan bool, 1)
+	return &Exec{
+		logger:  logger,
+		verbose: verbose,
+
+		// by default service is recovered
+		recovered:     true,
+		checkInterval: 0,
+		stopChan:      stopChan,
+	}
+}
+
+// Name the name of the service
+func (d *Exec) Name() string {
+	return "exec"
+}
+
+// Ping will try to ping the service
+func (d *Exec) Ping() []byte {
+	if d.command == "" {
+		d.SetMessage(ErrorNoCommand.Error())
+		return []byte("NOT_OK")
+	}
+
+	stdout, stderr, timedOut, err := d.run()
+	if timedOut {
+		d.SetMessage(fmt.Sprintf("CRITICAL: %s timed out after %s", d.command, d.timeout))
+		return []byte("NOT_OK")
+	}
+
+	exitCode := StateOK
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			// the command cannot be started (eg: not found, not executable)
+			d.SetMessage(fmt.Sprintf("UNKNOWN: %s", err.Error()))
+			return []byte("NOT_OK")
+		}
+
+		exitCode = exitErr.ExitCode()
+	}
+
+	// the first line of stdout is the message, stderr is used when the plugin prints nothing
+	output := firstLine(stdout)
+	if output == "" {
+		output = firstLine(stderr)
+	}
+
+	text, perfdata := splitOutput(output)
+
+	var details []string
+	if d.parsePerfdata && perfdata != "" {
+		for _, item := range parsePerfdata(perfdata) {
+			details = append(details, item.String())
+		}
+	}
+
+	if d.verbose {
+		d.logger.Printf("exec %s exit code: %d, output: %s\n", d.command, exitCode, output)
+	}
+
+	var (
+		state  string
+		status string
+	)
+
+	switch exitCode {
+	case StateOK:
+		state, status = "OK", "OK"
+	case StateWarning:
+		state, status = "WARNING", "DEGRADED"
+	case StateCritical:
+		state, status = "CRITICAL", "NOT_OK"
+	case StateUnknown:
+		state, status = "UNKNOWN", "NOT_OK"
+	default:
+		state, status = fmt.Sprintf("UNKNOWN (exit code %d)", exitCode), "NOT_OK"
+	}
+
+	message := fmt.Sprintf("%s: %s", state, text)
+	if len(details) > 0 {
+		message = fmt.Sprintf("%s\n%s", message, strings.Join(details, "\n"))
+	}
+
+	d.SetMessage(message)
+
+	return []byte(status)
+}
+
+// run will run the command until it exits or times out, the output is read from pipes owned by tob
+// and closed when the check ends, so a process left running by the command cannot block the check
+func (d *Exec) run() (string, string, bool, error) {
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		return "", "", false, err
+	}
+
+	stderrReader, stderrWriter, err := os.Pipe()
+	if err != nil {
+		stdoutReader.Close()
+		stdoutWriter.Close()
+		return "", "", false, err
+	}
+
+	// closing the read side stops the readers, even when a process still holds the write side
+	defer func() {
+		stdoutReader.Close()
+		stderrReader.Close()
+	}()
+
+	cmd := exec.Command(d.command, d.args...)
+	cmd.Env = d.env
+	cmd.Dir = d.dir
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = stderrWriter
+	setProcessGroup(cmd)
+
+	err = cmd.Start()
+
+	// the command has its own copy of the write side
+	stdoutWriter.Close()
+	stderrWriter.Close()
+
+	if err != nil {
+		return "", "", false, err
+	}
+
+	var (
+		stdout, stderr outputBuffer
+		readers        sync.WaitGroup
+	)
+
+	readers.Add(2)
+	go func() {
+		defer readers.Done()
+		io.Copy(&stdout, stdoutReader)
+	}()
+
+	go func() {
+		defer readers.Done()
+		io.Copy(&stderr, stderrReader)
+	}()
+
+	readDone := make(chan struct{})
+	go func() {
+		readers.Wait()
+		close(readDone)
+	}()
+
+	waitDone := make(chan error, 1)
+	go func() { waitDone <- cmd.Wait() }()
+
+	timer := time.NewTimer(d.timeout)
+	defer timer.Stop()
+
+	select {
+	case err = <-waitDone:
+		select {
+		case <-readDone:
+		case <-time.After(outputWait):
+		}
+
+		return stdout.String(), stderr.String(), false, err
+	case <-timer.C:
+		killErr := killProcessGroup(cmd)
+		if killErr != nil && d.verbose {
+			d.logger.Printf("exec %s kill error: %s\n", d.command, killErr.Error())
+		}
+
+		// Wait returns once the command is killed, it does not wait for the output
+		<-waitDone
+
+		return stdout.String(), stderr.String(), true, nil
+	}
+}
+
+func firstLine(output string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(line)
+}
+
+// SetURL will set the service URL
+func (d *Exec) SetURL(url string) {
+	d.url = url
+}
+
+// Connect to service if needed
+func (d *Exec) Connect() error {
+	if d.verbose {
+		d.logger.Println("connect Exec")
+	}
+
+	// Ping reports the missing command
+	d.command, _ = d.configs["command"].(string)
+
+	d.args = d.args[:0]
+	args, _ := d.configs["args"].([]interface{})
+	for _, arg := range args {
+		d.args = append(d.args, fmt.Sprintf("%v", arg))
+	}
+
+	// the command inherits tob environment, env adds or overrides variables
+	d.env = os.Environ()
+	env, _ := d.configs["env"].(map[string]interface{})
+	for key, value := range env {
+		d.env = append(d.env, fmt.Sprintf("%s=%v", key, value))
+	}
+
+	d.dir, _ = d.configs["dir"].(string)
+
+	timeout := int(util.InterfaceToFloat64(d.configs["timeout"]))
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	d.timeout = time.Duration(timeout) * time.Second
+	d.parsePerfdata, _ = d.configs["parsePerfdata"].(bool)
+
+	return nil
+}
+
+// Close will close the service resources if needed
+func (d *Exec) Close() error {
+	if d.verbose {
+		d.logger.Println("close Exec")
+	}
+
+	return nil
+}
+
+// SetRecover will set recovered status
+func (d *Exec) SetRecover(recovered bool) {
+	d.recovered = recovered
+}
+
+// IsRecover will return recovered status
+func (d *Exec) IsRecover() bool {
+	return d.recovered
+}
+
+// LastDownTime will set last down time of service to current time
+func (d *Exec) SetLastDownTimeNow() {
+	if d.recovered {
+		d.lastDownTime = time.Now().Format(util.YYMMDD)
+	}
+}
+
+// GetDownTimeDiff will return down time service difference in minutes
+func (d *Exec) GetDownTimeDiff() string {
+	return util.TimeDifference(d.lastDownTime, time.Now().Format(util.YYMMDD))
+}
+
+// SetCheckInterval will set check interval to service
+func (d *Exec) SetCheckInterval(interval int) {
+	d.checkInterval = interval
+}
+
+// GetCheckInterval will return check interval to service
+func (d *Exec) GetCheckInterval() int {
+	return d.checkInterval
+}
+
+// Enable will set enabled status to service
+func (d *Exec) Enable(enabled bool) {
+	d.enabled = enabled
+}
+
+// IsEnabled will return enable status
+func (d *Exec) IsEnabled() bool {
+	return d.enabled
+}
+
+// SetMessage will set additional message
+func (d *Exec) SetMessage(message string) {
+	d.message = message
+}
+
+// GetMessage will return additional message
+func (d *Exec) GetMessage() string {
+	return d.message
+}
+
+// SetConfig will set config
+func (d *Exec) SetConfig(configs config.Config) {
+	d.configs = configs
+}
+
+// SetNotificatorConfig will set config
+func (d *Exec) SetNotificatorConfig(configs config.Config) {
+	d.notificatorConfig = configs
+}
+
+// GetNotificators will return notificators
+func (d *Exec) GetNotificators() []tob.Notificator {
+	return tob.InitNotificatorFactory(d.notificatorConfig, d.verbose)
+}
+
+// Stop will receive stop channel
+func (d *Exec) Stop() chan bool {
+	return d.stopChan
+}
@@ -0,0 +1,157 @@
+package execcheck
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/telkomdev/tob/config"
+)
+
+func newExec(t *testing.T, configs config.Config) *Exec {
+	t.Helper()
+
+	e := NewExec(false, log.New(io.Discard, "", 0))
+	e.SetConfig(configs)
+
+	err := e.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return e
+}
+
+// shell will return the config running the script with sh
+func shell(t *testing.T, script string) config.Config {
+	t.Helper()
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	return config.Config{"command": "sh", "args": []interface{}{"-c", script}, "timeout": 1.0}
+}
+
+func TestExecExitCode(t *testing.T) {
+	tests := []struct {
+		script  string
+		status  string
+		message string
+	}{
+		{script: "echo all good", status: "OK", message: "OK: all good"},
+		{script: "echo 'disk 85%'; exit 1", status: "DEGRADED", message: "WARNING: disk 85%"},
+		{script: "echo 'disk 95%'; exit 2", status: "NOT_OK", message: "CRITICAL: disk 95%"},
+		{script: "echo 'no data'; exit 3", status: "NOT_OK", message: "UNKNOWN: no data"},
+		{script: "echo crashed; exit 42", status: "NOT_OK", message: "UNKNOWN (exit code 42): crashed"},
+		{script: "echo 'to stderr' >&2; exit 2", status: "NOT_OK", message: "CRITICAL: to stderr"},
+		{script: "echo 'first line'; echo 'second line'", status: "OK", message: "OK: first line"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.script, func(t *testing.T) {
+			e := newExec(t, shell(t, test.script))
+
+			status := string(e.Ping())
+			if status != test.status || e.GetMessage() != test.message {
+				t.Errorf("expected %s %q, got %s %q", test.status, test.message, status, e.GetMessage())
+			}
+		})
+	}
+}
+
+func TestExecPerfdata(t *testing.T) {
+	configs := shell(t, "echo 'DISK WARNING | /=85%;80;90'; exit 1")
+	configs["parsePerfdata"] = true
+
+	e := newExec(t, configs)
+
+	status := string(e.Ping())
+	expected := "WARNING: DISK WARNING\n/=85% (warning 80, critical 90)"
+	if status != "DEGRADED" || e.GetMessage() != expected {
+		t.Errorf("expected DEGRADED %q, got %s %q", expected, status, e.GetMessage())
+	}
+}
+
+func TestExecNoCommand(t *testing.T) {
+	e := newExec(t, config.Config{})
+	if status := string(e.Ping()); status != "NOT_OK" || e.GetMessage() != ErrorNoCommand.Error() {
+		t.Errorf("expected NOT_OK %q, got %s %q", ErrorNoCommand.Error(), status, e.GetMessage())
+	}
+
+	e = newExec(t, config.Config{"command": "/nonexistent/check"})
+	if status := string(e.Ping()); status != "NOT_OK" || !strings.HasPrefix(e.GetMessage(), "UNKNOWN: ") {
+		t.Errorf("expected NOT_OK UNKNOWN, got %s %q", status, e.GetMessage())
+	}
+}
+
+func TestExecOutputSize(t *testing.T) {
+	// the command writes more than maxOutputSize, it is never blocked by a full pipe
+	e := newExec(t, shell(t, fmt.Sprintf("head -c %d /dev/zero | tr '\\0' x; echo", 4*maxOutputSize)))
+
+	status := string(e.Ping())
+	if status != "OK" || len(e.GetMessage()) != len("OK: ")+maxOutputSize {
+		t.Errorf("expected OK with %d bytes of output, got %s with %d bytes", maxOutputSize, status, len(e.GetMessage()))
+	}
+}
+
+func TestExecTimeout(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		needs  string
+	}{
+		{name: "command", script: "sleep 10"},
+		{name: "background child", script: "sleep 10 & sleep 10"},
+		{name: "process outside the group", script: "setsid sleep 10 & sleep 10", needs: "setsid"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.needs != "" {
+				if _, err := exec.LookPath(test.needs); err != nil {
+					t.Skipf("%s is not available", test.needs)
+				}
+			}
+
+			e := newExec(t, shell(t, test.script))
+
+			start := time.Now()
+			status := string(e.Ping())
+			elapsed := time.Since(start)
+
+			if status != "NOT_OK" || !strings.Contains(e.GetMessage(), "timed out after 1s") {
+				t.Errorf("expected NOT_OK timed out, got %s %q", status, e.GetMessage())
+			}
+
+			if elapsed > 3*time.Second {
+				t.Errorf("expected the check to end after the timeout, it took %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestExecDaemonAfterExit(t *testing.T) {
+	if _, err := exec.LookPath("setsid"); err != nil {
+		t.Skip("setsid is not available")
+	}
+
+	// the command exits but leaves a process holding its output
+	e := newExec(t, shell(t, "echo started; setsid sleep 10 &"))
+
+	start := time.Now()
+	status := string(e.Ping())
+	elapsed := time.Since(start)
+
+	if status != "OK" || e.GetMessage() != "OK: started" {
+		t.Errorf("expected OK %q, got %s %q", "OK: started", status, e.GetMessage())
+	}
+
+	if elapsed > outputWait+time.Second {
+		t.Errorf("expected the check to end after outputWait, it took %s", elapsed)
+	}
+}
@@ -0,0 +1,25 @@
+package execcheck
+
+import (
+	"log"
+
+	"github.com/telkomdev/tob"
+)
+
+func init() {
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.Exec,
+		Description: "runs a Nagios style check command, the exit code is the status",
+		Options: []tob.Option{
+			{Name: "command", Type: "string", Required: true, Description: "command to run"},
+			{Name: "args", Type: "array", Description: "command arguments"},
+			{Name: "env", Type: "object", Description: "extra environment variables"},
+			{Name: "dir", Type: "string", Description: "working directory"},
+			{Name: "timeout", Type: "number", Description: "timeout in seconds, default 30"},
+			{Name: "parsePerfdata", Type: "bool", Description: "add the performance data to the message"},
+		},
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewExec(verbose, logger)
+		},
+	})
+}
@@ -0,0 +1,47 @@
+package execcheck
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+const (
+	// maxOutputSize the bytes kept from stdout and from stderr, the rest is discarded
+	maxOutputSize = 64 << 10
+
+	// outputWait how long the output is read after the command exits,
+	// a process left in the background by the command may keep the output open
+	outputWait = time.Second
+)
+
+// outputBuffer keeps the first maxOutputSize bytes written to it,
+// it is written by the reader of a pipe while the check reads it
+type outputBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+// Write will keep what fits in maxOutputSize, the whole p is always accepted so the command is never blocked
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if room := maxOutputSize - b.buffer.Len(); room > 0 {
+		if len(p) > room {
+			b.buffer.Write(p[:room])
+		} else {
+			b.buffer.Write(p)
+		}
+	}
+
+	return len(p), nil
+}
+
+// String will return the output kept
+func (b *outputBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.buffer.String()
+}
@@ -0,0 +1,122 @@
+package execcheck
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Perfdata represent a performance data item of Nagios plugin output,
+// 'label'=value[UOM];[warn];[crit];[min];[max]
+type Perfdata struct {
+	Label    string
+	Value    string
+	UOM      string
+	Warning  string
+	Critical string
+	Min      string
+	Max      string
+}
+
+// String will return the perfdata in a human readable form
+func (p Perfdata) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s=%s%s", p.Label, p.Value, p.UOM))
+
+	var thresholds []string
+	if p.Warning != "" {
+		thresholds = append(thresholds, "warning "+p.Warning)
+	}
+
+	if p.Critical != "" {
+		thresholds = append(thresholds, "critical "+p.Critical)
+	}
+
+	if len(thresholds) > 0 {
+		sb.WriteString(fmt.Sprintf(" (%s)", strings.Join(thresholds, ", ")))
+	}
+
+	return sb.String()
+}
+
+// splitOutput will split the first line of plugin output into the text and the perfdata
+func splitOutput(line string) (string, string) {
+	text, perfdata, _ := strings.Cut(line, "|")
+	return strings.TrimSpace(text), strings.TrimSpace(perfdata)
+}
+
+// parsePerfdata will parse space separated perfdata items, a quoted label may contain spaces
+func parsePerfdata(perfdata string) []Perfdata {
+	var (
+		items  []Perfdata
+		fields []string
+		field  strings.Builder
+		quoted bool
+	)
+
+	for _, r := range perfdata {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+			field.WriteRune(r)
+		case r == ' ' && !quoted:
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		default:
+			field.WriteRune(r)
+		}
+	}
+
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+
+	for _, f := range fields {
+		label, data, ok := strings.Cut(f, "=")
+		if !ok {
+			continue
+		}
+
+		values := strings.Split(data, ";")
+		value, uom := splitUOM(values[0])
+
+		item := Perfdata{
+			Label: strings.Trim(label, "'"),
+			Value: value,
+			UOM:   uom,
+		}
+
+		for i, v := range values[1:] {
+			switch i {
+			case 0:
+				item.Warning = v
+			case 1:
+				item.Critical = v
+			case 2:
+				item.Min = v
+			case 3:
+				item.Max = v
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// splitUOM will split the numeric value and its unit of measurement, eg: 95.5% => 95.5, %
+func splitUOM(value string) (string, string) {
+	// U means the value could not be determined
+	if value == "U" {
+		return value, ""
+	}
+
+	i := len(value)
+	for i > 0 && !strings.ContainsRune("0123456789.-", rune(value[i-1])) {
+		i--
+	}
+
+	return value[:i], value[i:]
+}
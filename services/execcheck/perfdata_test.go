@@ -0,0 +1,81 @@
+package execcheck
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitOutput(t *testing.T) {
+	tests := []struct {
+		line     string
+		text     string
+		perfdata string
+	}{
+		{line: "DISK OK - free space: / 3326 MB", text: "DISK OK - free space: / 3326 MB"},
+		{line: "DISK OK | /=2643MB;5948;5958;0;5968", text: "DISK OK", perfdata: "/=2643MB;5948;5958;0;5968"},
+		{line: "| time=0.1s", text: "", perfdata: "time=0.1s"},
+		{line: "", text: ""},
+	}
+
+	for _, test := range tests {
+		text, perfdata := splitOutput(test.line)
+		if text != test.text || perfdata != test.perfdata {
+			t.Errorf("splitOutput(%q) expected %q, %q, got %q, %q", test.line, test.text, test.perfdata, text, perfdata)
+		}
+	}
+}
+
+func TestParsePerfdata(t *testing.T) {
+	tests := []struct {
+		name     string
+		perfdata string
+		expected []Perfdata
+	}{
+		{
+			name:     "all fields",
+			perfdata: "/=2643MB;5948;5958;0;5968",
+			expected: []Perfdata{{Label: "/", Value: "2643", UOM: "MB", Warning: "5948", Critical: "5958", Min: "0", Max: "5968"}},
+		},
+		{
+			name:     "several items",
+			perfdata: "time=0.012s;;;0.000000 size=314B;;;0",
+			expected: []Perfdata{
+				{Label: "time", Value: "0.012", UOM: "s", Min: "0.000000"},
+				{Label: "size", Value: "314", UOM: "B", Min: "0"},
+			},
+		},
+		{
+			name:     "quoted label with spaces",
+			perfdata: "'used memory'=95.5%;80;90",
+			expected: []Perfdata{{Label: "used memory", Value: "95.5", UOM: "%", Warning: "80", Critical: "90"}},
+		},
+		{
+			name:     "unknown value and negative value",
+			perfdata: "load=U temperature=-5C",
+			expected: []Perfdata{{Label: "load", Value: "U"}, {Label: "temperature", Value: "-5", UOM: "C"}},
+		},
+		{
+			name:     "invalid item",
+			perfdata: "nolabel  users=3",
+			expected: []Perfdata{{Label: "users", Value: "3"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items := parsePerfdata(test.perfdata)
+			if !reflect.DeepEqual(items, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, items)
+			}
+		})
+	}
+}
+
+func TestPerfdataString(t *testing.T) {
+	item := Perfdata{Label: "used", Value: "95.5", UOM: "%", Warning: "80", Critical: "90"}
+
+	expected := "used=95.5% (warning 80, critical 90)"
+	if item.String() != expected {
+		t.Errorf("expected %q, got %q", expected, item.String())
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !linux && !darwin && !freebsd
+
+package execcheck
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup process groups are not supported, only the command is killed
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup will kill the command
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
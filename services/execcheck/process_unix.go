@@ -0,0 +1,19 @@
+//go:build linux || darwin || freebsd
+
+package execcheck
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup will start the command in its own process group,
+// so the processes started by the command are killed with it
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup will kill the command and every process of its group
+func killProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
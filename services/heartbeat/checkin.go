@@ -0,0 +1,98 @@
+package heartbeat
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/telkomdev/tob/config"
+)
+
+const (
+	// EventSuccess the job has finished, a ping without suffix is a success
+	EventSuccess = "success"
+
+	// EventStart the job has started
+	EventStart = "start"
+
+	// EventFail the job has failed
+	EventFail = "fail"
+
+	// maxCheckIns the check-ins kept for each ping key
+	maxCheckIns = 10
+
+	// maxExcerpt the body excerpt length of a check-in
+	maxExcerpt = 200
+)
+
+// CheckIn represent a ping received on the ping url of a heartbeat service
+type CheckIn struct {
+	Event      string
+	Excerpt    string
+	RemoteAddr string
+	ReceivedAt time.Time
+}
+
+var (
+	checkInsMutex sync.RWMutex
+
+	// checkIns the latest check-ins of each ping key, the latest is the last
+	checkIns = make(map[string][]CheckIn)
+)
+
+// Record will record a check-in of the ping key, event is EventSuccess, EventStart or EventFail
+func Record(pingKey, event, body, remoteAddr string) {
+	checkInsMutex.Lock()
+	defer checkInsMutex.Unlock()
+
+	body = strings.TrimSpace(body)
+	if len(body) > maxExcerpt {
+		body = body[:maxExcerpt] + "..."
+	}
+
+	history := append(checkIns[pingKey], CheckIn{
+		Event:      event,
+		Excerpt:    body,
+		RemoteAddr: remoteAddr,
+		ReceivedAt: time.Now(),
+	})
+
+	if len(history) > maxCheckIns {
+		history = history[len(history)-maxCheckIns:]
+	}
+
+	checkIns[pingKey] = history
+}
+
+// CheckIns will return the latest check-ins of the ping key, the latest is the last
+func CheckIns(pingKey string) []CheckIn {
+	checkInsMutex.RLock()
+	defer checkInsMutex.RUnlock()
+
+	return append([]CheckIn(nil), checkIns[pingKey]...)
+}
+
+// IsEvent will return true when event is a valid check-in event
+func IsEvent(event string) bool {
+	return event == EventSuccess || event == EventStart || event == EventFail
+}
+
+// Configured will return true when an enabled heartbeat service of the config has the ping key
+func Configured(configs config.Config, pingKey string) bool {
+	services, _ := configs["service"].(map[string]interface{})
+	for _, serviceInterface := range services {
+		conf, ok := serviceInterface.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		kind, _ := conf["kind"].(string)
+		enabled, _ := conf["enable"].(bool)
+		key, _ := conf["pingKey"].(string)
+		if kind == "heartbeat" && enabled && key != "" && key == pingKey {
+			return true
+		}
+	}
+
+	return false
+}
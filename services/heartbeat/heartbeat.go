@@ -0,0 +1,248 @@
+package heartbeat
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/telkomdev/tob"
+	"github.com/telkomdev/tob/config"
+	"github.com/telkomdev/tob/util"
+)
+
+const (
+	// DefaultGrace default grace period in seconds
+	DefaultGrace = 60
+
+	// PingPath the path of the ping urls on the tob HTTP server, eg: /api/heartbeat/<pingKey>/start
+	PingPath = "/api/heartbeat/"
+)
+
+var (
+	// ErrorNoPingKey error type
+	ErrorNoPingKey = errors.New("error: pingKey is required")
+
+	// ErrorNoPeriod error type
+	ErrorNoPeriod = errors.New("error: period is required")
+)
+
+// Heartbeat service, a dead man's switch. The job pings its url on the tob HTTP server,
+// the service is down when no ping arrives within period + grace
+type Heartbeat struct {
+	url               string
+	recovered         bool
+	lastDownTime      string
+	enabled           bool
+	verbose           bool
+	logger            *log.Logger
+	checkInterval     int
+	stopChan          chan bool
+	message           string
+	pingKey           string
+	period            time.Duration
+	grace             time.Duration
+	connectedAt       time.Time
+	configs           config.Config
+	notificatorConfig config.Config
+}
+
+// NewHeartbeat Heartbeat's constructor
+func NewHeartbeat(verbose bool, logger *log.Logger) *Heartbeat {
+	stopChan := make(chan bool, 1)
+	return &Heartbeat{
+		logger:  logger,
+		verbose: verbose,
+
+		// by default service is recovered
+		recovered:     true,
+		checkInterval: 0,
+		stopChan:      stopChan,
+	}
+}
+
+// Name the name of the service
+func (d *Heartbeat) Name() string {
+	return "heartbeat"
+}
+
+// Ping will check the latest check-ins
+func (d *Heartbeat) Ping() []byte {
+	now := time.Now()
+	history := CheckIns(d.pingKey)
+
+	if len(history) == 0 {
+		if now.Sub(d.connectedAt) > d.period+d.grace {
+			d.SetMessage(fmt.Sprintf("no ping since tob started %s ago", now.Sub(d.connectedAt).Round(time.Second)))
+			return []byte("NOT_OK")
+		}
+
+		d.SetMessage("waiting for the first ping")
+		return []byte("OK")
+	}
+
+	last := history[len(history)-1]
+
+	switch last.Event {
+	case EventFail:
+		d.SetMessage(fmt.Sprintf("job failed at %s%s", last.ReceivedAt.Format(time.RFC3339), excerpt(last)))
+		return []byte("NOT_OK")
+	case EventStart:
+		// a started job must finish within the grace period
+		if now.Sub(last.ReceivedAt) > d.grace {
+			d.SetMessage(fmt.Sprintf("job started at %s but has not finished after %s", last.ReceivedAt.Format(time.RFC3339), now.Sub(last.ReceivedAt).Round(time.Second)))
+			return []byte("NOT_OK")
+		}
+	}
+
+	lastSuccess, ok := latest(history, EventSuccess)
+	if !ok {
+		// the job is running for the first time
+		d.SetMessage(fmt.Sprintf("job started at %s", last.ReceivedAt.Format(time.RFC3339)))
+		return []byte("OK")
+	}
+
+	if age := now.Sub(lastSuccess.ReceivedAt); age > d.period+d.grace {
+		d.SetMessage(fmt.Sprintf("no ping since %s (%s ago), expected every %s", lastSuccess.ReceivedAt.Format(time.RFC3339), age.Round(time.Second), d.period))
+		return []byte("NOT_OK")
+	}
+
+	d.SetMessage(fmt.Sprintf("last ping at %s%s", lastSuccess.ReceivedAt.Format(time.RFC3339), excerpt(lastSuccess)))
+
+	return []byte("OK")
+}
+
+// latest will return the latest check-in of the event
+func latest(history []CheckIn, event string) (CheckIn, bool) {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Event == event {
+			return history[i], true
+		}
+	}
+
+	return CheckIn{}, false
+}
+
+func excerpt(checkIn CheckIn) string {
+	if checkIn.Excerpt == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(": %s", strings.ReplaceAll(checkIn.Excerpt, "\n", " "))
+}
+
+// SetURL will set the service URL, the url is not used, the ping url is PingPath + pingKey
+func (d *Heartbeat) SetURL(url string) {
+	d.url = url
+}
+
+// Connect to service if needed
+func (d *Heartbeat) Connect() error {
+	if d.verbose {
+		d.logger.Println("connect heartbeat")
+	}
+
+	pingKey, _ := d.configs["pingKey"].(string)
+	if pingKey == "" {
+		return ErrorNoPingKey
+	}
+
+	period := util.InterfaceToFloat64(d.configs["period"])
+	if period <= 0 {
+		return ErrorNoPeriod
+	}
+
+	grace := float64(DefaultGrace)
+	if _, ok := d.configs["grace"]; ok {
+		grace = util.InterfaceToFloat64(d.configs["grace"])
+	}
+
+	d.pingKey = pingKey
+	d.period = time.Duration(period * float64(time.Second))
+	d.grace = time.Duration(grace * float64(time.Second))
+	d.connectedAt = time.Now()
+
+	return nil
+}
+
+// Close will close the service resources if needed
+func (d *Heartbeat) Close() error {
+	if d.verbose {
+		d.logger.Println("close heartbeat")
+	}
+
+	return nil
+}
+
+// SetRecover will set recovered status
+func (d *Heartbeat) SetRecover(recovered bool) {
+	d.recovered = recovered
+}
+
+// IsRecover will return recovered status
+func (d *Heartbeat) IsRecover() bool {
+	return d.recovered
+}
+
+// LastDownTime will set last down time of service to current time
+func (d *Heartbeat) SetLastDownTimeNow() {
+	if d.recovered {
+		d.lastDownTime = time.Now().Format(util.YYMMDD)
+	}
+}
+
+// GetDownTimeDiff will return down time service difference in minutes
+func (d *Heartbeat) GetDownTimeDiff() string {
+	return util.TimeDifference(d.lastDownTime, time.Now().Format(util.YYMMDD))
+}
+
+// SetCheckInterval will set check interval to service
+func (d *Heartbeat) SetCheckInterval(interval int) {
+	d.checkInterval = interval
+}
+
+// GetCheckInterval will return check interval to service
+func (d *Heartbeat) GetCheckInterval() int {
+	return d.checkInterval
+}
+
+// Enable will set enabled status to service
+func (d *Heartbeat) Enable(enabled bool) {
+	d.enabled = enabled
+}
+
+// IsEnabled will return enable status
+func (d *Heartbeat) IsEnabled() bool {
+	return d.enabled
+}
+
+// SetMessage will set additional message
+func (d *Heartbeat) SetMessage(message string) {
+	d.message = message
+}
+
+// GetMessage will return additional message
+func (d *Heartbeat) GetMessage() string {
+	return d.message
+}
+
+// SetConfig will set config
+func (d *Heartbeat) SetConfig(configs config.Config) {
+	d.configs = configs
+}
+
+// SetNotificatorConfig will set config
+func (d *Heartbeat) SetNotificatorConfig(configs config.Config) {
+	d.notificatorConfig = configs
+}
+
+// GetNotificators will return notificators
+func (d *Heartbeat) GetNotificators() []tob.Notificator {
+	return tob.InitNotificatorFactory(d.notificatorConfig, d.verbose)
+}
+
+// Stop will receive stop channel
+func (d *Heartbeat) Stop() chan bool {
+	return d.stopChan
+}
@@ -0,0 +1,22 @@
+package heartbeat
+
+import (
+	"log"
+
+	"github.com/telkomdev/tob"
+)
+
+func init() {
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.Heartbeat,
+		Description: "dead man's switch, the job pings /api/heartbeat/<pingKey> on the tob HTTP server",
+		Options: []tob.Option{
+			{Name: "pingKey", Type: "string", Required: true, Description: "unique secret part of the ping url"},
+			{Name: "period", Type: "number", Required: true, Description: "expected seconds between two pings"},
+			{Name: "grace", Type: "number", Description: "extra seconds before a missing ping, or a job that has not finished, marks the service down, default 60"},
+		},
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewHeartbeat(verbose, logger)
+		},
+	})
+}
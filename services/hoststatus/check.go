@@ -0,0 +1,149 @@
+package hoststatus
+
+import (
+	"fmt"
+
+	"github.com/telkomdev/tob/config"
+	"github.com/telkomdev/tob/data"
+	"github.com/telkomdev/tob/util"
+)
+
+// hostThreshold represent the thresholds of the hoststatus service config, a zero threshold is not checked
+type hostThreshold struct {
+	warningLoadPerCPU             float64
+	criticalLoadPerCPU            float64
+	warningMemoryPercent          float64
+	criticalMemoryPercent         float64
+	warningSwapPercent            float64
+	criticalSwapPercent           float64
+	warningDiskPercent            float64
+	criticalDiskPercent           float64
+	warningInodePercent           float64
+	criticalInodePercent          float64
+	warningInterfaceErrors        float64
+	criticalInterfaceErrors       float64
+	warningFileDescriptorPercent  float64
+	criticalFileDescriptorPercent float64
+}
+
+func parseHostThreshold(configs config.Config) hostThreshold {
+	return hostThreshold{
+		warningLoadPerCPU:             util.InterfaceToFloat64(configs["warningLoadPerCPU"]),
+		criticalLoadPerCPU:            util.InterfaceToFloat64(configs["criticalLoadPerCPU"]),
+		warningMemoryPercent:          util.InterfaceToFloat64(configs["warningMemoryPercent"]),
+		criticalMemoryPercent:         util.InterfaceToFloat64(configs["criticalMemoryPercent"]),
+		warningSwapPercent:            util.InterfaceToFloat64(configs["warningSwapPercent"]),
+		criticalSwapPercent:           util.InterfaceToFloat64(configs["criticalSwapPercent"]),
+		warningDiskPercent:            util.InterfaceToFloat64(configs["warningDiskPercent"]),
+		criticalDiskPercent:           util.InterfaceToFloat64(configs["criticalDiskPercent"]),
+		warningInodePercent:           util.InterfaceToFloat64(configs["warningInodePercent"]),
+		criticalInodePercent:          util.InterfaceToFloat64(configs["criticalInodePercent"]),
+		warningInterfaceErrors:        util.InterfaceToFloat64(configs["warningInterfaceErrors"]),
+		criticalInterfaceErrors:       util.InterfaceToFloat64(configs["criticalInterfaceErrors"]),
+		warningFileDescriptorPercent:  util.InterfaceToFloat64(configs["warningFileDescriptorPercent"]),
+		criticalFileDescriptorPercent: util.InterfaceToFloat64(configs["criticalFileDescriptorPercent"]),
+	}
+}
+
+// compare will compare value with the thresholds
+func compare(name string, value, warning, critical float64, format string) ([]string, []string) {
+	if critical > 0 && value >= critical {
+		return []string{fmt.Sprintf("%s "+format+" exceeds critical threshold "+format, name, value, critical)}, nil
+	}
+
+	if warning > 0 && value >= warning {
+		return nil, []string{fmt.Sprintf("%s "+format+" exceeds warning threshold "+format, name, value, warning)}
+	}
+
+	return nil, nil
+}
+
+// checkMetrics will compare the host metrics with the thresholds,
+// interfaceErrors keeps the error counters of the previous check to alert on new errors only
+func checkMetrics(metrics data.HostMetrics, threshold hostThreshold, interfaceErrors map[string]uint64) ([]string, []string) {
+	var (
+		criticals []string
+		warnings  []string
+	)
+
+	add := func(c, w []string) {
+		criticals = append(criticals, c...)
+		warnings = append(warnings, w...)
+	}
+
+	// a metric the agent cannot read is not a failure of the host
+	for _, e := range metrics.Errors {
+		warnings = append(warnings, fmt.Sprintf("agent error %s", e))
+	}
+
+	if metrics.Load != nil && metrics.Load.CPUs > 0 {
+		loadPerCPU := metrics.Load.Load5 / float64(metrics.Load.CPUs)
+		add(compare("load5 per cpu", loadPerCPU, threshold.warningLoadPerCPU, threshold.criticalLoadPerCPU, "%.2f"))
+	}
+
+	if metrics.Memory != nil {
+		add(compare("memory used", metrics.Memory.UsedPercent, threshold.warningMemoryPercent, threshold.criticalMemoryPercent, "%.1f%%"))
+	}
+
+	if metrics.Swap != nil && metrics.Swap.Total > 0 {
+		add(compare("swap used", metrics.Swap.UsedPercent, threshold.warningSwapPercent, threshold.criticalSwapPercent, "%.1f%%"))
+	}
+
+	for _, mount := range metrics.Mounts {
+		add(compare(fmt.Sprintf("disk %s used", mount.Path), mount.UsedPercent, threshold.warningDiskPercent, threshold.criticalDiskPercent, "%.1f%%"))
+
+		if mount.Inodes > 0 {
+			add(compare(fmt.Sprintf("disk %s inodes used", mount.Path), mount.InodesUsedPercent, threshold.warningInodePercent, threshold.criticalInodePercent, "%.1f%%"))
+		}
+	}
+
+	for _, iface := range metrics.Interfaces {
+		total := iface.RxErrors + iface.RxDropped + iface.TxErrors + iface.TxDropped
+
+		// the first check and a counter reset (reboot) have no previous value
+		previous, ok := interfaceErrors[iface.Name]
+		interfaceErrors[iface.Name] = total
+		if !ok || total < previous {
+			continue
+		}
+
+		add(compare(fmt.Sprintf("interface %s new errors", iface.Name), float64(total-previous), threshold.warningInterfaceErrors, threshold.criticalInterfaceErrors, "%.0f"))
+	}
+
+	if metrics.FileDescriptors != nil {
+		add(compare("file descriptors used", metrics.FileDescriptors.UsedPercent, threshold.warningFileDescriptorPercent, threshold.criticalFileDescriptorPercent, "%.1f%%"))
+	}
+
+	for _, process := range metrics.Processes {
+		if process.Running == 0 {
+			criticals = append(criticals, fmt.Sprintf("process %s is not running", process.Name))
+		}
+	}
+
+	for _, unit := range metrics.Units {
+		if !unit.Active {
+			criticals = append(criticals, fmt.Sprintf("unit %s is not active", unit.Name))
+		}
+	}
+
+	return criticals, warnings
+}
+
+// summary will return the host metrics in a human readable form
+func summary(metrics data.HostMetrics) string {
+	s := fmt.Sprintf("host %s", metrics.Hostname)
+
+	if metrics.Load != nil {
+		s += fmt.Sprintf(", load %.2f %.2f %.2f (%d cpus)", metrics.Load.Load1, metrics.Load.Load5, metrics.Load.Load15, metrics.Load.CPUs)
+	}
+
+	if metrics.Memory != nil {
+		s += fmt.Sprintf(", memory %.1f%%", metrics.Memory.UsedPercent)
+	}
+
+	for _, mount := range metrics.Mounts {
+		s += fmt.Sprintf(", disk %s %.1f%%", mount.Path, mount.UsedPercent)
+	}
+
+	return s
+}
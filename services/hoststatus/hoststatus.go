@@ -0,0 +1,255 @@
+package hoststatus
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/telkomdev/tob"
+	"github.com/telkomdev/tob/agentclient"
+	"github.com/telkomdev/tob/config"
+	"github.com/telkomdev/tob/data"
+	"github.com/telkomdev/tob/util"
+)
+
+// HostStatus service, checks the host metrics reported by tob-http-agent
+type HostStatus struct {
+	url               string
+	recovered         bool
+	lastDownTime      string
+	enabled           bool
+	verbose           bool
+	logger            *log.Logger
+	checkInterval     int
+	stopChan          chan bool
+	message           string
+	agent             *agentclient.Client
+	request           data.HostMetricsRequest
+	threshold         hostThreshold
+	interfaceErrors   map[string]uint64
+	push              pushConfig
+	connectedAt       time.Time
+	configs           config.Config
+	notificatorConfig config.Config
+}
+
+type target struct {
+	Success bool             `json:"success"`
+	Message string           `json:"message"`
+	Data    data.HostMetrics `json:"data"`
+}
+
+// NewHostStatus HostStatus's constructor
+func NewHostStatus(verbose bool, logger *log.Logger) *HostStatus {
+	stopChan := make(chan bool, 1)
+	return &HostStatus{
+		logger:  logger,
+		verbose: verbose,
+
+		// by default service is recovered
+		recovered:       true,
+		checkInterval:   0,
+		stopChan:        stopChan,
+		interfaceErrors: make(map[string]uint64),
+	}
+}
+
+// Name the name of the service
+func (d *HostStatus) Name() string {
+	return "hoststatus"
+}
+
+// Ping will try to ping the service
+func (d *HostStatus) Ping() []byte {
+	var metrics data.HostMetrics
+
+	if d.push.enabled {
+		r, ok := lastReport(d.push.id)
+		if !ok {
+			if time.Since(d.connectedAt) > d.push.maxAge {
+				d.SetMessage(fmt.Sprintf("no report from agent %s since tob started %s ago", d.push.id, time.Since(d.connectedAt).Round(time.Second)))
+				return []byte("NOT_OK")
+			}
+
+			d.SetMessage(fmt.Sprintf("waiting for the first report of agent %s", d.push.id))
+			return []byte("OK")
+		}
+
+		if age := time.Since(r.collectedAt); age > d.push.maxAge {
+			d.SetMessage(fmt.Sprintf("no report from agent %s since %s (%s ago)", d.push.id, r.collectedAt.Format(time.RFC3339), age.Round(time.Second)))
+			return []byte("NOT_OK")
+		}
+
+		metrics = r.metrics
+	} else {
+		if d.agent == nil {
+			return []byte("NOT_OK")
+		}
+
+		var target target
+
+		err := d.agent.Post("/host-metrics", d.request, &target)
+		if err != nil {
+			d.SetMessage(err.Error())
+			if d.verbose {
+				d.logger.Println(err)
+			}
+
+			return []byte("NOT_OK")
+		}
+
+		metrics = target.Data
+	}
+
+	criticals, warnings := checkMetrics(metrics, d.threshold, d.interfaceErrors)
+
+	if len(criticals) > 0 {
+		d.SetMessage(strings.Join(append(append([]string{summary(metrics)}, criticals...), warnings...), "\n"))
+		return []byte("NOT_OK")
+	}
+
+	if len(warnings) > 0 {
+		d.SetMessage(strings.Join(append([]string{summary(metrics)}, warnings...), "\n"))
+		return []byte("DEGRADED")
+	}
+
+	d.SetMessage(summary(metrics))
+
+	return []byte("OK")
+}
+
+// SetURL will set the service URL
+func (d *HostStatus) SetURL(url string) {
+	d.url = url
+}
+
+// Connect to service if needed
+func (d *HostStatus) Connect() error {
+	if d.verbose {
+		d.logger.Println("connect HostStatus")
+	}
+
+	d.request = data.HostMetricsRequest{
+		Mounts:    stringList(d.configs["mounts"]),
+		Processes: stringList(d.configs["processes"]),
+		Units:     stringList(d.configs["units"]),
+	}
+
+	d.threshold = parseHostThreshold(d.configs)
+
+	push, err := parsePushConfig(d.configs)
+	if err != nil {
+		return err
+	}
+
+	d.push = push
+	d.connectedAt = time.Now()
+
+	// the agent pushes its reports to the tob HTTP server
+	if d.push.enabled {
+		return nil
+	}
+
+	agent, err := agentclient.NewClient(d.url, d.configs, 10)
+	if err != nil {
+		return err
+	}
+
+	d.agent = agent
+
+	return nil
+}
+
+func stringList(value interface{}) []string {
+	var list []string
+	values, _ := value.([]interface{})
+	for _, v := range values {
+		if s, ok := v.(string); ok && s != "" {
+			list = append(list, s)
+		}
+	}
+
+	return list
+}
+
+// Close will close the service resources if needed
+func (d *HostStatus) Close() error {
+	if d.verbose {
+		d.logger.Println("close HostStatus")
+	}
+
+	return nil
+}
+
+// SetRecover will set recovered status
+func (d *HostStatus) SetRecover(recovered bool) {
+	d.recovered = recovered
+}
+
+// IsRecover will return recovered status
+func (d *HostStatus) IsRecover() bool {
+	return d.recovered
+}
+
+// LastDownTime will set last down time of service to current time
+func (d *HostStatus) SetLastDownTimeNow() {
+	if d.recovered {
+		d.lastDownTime = time.Now().Format(util.YYMMDD)
+	}
+}
+
+// GetDownTimeDiff will return down time service difference in minutes
+func (d *HostStatus) GetDownTimeDiff() string {
+	return util.TimeDifference(d.lastDownTime, time.Now().Format(util.YYMMDD))
+}
+
+// SetCheckInterval will set check interval to service
+func (d *HostStatus) SetCheckInterval(interval int) {
+	d.checkInterval = interval
+}
+
+// GetCheckInterval will return check interval to service
+func (d *HostStatus) GetCheckInterval() int {
+	return d.checkInterval
+}
+
+// Enable will set enabled status to service
+func (d *HostStatus) Enable(enabled bool) {
+	d.enabled = enabled
+}
+
+// IsEnabled will return enable status
+func (d *HostStatus) IsEnabled() bool {
+	return d.enabled
+}
+
+// SetMessage will set additional message
+func (d *HostStatus) SetMessage(message string) {
+	d.message = message
+}
+
+// GetMessage will return additional message
+func (d *HostStatus) GetMessage() string {
+	return d.message
+}
+
+// SetConfig will set config
+func (d *HostStatus) SetConfig(configs config.Config) {
+	d.configs = configs
+}
+
+// SetNotificatorConfig will set config
+func (d *HostStatus) SetNotificatorConfig(configs config.Config) {
+	d.notificatorConfig = configs
+}
+
+// GetNotificators will return notificators
+func (d *HostStatus) GetNotificators() []tob.Notificator {
+	return tob.InitNotificatorFactory(d.notificatorConfig, d.verbose)
+}
+
+// Stop will receive stop channel
+func (d *HostStatus) Stop() chan bool {
+	return d.stopChan
+}
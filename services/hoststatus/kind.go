@@ -0,0 +1,46 @@
+package hoststatus
+
+import (
+	"log"
+
+	"github.com/telkomdev/tob"
+)
+
+func init() {
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.HostStatus,
+		Description: "host metrics reported by tob-http-agent",
+		Options: []tob.Option{
+			{Name: "push", Type: "bool", Description: "push mode, the agent posts its reports to tob instead of being polled"},
+			{Name: "pushId", Type: "string", Description: "push mode, the -push-id of the agent"},
+			{Name: "pushInterval", Type: "number", Description: "push mode, expected seconds between two reports, default 60"},
+			{Name: "pushGrace", Type: "number", Description: "push mode, extra seconds before a missing report marks the service down, default pushInterval"},
+			{Name: "mounts", Type: "array", Description: "mount paths to check, default every mount"},
+			{Name: "processes", Type: "array", Description: "process names that must be running"},
+			{Name: "units", Type: "array", Description: "systemd units that must be active"},
+			{Name: "token", Type: "string", Description: "bearer token of tob-http-agent"},
+			{Name: "hmacSecret", Type: "string", Description: "HMAC secret to sign the requests to tob-http-agent"},
+			{Name: "caFile", Type: "string", Description: "CA bundle used to verify tob-http-agent"},
+			{Name: "certFile", Type: "string", Description: "client certificate for mTLS"},
+			{Name: "keyFile", Type: "string", Description: "client certificate key for mTLS"},
+			{Name: "tlsSkipVerify", Type: "bool", Description: "skip TLS certificate verification"},
+			{Name: "warningLoadPerCPU", Type: "number", Description: "load5 per cpu before the service is degraded"},
+			{Name: "criticalLoadPerCPU", Type: "number", Description: "load5 per cpu before the service is down"},
+			{Name: "warningMemoryPercent", Type: "number", Description: "memory used percent before the service is degraded"},
+			{Name: "criticalMemoryPercent", Type: "number", Description: "memory used percent before the service is down"},
+			{Name: "warningSwapPercent", Type: "number", Description: "swap used percent before the service is degraded"},
+			{Name: "criticalSwapPercent", Type: "number", Description: "swap used percent before the service is down"},
+			{Name: "warningDiskPercent", Type: "number", Description: "disk used percent of a mount before the service is degraded"},
+			{Name: "criticalDiskPercent", Type: "number", Description: "disk used percent of a mount before the service is down"},
+			{Name: "warningInodePercent", Type: "number", Description: "inodes used percent of a mount before the service is degraded"},
+			{Name: "criticalInodePercent", Type: "number", Description: "inodes used percent of a mount before the service is down"},
+			{Name: "warningInterfaceErrors", Type: "number", Description: "new errors and drops of an interface since the previous check before the service is degraded"},
+			{Name: "criticalInterfaceErrors", Type: "number", Description: "new errors and drops of an interface since the previous check before the service is down"},
+			{Name: "warningFileDescriptorPercent", Type: "number", Description: "file descriptors used percent before the service is degraded"},
+			{Name: "criticalFileDescriptorPercent", Type: "number", Description: "file descriptors used percent before the service is down"},
+		},
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewHostStatus(verbose, logger)
+		},
+	})
+}
@@ -0,0 +1,140 @@
+package hoststatus
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/telkomdev/tob/config"
+	"github.com/telkomdev/tob/data"
+	"github.com/telkomdev/tob/httpx"
+	"github.com/telkomdev/tob/util"
+)
+
+const (
+	// DefaultPushInterval the expected seconds between two reports of a push mode agent
+	DefaultPushInterval = 60
+)
+
+var (
+	// ErrorNoPushID error type
+	ErrorNoPushID = errors.New("error: pushId is required in push mode")
+
+	// ErrorNoPushSecret error type
+	ErrorNoPushSecret = errors.New("error: hmacSecret is required in push mode")
+
+	// ErrorStaleReport error type
+	ErrorStaleReport = errors.New("error: report is not newer than the last report")
+
+	// ErrorFutureReport error type
+	ErrorFutureReport = errors.New("error: report is collected in the future, check the clock of the agent")
+)
+
+// report represent the latest metrics pushed by an agent
+type report struct {
+	metrics data.HostMetrics
+
+	// collectedAt the signed collection time of the metrics, the receive time can be replayed
+	collectedAt time.Time
+}
+
+var (
+	reportsMutex sync.RWMutex
+	reports      = make(map[string]report)
+)
+
+// Report will store the metrics pushed by the agent of pushID, the report is checked by the next Ping,
+// a report must be collected after the last report so a replayed report is rejected
+func Report(pushID string, metrics data.HostMetrics) error {
+	// a report from the future would look fresh and make the next reports stale until that time,
+	// the agent clock may be ahead as much as the signature allows
+	collectedAt := time.Unix(metrics.CollectedAt, 0)
+	if time.Until(collectedAt) > httpx.DefaultSignatureMaxAge {
+		return ErrorFutureReport
+	}
+
+	reportsMutex.Lock()
+	defer reportsMutex.Unlock()
+
+	if last, ok := reports[pushID]; ok && metrics.CollectedAt <= last.metrics.CollectedAt {
+		return ErrorStaleReport
+	}
+
+	reports[pushID] = report{metrics: metrics, collectedAt: collectedAt}
+
+	return nil
+}
+
+func lastReport(pushID string) (report, bool) {
+	reportsMutex.RLock()
+	defer reportsMutex.RUnlock()
+
+	r, ok := reports[pushID]
+	return r, ok
+}
+
+// PushSecret will return the hmacSecret of the enabled push mode hoststatus service of pushID
+func PushSecret(configs config.Config, pushID string) (string, bool) {
+	services, _ := configs["service"].(map[string]interface{})
+	for _, serviceInterface := range services {
+		conf, ok := serviceInterface.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		kind, _ := conf["kind"].(string)
+		enabled, _ := conf["enable"].(bool)
+		push, _ := conf["push"].(bool)
+		id, _ := conf["pushId"].(string)
+		if kind != "hoststatus" || !enabled || !push || id != pushID {
+			continue
+		}
+
+		secret, _ := conf["hmacSecret"].(string)
+		return secret, secret != ""
+	}
+
+	return "", false
+}
+
+// pushConfig represent the push mode of the hoststatus service config
+type pushConfig struct {
+	enabled bool
+	id      string
+
+	// maxAge is the push interval plus the grace period
+	maxAge time.Duration
+}
+
+func parsePushConfig(configs config.Config) (pushConfig, error) {
+	push, _ := configs["push"].(bool)
+	if !push {
+		return pushConfig{}, nil
+	}
+
+	id, _ := configs["pushId"].(string)
+	if id == "" {
+		return pushConfig{}, ErrorNoPushID
+	}
+
+	if secret, _ := configs["hmacSecret"].(string); secret == "" {
+		return pushConfig{}, ErrorNoPushSecret
+	}
+
+	interval := util.InterfaceToFloat64(configs["pushInterval"])
+	if interval <= 0 {
+		interval = DefaultPushInterval
+	}
+
+	// by default a single missed report is tolerated
+	grace := interval
+	if _, ok := configs["pushGrace"]; ok {
+		grace = util.InterfaceToFloat64(configs["pushGrace"])
+	}
+
+	return pushConfig{
+		enabled: true,
+		id:      id,
+		maxAge:  time.Duration((interval + grace) * float64(time.Second)),
+	}, nil
+}
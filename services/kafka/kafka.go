@@ -1,8 +1,11 @@
 package kafka
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
@@ -18,9 +21,18 @@ const (
 	ErrorClosedNetwork = "use of closed network connection"
 )
 
+// consumerGroupThreshold represent lag thresholds of a consumer group on a single topic
+type consumerGroupThreshold struct {
+	group       string
+	topic       string
+	warningLag  int64
+	criticalLag int64
+}
+
 // Kafka service
 type Kafka struct {
 	url               string
+	hosts             []string
 	brokerSize        int
 	recovered         bool
 	lastDownTime      string
@@ -28,9 +40,11 @@ type Kafka struct {
 	verbose           bool
 	logger            *log.Logger
 	client            *kf.Conn
+	transport         *kf.Transport
 	checkInterval     int
 	stopChan          chan bool
 	message           string
+	configs           config.Config
 	notificatorConfig config.Config
 }
 
@@ -67,14 +81,16 @@ func (d *Kafka) Ping() []byte {
 			d.logger.Println(err)
 		}
 
-		// re dial
-		if strings.Contains(err.Error(), ErrorClosedNetwork) {
+		// re dial, the connected broker may be gone so another listed broker is tried
+		if !strings.Contains(err.Error(), ErrorClosedNetwork) {
+			d.client.Close()
+		}
+
+		d.logger.Printf("Kafka: %s | do re dial\n", err.Error())
+		// re dial ignore error
+		err = d.dial()
+		if err != nil {
 			d.logger.Printf("Kafka: %s | do re dial\n", err.Error())
-			// re dial ignore error
-			err = d.dial()
-			if err != nil {
-				d.logger.Printf("Kafka: %s | do re dial\n", err.Error())
-			}
 		}
 		return []byte("NOT_OK")
 	}
@@ -90,12 +106,238 @@ func (d *Kafka) Ping() []byte {
 	// if the reply length is less than brokerSize,
 	// then there is an indication that the Kafka Cluster is experiencing problems
 	if len(reply) < d.brokerSize {
+		d.SetMessage(fmt.Sprintf("%d of %d brokers available", len(reply), d.brokerSize))
 		return []byte("NOT_OK")
 	}
 
+	var (
+		criticals []string
+		warnings  []string
+	)
+
+	if checkController, ok := d.configs["checkController"].(bool); ok && checkController {
+		c, w := d.checkController()
+		criticals = append(criticals, c...)
+		warnings = append(warnings, w...)
+	}
+
+	if checkPartitions, ok := d.configs["checkPartitions"].(bool); ok && checkPartitions {
+		c, w := d.checkPartitions()
+		criticals = append(criticals, c...)
+		warnings = append(warnings, w...)
+	}
+
+	for _, threshold := range d.consumerGroupThresholds() {
+		c, w := d.checkConsumerGroupLag(threshold)
+		criticals = append(criticals, c...)
+		warnings = append(warnings, w...)
+	}
+
+	if len(criticals) > 0 {
+		d.SetMessage(strings.Join(append(criticals, warnings...), "\n"))
+		return []byte("NOT_OK")
+	}
+
+	if len(warnings) > 0 {
+		d.SetMessage(strings.Join(warnings, "\n"))
+		return []byte("DEGRADED")
+	}
+
 	return []byte("OK")
 }
 
+// checkController will check if the Kafka Cluster has an active controller
+func (d *Kafka) checkController() ([]string, []string) {
+	controller, err := d.client.Controller()
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read controller: %s", err.Error())}, nil
+	}
+
+	if controller.Host == "" {
+		return []string{"no active controller"}, nil
+	}
+
+	if d.verbose {
+		d.logger.Printf("Kafka controller: %d (%s:%d)\n", controller.ID, controller.Host, controller.Port)
+	}
+
+	return nil, nil
+}
+
+// checkPartitions will check offline and under replicated partitions
+func (d *Kafka) checkPartitions() ([]string, []string) {
+	var topics []string
+	if topicsInterface, ok := d.configs["topics"].([]interface{}); ok {
+		for _, topicInterface := range topicsInterface {
+			if topic, ok := topicInterface.(string); ok {
+				topics = append(topics, topic)
+			}
+		}
+	}
+
+	partitions, err := d.client.ReadPartitions(topics...)
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read partitions: %s", err.Error())}, nil
+	}
+
+	var (
+		offline         []string
+		underReplicated []string
+	)
+
+	for _, partition := range partitions {
+		name := fmt.Sprintf("%s/%d", partition.Topic, partition.ID)
+
+		// a partition without leader cannot be read or written
+		if partition.Leader.Host == "" {
+			offline = append(offline, name)
+			continue
+		}
+
+		if len(partition.Isr) < len(partition.Replicas) {
+			underReplicated = append(underReplicated, name)
+		}
+	}
+
+	var (
+		criticals []string
+		warnings  []string
+	)
+
+	if len(offline) > 0 {
+		criticals = append(criticals, fmt.Sprintf("offline partitions: %s", strings.Join(offline, ", ")))
+	}
+
+	if len(underReplicated) > 0 {
+		warnings = append(warnings, fmt.Sprintf("under replicated partitions: %s", strings.Join(underReplicated, ", ")))
+	}
+
+	return criticals, warnings
+}
+
+// checkConsumerGroupLag will check lag of consumer group per partition of the topic
+func (d *Kafka) checkConsumerGroupLag(threshold consumerGroupThreshold) ([]string, []string) {
+	partitions, err := d.client.ReadPartitions(threshold.topic)
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read partitions of topic %s: %s", threshold.topic, err.Error())}, nil
+	}
+
+	partitionIDs := make([]int, 0, len(partitions))
+	lastOffsetRequests := make([]kf.OffsetRequest, 0, len(partitions))
+	for _, partition := range partitions {
+		partitionIDs = append(partitionIDs, partition.ID)
+		lastOffsetRequests = append(lastOffsetRequests, kf.LastOffsetOf(partition.ID))
+	}
+
+	sort.Ints(partitionIDs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer func() { cancel() }()
+
+	client := &kf.Client{
+		Addr:      kf.TCP(d.hosts...),
+		Timeout:   10 * time.Second,
+		Transport: d.transport,
+	}
+
+	committed, err := client.OffsetFetch(ctx, &kf.OffsetFetchRequest{
+		GroupID: threshold.group,
+		Topics:  map[string][]int{threshold.topic: partitionIDs},
+	})
+	if err == nil {
+		err = committed.Error
+	}
+
+	if err != nil {
+		return []string{fmt.Sprintf("cannot fetch offsets of consumer group %s: %s", threshold.group, err.Error())}, nil
+	}
+
+	last, err := client.ListOffsets(ctx, &kf.ListOffsetsRequest{
+		Topics: map[string][]kf.OffsetRequest{threshold.topic: lastOffsetRequests},
+	})
+	if err != nil {
+		return []string{fmt.Sprintf("cannot list offsets of topic %s: %s", threshold.topic, err.Error())}, nil
+	}
+
+	lastOffsets := make(map[int]int64)
+	for _, partition := range last.Topics[threshold.topic] {
+		lastOffsets[partition.Partition] = partition.LastOffset
+	}
+
+	var (
+		criticals []string
+		warnings  []string
+	)
+
+	for _, partition := range committed.Topics[threshold.topic] {
+		if partition.Error != nil {
+			criticals = append(criticals, fmt.Sprintf("consumer group %s on %s/%d: %s",
+				threshold.group, threshold.topic, partition.Partition, partition.Error.Error()))
+			continue
+		}
+
+		// the consumer group has not committed any offset on this partition yet,
+		// the lag cannot be measured since the retention may have removed the first messages
+		if partition.CommittedOffset < 0 {
+			warnings = append(warnings, fmt.Sprintf("consumer group %s has no committed offset on %s/%d",
+				threshold.group, threshold.topic, partition.Partition))
+			continue
+		}
+
+		lag := lastOffsets[partition.Partition] - partition.CommittedOffset
+		if lag < 0 {
+			lag = 0
+		}
+
+		if d.verbose {
+			d.logger.Printf("Kafka consumer group %s lag on %s/%d: %d\n",
+				threshold.group, threshold.topic, partition.Partition, lag)
+		}
+
+		if threshold.criticalLag > 0 && lag >= threshold.criticalLag {
+			criticals = append(criticals, fmt.Sprintf("consumer group %s lag on %s/%d is %d, exceeds critical threshold %d",
+				threshold.group, threshold.topic, partition.Partition, lag, threshold.criticalLag))
+		} else if threshold.warningLag > 0 && lag >= threshold.warningLag {
+			warnings = append(warnings, fmt.Sprintf("consumer group %s lag on %s/%d is %d, exceeds warning threshold %d",
+				threshold.group, threshold.topic, partition.Partition, lag, threshold.warningLag))
+		}
+	}
+
+	return criticals, warnings
+}
+
+// consumerGroupThresholds will parse consumerGroups field from the service config
+func (d *Kafka) consumerGroupThresholds() []consumerGroupThreshold {
+	var thresholds []consumerGroupThreshold
+
+	consumerGroups, ok := d.configs["consumerGroups"].([]interface{})
+	if !ok {
+		return thresholds
+	}
+
+	for _, consumerGroupInterface := range consumerGroups {
+		consumerGroup, ok := consumerGroupInterface.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		group, _ := consumerGroup["group"].(string)
+		topic, _ := consumerGroup["topic"].(string)
+		if group == "" || topic == "" {
+			continue
+		}
+
+		thresholds = append(thresholds, consumerGroupThreshold{
+			group:       group,
+			topic:       topic,
+			warningLag:  int64(util.InterfaceToFloat64(consumerGroup["warningLag"])),
+			criticalLag: int64(util.InterfaceToFloat64(consumerGroup["criticalLag"])),
+		})
+	}
+
+	return thresholds
+}
+
 // SetURL will set the service URL
 func (d *Kafka) SetURL(url string) {
 	d.url = url
@@ -131,25 +373,43 @@ func (d *Kafka) dial() error {
 		DualStack: true,
 	}
 
+	transport := &kf.Transport{
+		ClientID:    "tob",
+		DialTimeout: 10 * time.Second,
+	}
+
 	if username != "" && password != "" {
-		dialer.SASLMechanism = plain.Mechanism{
+		mechanism := plain.Mechanism{
 			Username: username,
 			Password: password,
 		}
+
+		dialer.SASLMechanism = mechanism
+		transport.SASL = mechanism
 	}
 
 	hostSplitted := strings.Split(hosts, ",")
-	host := ""
-	if len(hostSplitted) > 1 {
-		host = hostSplitted[0]
-	} else {
-		host = hosts
-	}
 
 	// set brokerSize with total length of Kafka host broker from config
 	d.brokerSize = len(hostSplitted)
+	d.hosts = hostSplitted
+
+	if d.transport != nil {
+		d.transport.CloseIdleConnections()
+	}
+	d.transport = transport
+
+	// dial the listed brokers one by one until one of them is reachable
+	var conn *kf.Conn
+	for _, host := range hostSplitted {
+		conn, err = dialer.Dial("tcp", host)
+		if err == nil {
+			break
+		}
+
+		d.logger.Printf("Kafka: cannot dial broker %s: %s\n", host, err.Error())
+	}
 
-	conn, err := dialer.Dial("tcp", host)
 	if err != nil {
 		return err
 	}
@@ -175,6 +435,10 @@ func (d *Kafka) Close() error {
 		d.logger.Println("closing Kafka connection")
 	}
 
+	if d.transport != nil {
+		d.transport.CloseIdleConnections()
+	}
+
 	if d.client != nil {
 		err := d.client.Close()
 		if err != nil {
@@ -243,7 +507,7 @@ func (d *Kafka) GetMessage() string {
 
 // SetConfig will set config
 func (d *Kafka) SetConfig(configs config.Config) {
-
+	d.configs = configs
 }
 
 // SetNotificatorConfig will set config
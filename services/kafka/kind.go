@@ -0,0 +1,23 @@
+package kafka
+
+import (
+	"log"
+
+	"github.com/telkomdev/tob"
+)
+
+func init() {
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.Kafka,
+		Description: "Kafka brokers, controller, partitions and consumer group lag",
+		Options: []tob.Option{
+			{Name: "checkController", Type: "bool", Description: "check an active controller is elected"},
+			{Name: "checkPartitions", Type: "bool", Description: "check offline and under replicated partitions"},
+			{Name: "topics", Type: "array", Description: "topics checked for partitions, default every topic"},
+			{Name: "consumerGroups", Type: "array", Description: "consumer group lag thresholds"},
+		},
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewKafka(verbose, logger)
+		},
+	})
+}
@@ -0,0 +1,21 @@
+package mongodb
+
+import (
+	"log"
+
+	"github.com/telkomdev/tob"
+)
+
+func init() {
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.MongoDB,
+		Description: "MongoDB ping, replica set and server status",
+		Options: []tob.Option{
+			{Name: "replicaSet", Type: "object", Description: "replica set checks: member health, replication lag and oplog window"},
+			{Name: "serverStatus", Type: "object", Description: "server status checks: connections percent"},
+		},
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewMongo(verbose, logger)
+		},
+	})
+}
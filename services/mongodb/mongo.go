@@ -3,6 +3,7 @@ package mongodb
 import (
 	"context"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/telkomdev/tob"
@@ -10,6 +11,7 @@ import (
 	"github.com/telkomdev/tob/util"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 // Mongo service
@@ -24,6 +26,7 @@ type Mongo struct {
 	checkInterval     int
 	stopChan          chan bool
 	message           string
+	configs           config.Config
 	notificatorConfig config.Config
 }
 
@@ -52,7 +55,16 @@ func (d *Mongo) Ping() []byte {
 		return []byte("NOT_OK")
 	}
 
-	if err := d.client.Ping(context.Background(), nil); err != nil {
+	replicaSet, hasReplicaSet := d.configs["replicaSet"].(map[string]interface{})
+
+	// by default ping selects the primary, when replica set is checked any member may answer
+	// so the missing primary is reported by checkReplicaSet instead of a server selection timeout
+	var rp *readpref.ReadPref
+	if hasReplicaSet {
+		rp = readpref.Nearest()
+	}
+
+	if err := d.client.Ping(context.Background(), rp); err != nil {
 		d.SetMessage(err.Error())
 		if d.verbose {
 			d.logger.Println("MongoDB error")
@@ -61,6 +73,36 @@ func (d *Mongo) Ping() []byte {
 		return []byte("NOT_OK")
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer func() { cancel() }()
+
+	var (
+		criticals []string
+		warnings  []string
+	)
+
+	if hasReplicaSet {
+		c, w := d.checkReplicaSet(ctx, parseReplicaSetThreshold(replicaSet))
+		criticals = append(criticals, c...)
+		warnings = append(warnings, w...)
+	}
+
+	if serverStatus, ok := d.configs["serverStatus"].(map[string]interface{}); ok {
+		c, w := d.checkServerStatus(ctx, parseServerStatusThreshold(serverStatus))
+		criticals = append(criticals, c...)
+		warnings = append(warnings, w...)
+	}
+
+	if len(criticals) > 0 {
+		d.SetMessage(strings.Join(append(criticals, warnings...), "\n"))
+		return []byte("NOT_OK")
+	}
+
+	if len(warnings) > 0 {
+		d.SetMessage(strings.Join(warnings, "\n"))
+		return []byte("DEGRADED")
+	}
+
 	return []byte("OK")
 }
 
@@ -175,7 +217,7 @@ func (d *Mongo) GetMessage() string {
 
 // SetConfig will set config
 func (d *Mongo) SetConfig(configs config.Config) {
-
+	d.configs = configs
 }
 
 // SetNotificatorConfig will set config
@@ -0,0 +1,222 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/telkomdev/tob/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// replicaSetThreshold represent replicaSet field of the service config
+type replicaSetThreshold struct {
+	warningLagSeconds        float64
+	criticalLagSeconds       float64
+	warningOplogWindowHours  float64
+	criticalOplogWindowHours float64
+}
+
+// serverStatusThreshold represent serverStatus field of the service config
+type serverStatusThreshold struct {
+	warningConnectionsPercent  float64
+	criticalConnectionsPercent float64
+}
+
+// replicaSetStatus represent the part of replSetGetStatus reply used by tob
+type replicaSetStatus struct {
+	Set     string `bson:"set"`
+	Members []struct {
+		Name       string    `bson:"name"`
+		Health     float64   `bson:"health"`
+		StateStr   string    `bson:"stateStr"`
+		OptimeDate time.Time `bson:"optimeDate"`
+	} `bson:"members"`
+}
+
+// serverStatus represent the part of serverStatus reply used by tob
+type serverStatus struct {
+	Connections struct {
+		Current   float64 `bson:"current"`
+		Available float64 `bson:"available"`
+	} `bson:"connections"`
+}
+
+// oplogEntry represent the timestamp of an oplog entry
+type oplogEntry struct {
+	Ts primitive.Timestamp `bson:"ts"`
+}
+
+func parseReplicaSetThreshold(replicaSet map[string]interface{}) replicaSetThreshold {
+	return replicaSetThreshold{
+		warningLagSeconds:        util.InterfaceToFloat64(replicaSet["warningLagSeconds"]),
+		criticalLagSeconds:       util.InterfaceToFloat64(replicaSet["criticalLagSeconds"]),
+		warningOplogWindowHours:  util.InterfaceToFloat64(replicaSet["warningOplogWindowHours"]),
+		criticalOplogWindowHours: util.InterfaceToFloat64(replicaSet["criticalOplogWindowHours"]),
+	}
+}
+
+func parseServerStatusThreshold(serverStatus map[string]interface{}) serverStatusThreshold {
+	return serverStatusThreshold{
+		warningConnectionsPercent:  util.InterfaceToFloat64(serverStatus["warningConnectionsPercent"]),
+		criticalConnectionsPercent: util.InterfaceToFloat64(serverStatus["criticalConnectionsPercent"]),
+	}
+}
+
+// checkReplicaSet will check primary presence, member states, replication lag and oplog window
+func (d *Mongo) checkReplicaSet(ctx context.Context, threshold replicaSetThreshold) ([]string, []string) {
+	var (
+		criticals []string
+		warnings  []string
+		status    replicaSetStatus
+	)
+
+	// any member can answer replSetGetStatus, so an election without primary is reported
+	err := d.client.Database("admin").RunCommand(ctx, bson.D{{Key: "replSetGetStatus", Value: 1}},
+		options.RunCmd().SetReadPreference(readpref.Nearest())).Decode(&status)
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read replica set status: %s", err.Error())}, nil
+	}
+
+	var (
+		primary       string
+		primaryOptime time.Time
+		latestOptime  time.Time
+	)
+
+	for _, member := range status.Members {
+		if member.StateStr == "PRIMARY" {
+			primary = member.Name
+			primaryOptime = member.OptimeDate
+		}
+
+		if member.OptimeDate.After(latestOptime) {
+			latestOptime = member.OptimeDate
+		}
+	}
+
+	if primary == "" {
+		criticals = append(criticals, fmt.Sprintf("replica set %s has no primary", status.Set))
+
+		// without primary the lag is measured against the most recent member
+		primaryOptime = latestOptime
+	}
+
+	if d.verbose {
+		d.logger.Printf("MongoDB replica set %s primary: %s\n", status.Set, primary)
+	}
+
+	for _, member := range status.Members {
+		if member.Health == 0 {
+			warnings = append(warnings, fmt.Sprintf("member %s is unreachable (%s)", member.Name, member.StateStr))
+			continue
+		}
+
+		switch member.StateStr {
+		case "PRIMARY", "ARBITER":
+			continue
+		case "SECONDARY":
+		default:
+			warnings = append(warnings, fmt.Sprintf("member %s is %s", member.Name, member.StateStr))
+			continue
+		}
+
+		lag := primaryOptime.Sub(member.OptimeDate).Seconds()
+		if lag < 0 {
+			lag = 0
+		}
+
+		if d.verbose {
+			d.logger.Printf("MongoDB member %s lag: %.0f seconds\n", member.Name, lag)
+		}
+
+		if threshold.criticalLagSeconds > 0 && lag >= threshold.criticalLagSeconds {
+			criticals = append(criticals, fmt.Sprintf("member %s lag %.0f seconds exceeds critical threshold %.0f seconds",
+				member.Name, lag, threshold.criticalLagSeconds))
+		} else if threshold.warningLagSeconds > 0 && lag >= threshold.warningLagSeconds {
+			warnings = append(warnings, fmt.Sprintf("member %s lag %.0f seconds exceeds warning threshold %.0f seconds",
+				member.Name, lag, threshold.warningLagSeconds))
+		}
+	}
+
+	if threshold.warningOplogWindowHours > 0 || threshold.criticalOplogWindowHours > 0 {
+		c, w := d.checkOplogWindow(ctx, threshold)
+		criticals = append(criticals, c...)
+		warnings = append(warnings, w...)
+	}
+
+	return criticals, warnings
+}
+
+// checkOplogWindow will check the time range covered by the oplog,
+// a secondary that falls behind more than this window needs a full resync
+func (d *Mongo) checkOplogWindow(ctx context.Context, threshold replicaSetThreshold) ([]string, []string) {
+	oplog := d.client.Database("local").Collection("oplog.rs")
+
+	var first, last oplogEntry
+
+	err := oplog.FindOne(ctx, bson.D{}, options.FindOne().SetSort(bson.D{{Key: "$natural", Value: 1}})).Decode(&first)
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read oplog: %s", err.Error())}, nil
+	}
+
+	err = oplog.FindOne(ctx, bson.D{}, options.FindOne().SetSort(bson.D{{Key: "$natural", Value: -1}})).Decode(&last)
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read oplog: %s", err.Error())}, nil
+	}
+
+	window := float64(last.Ts.T-first.Ts.T) / 3600
+
+	if d.verbose {
+		d.logger.Printf("MongoDB oplog window: %.1f hours\n", window)
+	}
+
+	if threshold.criticalOplogWindowHours > 0 && window <= threshold.criticalOplogWindowHours {
+		return []string{fmt.Sprintf("oplog window %.1f hours is below critical threshold %.1f hours",
+			window, threshold.criticalOplogWindowHours)}, nil
+	}
+
+	if threshold.warningOplogWindowHours > 0 && window <= threshold.warningOplogWindowHours {
+		return nil, []string{fmt.Sprintf("oplog window %.1f hours is below warning threshold %.1f hours",
+			window, threshold.warningOplogWindowHours)}
+	}
+
+	return nil, nil
+}
+
+// checkServerStatus will check connection usage of the server
+func (d *Mongo) checkServerStatus(ctx context.Context, threshold serverStatusThreshold) ([]string, []string) {
+	var status serverStatus
+
+	err := d.client.Database("admin").RunCommand(ctx, bson.D{{Key: "serverStatus", Value: 1}},
+		options.RunCmd().SetReadPreference(readpref.Nearest())).Decode(&status)
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read server status: %s", err.Error())}, nil
+	}
+
+	total := status.Connections.Current + status.Connections.Available
+	if total <= 0 {
+		return nil, nil
+	}
+
+	connectionsPercent := status.Connections.Current / total * 100
+
+	if d.verbose {
+		d.logger.Printf("MongoDB connections: %.0f of %.0f (%.0f%%)\n", status.Connections.Current, total, connectionsPercent)
+	}
+
+	if threshold.criticalConnectionsPercent > 0 && connectionsPercent >= threshold.criticalConnectionsPercent {
+		return []string{fmt.Sprintf("connections %.0f%% exceeds critical threshold %.0f%%",
+			connectionsPercent, threshold.criticalConnectionsPercent)}, nil
+	}
+
+	if threshold.warningConnectionsPercent > 0 && connectionsPercent >= threshold.warningConnectionsPercent {
+		return nil, []string{fmt.Sprintf("connections %.0f%% exceeds warning threshold %.0f%%",
+			connectionsPercent, threshold.warningConnectionsPercent)}
+	}
+
+	return nil, nil
+}
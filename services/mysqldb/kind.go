@@ -0,0 +1,20 @@
+package mysqldb
+
+import (
+	"log"
+
+	"github.com/telkomdev/tob"
+)
+
+func init() {
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.MySQL,
+		Description: "MySQL ping and replication",
+		Options: []tob.Option{
+			{Name: "replication", Type: "object", Description: "replication checks: role, minimum replicas and lag"},
+		},
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewMySQL(verbose, logger)
+		},
+	})
+}
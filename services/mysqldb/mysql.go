@@ -3,11 +3,13 @@ package mysqldb
 import (
 	"database/sql"
 	"log"
+	"strings"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/telkomdev/tob"
 	"github.com/telkomdev/tob/config"
+	"github.com/telkomdev/tob/sqlquery"
 	"github.com/telkomdev/tob/util"
 )
 
@@ -23,6 +25,7 @@ type MySQL struct {
 	checkInterval     int
 	stopChan          chan bool
 	message           string
+	configs           config.Config
 	notificatorConfig config.Config
 }
 
@@ -60,6 +63,34 @@ func (d *MySQL) Ping() []byte {
 		return []byte("NOT_OK")
 	}
 
+	var (
+		criticals []string
+		warnings  []string
+	)
+
+	if replication, ok := d.configs["replication"].(map[string]interface{}); ok {
+		c, w := d.checkReplication(parseReplicationConfig(replication))
+		criticals = append(criticals, c...)
+		warnings = append(warnings, w...)
+	}
+
+	queries := sqlquery.ParseQueries(d.configs)
+	if len(queries) > 0 {
+		c, w := sqlquery.NewRunner(d.db, "").Run(queries)
+		criticals = append(criticals, c...)
+		warnings = append(warnings, w...)
+	}
+
+	if len(criticals) > 0 {
+		d.SetMessage(strings.Join(append(criticals, warnings...), "\n"))
+		return []byte("NOT_OK")
+	}
+
+	if len(warnings) > 0 {
+		d.SetMessage(strings.Join(warnings, "\n"))
+		return []byte("DEGRADED")
+	}
+
 	return []byte("OK")
 }
 
@@ -163,7 +194,7 @@ func (d *MySQL) GetMessage() string {
 
 // SetConfig will set config
 func (d *MySQL) SetConfig(configs config.Config) {
-
+	d.configs = configs
 }
 
 // SetNotificatorConfig will set config
@@ -0,0 +1,189 @@
+package mysqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/telkomdev/tob/util"
+)
+
+// replicationConfig represent replication field of the service config
+type replicationConfig struct {
+	role               string
+	warningLagSeconds  float64
+	criticalLagSeconds float64
+	minReplicas        int
+}
+
+func parseReplicationConfig(replication map[string]interface{}) replicationConfig {
+	role, _ := replication["role"].(string)
+
+	return replicationConfig{
+		role:               role,
+		warningLagSeconds:  util.InterfaceToFloat64(replication["warningLagSeconds"]),
+		criticalLagSeconds: util.InterfaceToFloat64(replication["criticalLagSeconds"]),
+		minReplicas:        int(util.InterfaceToFloat64(replication["minReplicas"])),
+	}
+}
+
+// queryMaps will run the statement and return every row as column name => value,
+// the fallback statement is used when the statement fails (eg: older MySQL version)
+func (d *MySQL) queryMaps(ctx context.Context, statement, fallback string) ([]map[string]sql.NullString, error) {
+	rows, err := d.db.QueryContext(ctx, statement)
+	if err != nil {
+		if fallback == "" {
+			return nil, err
+		}
+
+		rows, err = d.db.QueryContext(ctx, fallback)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	defer func() { rows.Close() }()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []map[string]sql.NullString
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		err = rows.Scan(pointers...)
+		if err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]sql.NullString, len(columns))
+		for i, column := range columns {
+			row[column] = values[i]
+		}
+
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+// column will return the first available column, MySQL 8.0.22 renamed Slave/Master columns to Replica/Source
+func column(row map[string]sql.NullString, names ...string) sql.NullString {
+	for _, name := range names {
+		if value, ok := row[name]; ok {
+			return value
+		}
+	}
+
+	return sql.NullString{}
+}
+
+// checkReplication will check replication health of primary or replica server
+func (d *MySQL) checkReplication(replication replicationConfig) ([]string, []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer func() { cancel() }()
+
+	channels, err := d.queryMaps(ctx, "SHOW REPLICA STATUS", "SHOW SLAVE STATUS")
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read replica status: %s", err.Error())}, nil
+	}
+
+	role := "primary"
+	if len(channels) > 0 {
+		role = "replica"
+	}
+
+	if d.verbose {
+		d.logger.Printf("MySQL replication role: %s\n", role)
+	}
+
+	if replication.role != "" && replication.role != role {
+		return []string{fmt.Sprintf("expected %s but server is %s", replication.role, role)}, nil
+	}
+
+	if role == "primary" {
+		return d.checkPrimary(ctx, replication)
+	}
+
+	var (
+		criticals []string
+		warnings  []string
+	)
+
+	// multi source replication returns one row per channel
+	for _, channel := range channels {
+		name := "replication"
+		if channelName := column(channel, "Channel_Name").String; channelName != "" {
+			name = fmt.Sprintf("replication channel %s", channelName)
+		}
+
+		ioRunning := column(channel, "Replica_IO_Running", "Slave_IO_Running").String
+		sqlRunning := column(channel, "Replica_SQL_Running", "Slave_SQL_Running").String
+
+		if ioRunning != "Yes" {
+			criticals = append(criticals, fmt.Sprintf("%s IO thread is %s: %s",
+				name, ioRunning, column(channel, "Last_IO_Error").String))
+		}
+
+		if sqlRunning != "Yes" {
+			criticals = append(criticals, fmt.Sprintf("%s SQL thread is %s: %s",
+				name, sqlRunning, column(channel, "Last_SQL_Error").String))
+		}
+
+		if ioRunning != "Yes" || sqlRunning != "Yes" {
+			continue
+		}
+
+		secondsBehind := column(channel, "Seconds_Behind_Source", "Seconds_Behind_Master")
+		if !secondsBehind.Valid {
+			criticals = append(criticals, fmt.Sprintf("%s lag is unknown", name))
+			continue
+		}
+
+		lag, err := strconv.ParseFloat(secondsBehind.String, 64)
+		if err != nil {
+			criticals = append(criticals, fmt.Sprintf("%s lag is not valid: %s", name, secondsBehind.String))
+			continue
+		}
+
+		if d.verbose {
+			d.logger.Printf("MySQL %s lag: %.0f seconds\n", name, lag)
+		}
+
+		if replication.criticalLagSeconds > 0 && lag >= replication.criticalLagSeconds {
+			criticals = append(criticals, fmt.Sprintf("%s lag %.0f seconds exceeds critical threshold %.0f seconds",
+				name, lag, replication.criticalLagSeconds))
+		} else if replication.warningLagSeconds > 0 && lag >= replication.warningLagSeconds {
+			warnings = append(warnings, fmt.Sprintf("%s lag %.0f seconds exceeds warning threshold %.0f seconds",
+				name, lag, replication.warningLagSeconds))
+		}
+	}
+
+	return criticals, warnings
+}
+
+// checkPrimary will check the number of replicas registered to primary server
+func (d *MySQL) checkPrimary(ctx context.Context, replication replicationConfig) ([]string, []string) {
+	if replication.minReplicas <= 0 {
+		return nil, nil
+	}
+
+	replicas, err := d.queryMaps(ctx, "SHOW REPLICAS", "SHOW SLAVE HOSTS")
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read replicas: %s", err.Error())}, nil
+	}
+
+	if len(replicas) < replication.minReplicas {
+		return []string{fmt.Sprintf("%d replicas registered, expected at least %d", len(replicas), replication.minReplicas)}, nil
+	}
+
+	return nil, nil
+}
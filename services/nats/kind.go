@@ -0,0 +1,21 @@
+package nats
+
+import (
+	"log"
+
+	"github.com/telkomdev/tob"
+)
+
+func init() {
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.NATS,
+		Description: "NATS server and JetStream streams",
+		Options: []tob.Option{
+			{Name: "monitorUrl", Type: "string", Description: "HTTP monitoring url, enables the JetStream checks"},
+			{Name: "streams", Type: "array", Description: "stream and consumer thresholds"},
+		},
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewNATS(verbose, logger)
+		},
+	})
+}
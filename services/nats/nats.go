@@ -0,0 +1,521 @@
+package nats
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/telkomdev/tob"
+	"github.com/telkomdev/tob/config"
+	"github.com/telkomdev/tob/httpx"
+	"github.com/telkomdev/tob/util"
+)
+
+const (
+	// DefaultPort default NATS client port
+	DefaultPort = "4222"
+)
+
+// serverInfo represent INFO message sent by NATS server right after the connection is opened
+type serverInfo struct {
+	ServerID     string `json:"server_id"`
+	Version      string `json:"version"`
+	TLSRequired  bool   `json:"tls_required"`
+	AuthRequired bool   `json:"auth_required"`
+}
+
+// connectOptions represent CONNECT message sent to NATS server
+type connectOptions struct {
+	Verbose     bool   `json:"verbose"`
+	Pedantic    bool   `json:"pedantic"`
+	TLSRequired bool   `json:"tls_required"`
+	Name        string `json:"name"`
+	Lang        string `json:"lang"`
+	Version     string `json:"version"`
+	Protocol    int    `json:"protocol"`
+	User        string `json:"user,omitempty"`
+	Pass        string `json:"pass,omitempty"`
+	AuthToken   string `json:"auth_token,omitempty"`
+}
+
+// streamThreshold represent JetStream lag thresholds of a single stream
+type streamThreshold struct {
+	name        string
+	consumer    string
+	warningLag  float64
+	criticalLag float64
+}
+
+// jszResponse represent the part of /jsz monitoring response used by tob
+type jszResponse struct {
+	AccountDetails []struct {
+		StreamDetail []struct {
+			Name           string `json:"name"`
+			ConsumerDetail []struct {
+				Name       string  `json:"name"`
+				NumPending float64 `json:"num_pending"`
+			} `json:"consumer_detail"`
+		} `json:"stream_detail"`
+	} `json:"account_details"`
+}
+
+// NATS service
+type NATS struct {
+	url               string
+	recovered         bool
+	lastDownTime      string
+	enabled           bool
+	verbose           bool
+	logger            *log.Logger
+	conn              net.Conn
+	reader            *bufio.Reader
+	info              serverInfo
+	checkInterval     int
+	stopChan          chan bool
+	message           string
+	configs           config.Config
+	notificatorConfig config.Config
+}
+
+// NewNATS NATS's constructor
+func NewNATS(verbose bool, logger *log.Logger) *NATS {
+	stopChan := make(chan bool, 1)
+	return &NATS{
+		logger:  logger,
+		verbose: verbose,
+
+		// by default service is recovered
+		recovered:     true,
+		checkInterval: 0,
+		stopChan:      stopChan,
+	}
+}
+
+// Name the name of the service
+func (d *NATS) Name() string {
+	return "nats"
+}
+
+// Ping will try to ping the service
+func (d *NATS) Ping() []byte {
+	if d.conn == nil {
+		err := d.dial()
+		if err != nil {
+			d.SetMessage(err.Error())
+			if d.verbose {
+				d.logger.Println("NATS error re dial")
+				d.logger.Println(err)
+			}
+			return []byte("NOT_OK")
+		}
+	}
+
+	rtt, err := d.roundTrip()
+	if err != nil {
+		d.SetMessage(err.Error())
+		if d.verbose {
+			d.logger.Println("NATS error PING")
+			d.logger.Println(err)
+		}
+
+		// drop the broken connection, next Ping will re dial
+		d.Close()
+		d.conn = nil
+
+		return []byte("NOT_OK")
+	}
+
+	if d.verbose {
+		d.logger.Printf("NATS server %s version %s round trip: %v\n", d.info.ServerID, d.info.Version, rtt)
+	}
+
+	monitorURL, ok := d.configs["monitorUrl"].(string)
+	if !ok || monitorURL == "" {
+		return []byte("OK")
+	}
+
+	return d.checkStreams(monitorURL)
+}
+
+// roundTrip will send PING and wait for PONG
+func (d *NATS) roundTrip() (time.Duration, error) {
+	start := time.Now()
+
+	err := d.conn.SetDeadline(start.Add(5 * time.Second))
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = d.conn.Write([]byte("PING\r\n"))
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		line, err := d.reader.ReadString('\n')
+		if err != nil {
+			return 0, err
+		}
+
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return time.Since(start), nil
+		case line == "PING":
+			// the server can ping us at any time
+			_, err = d.conn.Write([]byte("PONG\r\n"))
+			if err != nil {
+				return 0, err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return 0, errors.New(strings.Trim(strings.TrimPrefix(line, "-ERR "), "'"))
+		}
+	}
+}
+
+// checkStreams will check JetStream consumer lag through the monitoring endpoint
+func (d *NATS) checkStreams(monitorURL string) []byte {
+	thresholds := d.streamThresholds()
+	if len(thresholds) == 0 {
+		return []byte("OK")
+	}
+
+	resp, err := httpx.HTTPGet(fmt.Sprintf("%s/jsz?streams=true&consumers=true", strings.TrimSuffix(monitorURL, "/")), nil, 5)
+	if err != nil {
+		d.SetMessage(err.Error())
+		return []byte("NOT_OK")
+	}
+
+	defer func() { resp.Body.Close() }()
+
+	statusOK := resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !statusOK {
+		d.SetMessage(fmt.Sprintf("NATS monitoring status: %d", resp.StatusCode))
+		return []byte("NOT_OK")
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		d.SetMessage(err.Error())
+		return []byte("NOT_OK")
+	}
+
+	var jsz jszResponse
+	err = json.Unmarshal(body, &jsz)
+	if err != nil {
+		d.SetMessage(err.Error())
+		return []byte("NOT_OK")
+	}
+
+	// stream name => consumer name => pending messages
+	lags := make(map[string]map[string]float64)
+	for _, account := range jsz.AccountDetails {
+		for _, stream := range account.StreamDetail {
+			consumers := make(map[string]float64)
+			for _, consumer := range stream.ConsumerDetail {
+				consumers[consumer.Name] = consumer.NumPending
+			}
+			lags[stream.Name] = consumers
+		}
+	}
+
+	var (
+		criticals []string
+		warnings  []string
+	)
+
+	for _, threshold := range thresholds {
+		consumers, ok := lags[threshold.name]
+		if !ok {
+			criticals = append(criticals, fmt.Sprintf("stream %s not found", threshold.name))
+			continue
+		}
+
+		for consumerName, lag := range consumers {
+			if threshold.consumer != "" && threshold.consumer != consumerName {
+				continue
+			}
+
+			if d.verbose {
+				d.logger.Printf("NATS stream %s consumer %s lag: %d\n", threshold.name, consumerName, int(lag))
+			}
+
+			if threshold.criticalLag > 0 && lag >= threshold.criticalLag {
+				criticals = append(criticals, fmt.Sprintf("stream %s consumer %s lag %d exceeds critical threshold %d",
+					threshold.name, consumerName, int(lag), int(threshold.criticalLag)))
+			} else if threshold.warningLag > 0 && lag >= threshold.warningLag {
+				warnings = append(warnings, fmt.Sprintf("stream %s consumer %s lag %d exceeds warning threshold %d",
+					threshold.name, consumerName, int(lag), int(threshold.warningLag)))
+			}
+		}
+
+		if _, ok := consumers[threshold.consumer]; threshold.consumer != "" && !ok {
+			criticals = append(criticals, fmt.Sprintf("stream %s consumer %s not found", threshold.name, threshold.consumer))
+		}
+	}
+
+	if len(criticals) > 0 {
+		d.SetMessage(strings.Join(append(criticals, warnings...), "\n"))
+		return []byte("NOT_OK")
+	}
+
+	if len(warnings) > 0 {
+		d.SetMessage(strings.Join(warnings, "\n"))
+		return []byte("DEGRADED")
+	}
+
+	return []byte("OK")
+}
+
+// streamThresholds will parse streams field from the service config
+func (d *NATS) streamThresholds() []streamThreshold {
+	var thresholds []streamThreshold
+
+	streams, ok := d.configs["streams"].([]interface{})
+	if !ok {
+		return thresholds
+	}
+
+	for _, streamInterface := range streams {
+		stream, ok := streamInterface.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, ok := stream["name"].(string)
+		if !ok || name == "" {
+			continue
+		}
+
+		consumer, _ := stream["consumer"].(string)
+
+		thresholds = append(thresholds, streamThreshold{
+			name:        name,
+			consumer:    consumer,
+			warningLag:  util.InterfaceToFloat64(stream["warningLag"]),
+			criticalLag: util.InterfaceToFloat64(stream["criticalLag"]),
+		})
+	}
+
+	return thresholds
+}
+
+// SetURL will set the service URL
+func (d *NATS) SetURL(url string) {
+	d.url = url
+}
+
+func (d *NATS) dial() error {
+	if d.verbose {
+		d.logger.Println("connecting to NATS server")
+	}
+
+	u, err := url.Parse(d.url)
+	if err != nil {
+		return err
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), DefaultPort)
+	}
+
+	conn, err := net.DialTimeout("tcp", host, 10*time.Second)
+	if err != nil {
+		return err
+	}
+
+	err = conn.SetDeadline(time.Now().Add(10 * time.Second))
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	reader := bufio.NewReader(conn)
+
+	// the first message from NATS server is always INFO
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	if !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("unexpected NATS greeting: %s", strings.TrimSpace(line))
+	}
+
+	var info serverInfo
+	err = json.Unmarshal([]byte(strings.TrimPrefix(line, "INFO ")), &info)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	useTLS := info.TLSRequired || u.Scheme == "tls"
+	if useTLS {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		err = tlsConn.Handshake()
+		if err != nil {
+			conn.Close()
+			return err
+		}
+
+		conn = tlsConn
+		reader = bufio.NewReader(conn)
+	}
+
+	options := connectOptions{
+		TLSRequired: useTLS,
+		Name:        "tob",
+		Lang:        "go",
+		Version:     tob.Version,
+		Protocol:    1,
+	}
+
+	if u.User != nil {
+		password, ok := u.User.Password()
+		if ok {
+			options.User = u.User.Username()
+			options.Pass = password
+		} else {
+			options.AuthToken = u.User.Username()
+		}
+	}
+
+	connectJSON, err := json.Marshal(options)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	_, err = conn.Write([]byte(fmt.Sprintf("CONNECT %s\r\n", connectJSON)))
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	// set connected conn
+	d.conn = conn
+	d.reader = reader
+	d.info = info
+
+	// PING right after CONNECT, so an authorization error is returned here
+	_, err = d.roundTrip()
+	if err != nil {
+		conn.Close()
+		d.conn = nil
+		return err
+	}
+
+	if d.verbose {
+		d.logger.Println("connecting to NATS server succeed")
+	}
+
+	return nil
+}
+
+// Connect to service if needed
+func (d *NATS) Connect() error {
+	return d.dial()
+}
+
+// Close will close the service resources if needed
+func (d *NATS) Close() error {
+	if d.verbose {
+		d.logger.Println("closing NATS connection")
+	}
+
+	if d.conn != nil {
+		err := d.conn.Close()
+		if err != nil {
+			return err
+		}
+
+		if d.verbose {
+			d.logger.Println("closing NATS connection succeed")
+		}
+	}
+
+	return nil
+}
+
+// SetRecover will set recovered status
+func (d *NATS) SetRecover(recovered bool) {
+	d.recovered = recovered
+}
+
+// IsRecover will return recovered status
+func (d *NATS) IsRecover() bool {
+	return d.recovered
+}
+
+// LastDownTime will set last down time of service to current time
+func (d *NATS) SetLastDownTimeNow() {
+	if d.recovered {
+		d.lastDownTime = time.Now().Format(util.YYMMDD)
+	}
+}
+
+// GetDownTimeDiff will return down time service difference in minutes
+func (d *NATS) GetDownTimeDiff() string {
+	return util.TimeDifference(d.lastDownTime, time.Now().Format(util.YYMMDD))
+}
+
+// SetCheckInterval will set check interval to service
+func (d *NATS) SetCheckInterval(interval int) {
+	d.checkInterval = interval
+}
+
+// GetCheckInterval will return check interval to service
+func (d *NATS) GetCheckInterval() int {
+	return d.checkInterval
+}
+
+// Enable will set enabled status to service
+func (d *NATS) Enable(enabled bool) {
+	d.enabled = enabled
+}
+
+// IsEnabled will return enable status
+func (d *NATS) IsEnabled() bool {
+	return d.enabled
+}
+
+// SetMessage will set additional message
+func (d *NATS) SetMessage(message string) {
+	d.message = message
+}
+
+// GetMessage will return additional message
+func (d *NATS) GetMessage() string {
+	return d.message
+}
+
+// SetConfig will set config
+func (d *NATS) SetConfig(configs config.Config) {
+	d.configs = configs
+}
+
+// SetNotificatorConfig will set config
+func (d *NATS) SetNotificatorConfig(configs config.Config) {
+	d.notificatorConfig = configs
+}
+
+// GetNotificators will return notificators
+func (d *NATS) GetNotificators() []tob.Notificator {
+	return tob.InitNotificatorFactory(d.notificatorConfig, d.verbose)
+}
+
+// Stop will receive stop channel
+func (d *NATS) Stop() chan bool {
+	return d.stopChan
+}
@@ -0,0 +1,17 @@
+package oracle
+
+import (
+	"log"
+
+	"github.com/telkomdev/tob"
+)
+
+func init() {
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.Oracle,
+		Description: "Oracle ping",
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewOracle(verbose, logger)
+		},
+	})
+}
@@ -2,13 +2,16 @@ package oracle
 
 import (
 	"context"
+	"database/sql"
 	"log"
 	"net/url"
+	"strings"
 	"time"
 
 	ora "github.com/sijms/go-ora/v2"
 	"github.com/telkomdev/tob"
 	"github.com/telkomdev/tob/config"
+	"github.com/telkomdev/tob/sqlquery"
 	"github.com/telkomdev/tob/util"
 )
 
@@ -21,9 +24,11 @@ type Oracle struct {
 	verbose           bool
 	logger            *log.Logger
 	db                *ora.Connection
+	queryDB           *sql.DB
 	checkInterval     int
 	stopChan          chan bool
 	message           string
+	configs           config.Config
 	notificatorConfig config.Config
 }
 
@@ -61,6 +66,23 @@ func (d *Oracle) Ping() []byte {
 		return []byte("NOT_OK")
 	}
 
+	queries := sqlquery.ParseQueries(d.configs)
+	if len(queries) == 0 || d.queryDB == nil {
+		return []byte("OK")
+	}
+
+	// go-ora does not support read only sql.TxOptions
+	criticals, warnings := sqlquery.NewRunner(d.queryDB, "SET TRANSACTION READ ONLY").Run(queries)
+	if len(criticals) > 0 {
+		d.SetMessage(strings.Join(append(criticals, warnings...), "\n"))
+		return []byte("NOT_OK")
+	}
+
+	if len(warnings) > 0 {
+		d.SetMessage(strings.Join(warnings, "\n"))
+		return []byte("DEGRADED")
+	}
+
 	return []byte("OK")
 }
 
@@ -116,6 +138,17 @@ func (d *Oracle) Connect() error {
 		return err
 	}
 
+	// query checks use database/sql, so the query can be run with timeout
+	if len(sqlquery.ParseQueries(d.configs)) > 0 {
+		queryDB, err := sql.Open("oracle", connStr)
+		if err != nil {
+			conn.Close()
+			return err
+		}
+
+		d.queryDB = queryDB
+	}
+
 	if d.verbose {
 		d.logger.Println("connecting to Oracle server succeed")
 	}
@@ -132,6 +165,13 @@ func (d *Oracle) Close() error {
 		d.logger.Println("closing Oracle connection")
 	}
 
+	if d.queryDB != nil {
+		err := d.queryDB.Close()
+		if err != nil {
+			return err
+		}
+	}
+
 	if d.db != nil {
 		err := d.db.Close()
 		if err != nil {
@@ -200,7 +240,7 @@ func (d *Oracle) GetMessage() string {
 
 // SetConfig will set config
 func (d *Oracle) SetConfig(configs config.Config) {
-
+	d.configs = configs
 }
 
 // SetNotificatorConfig will set config
@@ -0,0 +1,20 @@
+package postgres
+
+import (
+	"log"
+
+	"github.com/telkomdev/tob"
+)
+
+func init() {
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.Postgresql,
+		Description: "PostgreSQL ping and replication",
+		Options: []tob.Option{
+			{Name: "replication", Type: "object", Description: "replication checks: role, minimum replicas, lag and replication slots"},
+		},
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewPostgres(verbose, logger)
+		},
+	})
+}
@@ -0,0 +1,214 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/telkomdev/tob/util"
+)
+
+// replicationConfig represent replication field of the service config
+type replicationConfig struct {
+	role               string
+	warningLagSeconds  float64
+	criticalLagSeconds float64
+	minReplicas        int
+	checkSlots         bool
+}
+
+func parseReplicationConfig(replication map[string]interface{}) replicationConfig {
+	role, _ := replication["role"].(string)
+	checkSlots, _ := replication["checkSlots"].(bool)
+
+	return replicationConfig{
+		role:               role,
+		warningLagSeconds:  util.InterfaceToFloat64(replication["warningLagSeconds"]),
+		criticalLagSeconds: util.InterfaceToFloat64(replication["criticalLagSeconds"]),
+		minReplicas:        int(util.InterfaceToFloat64(replication["minReplicas"])),
+		checkSlots:         checkSlots,
+	}
+}
+
+// checkReplication will check replication health of primary or replica server
+func (d *Postgres) checkReplication(replication replicationConfig) ([]string, []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer func() { cancel() }()
+
+	var inRecovery bool
+	err := d.db.QueryRowContext(ctx, "SELECT pg_is_in_recovery()").Scan(&inRecovery)
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read recovery status: %s", err.Error())}, nil
+	}
+
+	role := "primary"
+	if inRecovery {
+		role = "replica"
+	}
+
+	if d.verbose {
+		d.logger.Printf("Postgres replication role: %s\n", role)
+	}
+
+	if replication.role != "" && replication.role != role {
+		return []string{fmt.Sprintf("expected %s but server is %s", replication.role, role)}, nil
+	}
+
+	if inRecovery {
+		return d.checkReplica(ctx, replication)
+	}
+
+	return d.checkPrimary(ctx, replication)
+}
+
+// checkReplica will check WAL receiver and WAL replay lag of replica server
+func (d *Postgres) checkReplica(ctx context.Context, replication replicationConfig) ([]string, []string) {
+	var receiverStatus string
+	err := d.db.QueryRowContext(ctx, "SELECT status FROM pg_stat_wal_receiver").Scan(&receiverStatus)
+	if err == sql.ErrNoRows {
+		return []string{"WAL receiver is not running"}, nil
+	}
+
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read WAL receiver status: %s", err.Error())}, nil
+	}
+
+	if receiverStatus != "streaming" {
+		return []string{fmt.Sprintf("WAL receiver is %s", receiverStatus)}, nil
+	}
+
+	// lag is zero when everything received has been replayed,
+	// otherwise it is the age of the last replayed transaction
+	var lag float64
+	err = d.db.QueryRowContext(ctx, `SELECT CASE
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END`).Scan(&lag)
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read WAL replay lag: %s", err.Error())}, nil
+	}
+
+	if d.verbose {
+		d.logger.Printf("Postgres WAL replay lag: %.0f seconds\n", lag)
+	}
+
+	return lagThreshold("WAL replay", lag, replication)
+}
+
+// checkPrimary will check connected replicas and replication slots of primary server
+func (d *Postgres) checkPrimary(ctx context.Context, replication replicationConfig) ([]string, []string) {
+	var (
+		criticals []string
+		warnings  []string
+	)
+
+	rows, err := d.db.QueryContext(ctx, `SELECT COALESCE(application_name, ''), COALESCE(client_addr::text, ''), state,
+		COALESCE(EXTRACT(EPOCH FROM replay_lag), 0) FROM pg_stat_replication`)
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read pg_stat_replication: %s", err.Error())}, nil
+	}
+
+	defer func() { rows.Close() }()
+
+	streaming := 0
+	for rows.Next() {
+		var (
+			applicationName string
+			clientAddr      string
+			state           string
+			lag             float64
+		)
+
+		err = rows.Scan(&applicationName, &clientAddr, &state, &lag)
+		if err != nil {
+			return []string{fmt.Sprintf("cannot read pg_stat_replication: %s", err.Error())}, nil
+		}
+
+		name := applicationName
+		if clientAddr != "" {
+			name = fmt.Sprintf("%s (%s)", applicationName, clientAddr)
+		}
+
+		if d.verbose {
+			d.logger.Printf("Postgres replica %s: %s, replay lag %.0f seconds\n", name, state, lag)
+		}
+
+		if state != "streaming" {
+			warnings = append(warnings, fmt.Sprintf("replica %s is %s", name, state))
+			continue
+		}
+
+		streaming++
+
+		c, w := lagThreshold(fmt.Sprintf("replica %s replay", name), lag, replication)
+		criticals = append(criticals, c...)
+		warnings = append(warnings, w...)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read pg_stat_replication: %s", err.Error())}, nil
+	}
+
+	if streaming < replication.minReplicas {
+		criticals = append(criticals, fmt.Sprintf("%d streaming replicas, expected at least %d", streaming, replication.minReplicas))
+	}
+
+	if replication.checkSlots {
+		c, w := d.checkSlots(ctx)
+		criticals = append(criticals, c...)
+		warnings = append(warnings, w...)
+	}
+
+	return criticals, warnings
+}
+
+// checkSlots will check inactive replication slots, an inactive slot retains WAL on the primary
+func (d *Postgres) checkSlots(ctx context.Context) ([]string, []string) {
+	var warnings []string
+
+	rows, err := d.db.QueryContext(ctx, "SELECT slot_name, active FROM pg_replication_slots")
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read pg_replication_slots: %s", err.Error())}, nil
+	}
+
+	defer func() { rows.Close() }()
+
+	for rows.Next() {
+		var (
+			slotName string
+			active   bool
+		)
+
+		err = rows.Scan(&slotName, &active)
+		if err != nil {
+			return []string{fmt.Sprintf("cannot read pg_replication_slots: %s", err.Error())}, nil
+		}
+
+		if !active {
+			warnings = append(warnings, fmt.Sprintf("replication slot %s is inactive", slotName))
+		}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read pg_replication_slots: %s", err.Error())}, nil
+	}
+
+	return nil, warnings
+}
+
+func lagThreshold(name string, lag float64, replication replicationConfig) ([]string, []string) {
+	if replication.criticalLagSeconds > 0 && lag >= replication.criticalLagSeconds {
+		return []string{fmt.Sprintf("%s lag %.0f seconds exceeds critical threshold %.0f seconds",
+			name, lag, replication.criticalLagSeconds)}, nil
+	}
+
+	if replication.warningLagSeconds > 0 && lag >= replication.warningLagSeconds {
+		return nil, []string{fmt.Sprintf("%s lag %.0f seconds exceeds warning threshold %.0f seconds",
+			name, lag, replication.warningLagSeconds)}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,21 @@
+package rabbitmq
+
+import (
+	"log"
+
+	"github.com/telkomdev/tob"
+)
+
+func init() {
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.RabbitMQ,
+		Description: "RabbitMQ connection and queues",
+		Options: []tob.Option{
+			{Name: "managementUrl", Type: "string", Description: "management API url, enables the queue checks"},
+			{Name: "queues", Type: "array", Description: "queue thresholds"},
+		},
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewRabbitMQ(verbose, logger)
+		},
+	})
+}
@@ -0,0 +1,380 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/telkomdev/tob"
+	"github.com/telkomdev/tob/config"
+	"github.com/telkomdev/tob/httpx"
+	"github.com/telkomdev/tob/util"
+)
+
+// queueThreshold represent queue depth and consumer thresholds of a single queue
+type queueThreshold struct {
+	name          string
+	vhost         string
+	warningDepth  float64
+	criticalDepth float64
+	minConsumers  float64
+}
+
+// queueInfo represent queue info returned by RabbitMQ management API
+type queueInfo struct {
+	Name      string  `json:"name"`
+	Vhost     string  `json:"vhost"`
+	Messages  float64 `json:"messages"`
+	Consumers float64 `json:"consumers"`
+}
+
+// RabbitMQ service
+type RabbitMQ struct {
+	url               string
+	recovered         bool
+	lastDownTime      string
+	enabled           bool
+	verbose           bool
+	logger            *log.Logger
+	conn              *amqp.Connection
+	checkInterval     int
+	stopChan          chan bool
+	message           string
+	configs           config.Config
+	notificatorConfig config.Config
+}
+
+// NewRabbitMQ RabbitMQ's constructor
+func NewRabbitMQ(verbose bool, logger *log.Logger) *RabbitMQ {
+	stopChan := make(chan bool, 1)
+	return &RabbitMQ{
+		logger:  logger,
+		verbose: verbose,
+
+		// by default service is recovered
+		recovered:     true,
+		checkInterval: 0,
+		stopChan:      stopChan,
+	}
+}
+
+// Name the name of the service
+func (d *RabbitMQ) Name() string {
+	return "rabbitmq"
+}
+
+// Ping will try to ping the service
+func (d *RabbitMQ) Ping() []byte {
+	if d.conn == nil || d.conn.IsClosed() {
+		if d.verbose {
+			d.logger.Println("RabbitMQ connection is closed | do re dial")
+		}
+
+		err := d.dial()
+		if err != nil {
+			d.SetMessage(err.Error())
+			if d.verbose {
+				d.logger.Println("RabbitMQ error re dial")
+				d.logger.Println(err)
+			}
+			return []byte("NOT_OK")
+		}
+	}
+
+	// opening a channel needs a round trip to the broker
+	ch, err := d.conn.Channel()
+	if err != nil {
+		d.SetMessage(err.Error())
+		if d.verbose {
+			d.logger.Println("RabbitMQ error open channel")
+			d.logger.Println(err)
+		}
+		return []byte("NOT_OK")
+	}
+
+	err = ch.Close()
+	if err != nil {
+		d.SetMessage(err.Error())
+		if d.verbose {
+			d.logger.Println("RabbitMQ error close channel")
+			d.logger.Println(err)
+		}
+		return []byte("NOT_OK")
+	}
+
+	managementURL, ok := d.configs["managementUrl"].(string)
+	if !ok || managementURL == "" {
+		return []byte("OK")
+	}
+
+	return d.checkQueues(managementURL)
+}
+
+// checkQueues will check depth and consumer count of the configured queues through the management API
+func (d *RabbitMQ) checkQueues(managementURL string) []byte {
+	thresholds := d.queueThresholds()
+
+	var (
+		criticals []string
+		warnings  []string
+	)
+
+	for _, threshold := range thresholds {
+		queue, err := d.getQueue(managementURL, threshold)
+		if err != nil {
+			criticals = append(criticals, fmt.Sprintf("queue %s: %s", threshold.name, err.Error()))
+			continue
+		}
+
+		if d.verbose {
+			d.logger.Printf("RabbitMQ queue %s: messages %d, consumers %d\n",
+				queue.Name, int(queue.Messages), int(queue.Consumers))
+		}
+
+		if threshold.minConsumers > 0 && queue.Consumers < threshold.minConsumers {
+			criticals = append(criticals, fmt.Sprintf("queue %s has %d consumers, expected at least %d",
+				queue.Name, int(queue.Consumers), int(threshold.minConsumers)))
+		}
+
+		if threshold.criticalDepth > 0 && queue.Messages >= threshold.criticalDepth {
+			criticals = append(criticals, fmt.Sprintf("queue %s depth %d exceeds critical threshold %d",
+				queue.Name, int(queue.Messages), int(threshold.criticalDepth)))
+		} else if threshold.warningDepth > 0 && queue.Messages >= threshold.warningDepth {
+			warnings = append(warnings, fmt.Sprintf("queue %s depth %d exceeds warning threshold %d",
+				queue.Name, int(queue.Messages), int(threshold.warningDepth)))
+		}
+	}
+
+	if len(criticals) > 0 {
+		d.SetMessage(strings.Join(append(criticals, warnings...), "\n"))
+		return []byte("NOT_OK")
+	}
+
+	if len(warnings) > 0 {
+		d.SetMessage(strings.Join(warnings, "\n"))
+		return []byte("DEGRADED")
+	}
+
+	return []byte("OK")
+}
+
+// queueThresholds will parse queues field from the service config
+func (d *RabbitMQ) queueThresholds() []queueThreshold {
+	var thresholds []queueThreshold
+
+	queues, ok := d.configs["queues"].([]interface{})
+	if !ok {
+		return thresholds
+	}
+
+	for _, queueInterface := range queues {
+		queue, ok := queueInterface.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, ok := queue["name"].(string)
+		if !ok || name == "" {
+			continue
+		}
+
+		vhost, ok := queue["vhost"].(string)
+		if !ok || vhost == "" {
+			vhost = "/"
+		}
+
+		thresholds = append(thresholds, queueThreshold{
+			name:          name,
+			vhost:         vhost,
+			warningDepth:  util.InterfaceToFloat64(queue["warningDepth"]),
+			criticalDepth: util.InterfaceToFloat64(queue["criticalDepth"]),
+			minConsumers:  util.InterfaceToFloat64(queue["minConsumers"]),
+		})
+	}
+
+	return thresholds
+}
+
+// getQueue will get queue info from the management API
+func (d *RabbitMQ) getQueue(managementURL string, threshold queueThreshold) (*queueInfo, error) {
+	u, err := url.Parse(managementURL)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make(map[string]string)
+	if u.User != nil {
+		password, _ := u.User.Password()
+		headers["Authorization"] = httpx.BasicAuth(u.User.Username(), password)
+
+		u.User = nil
+	}
+
+	queueURL := fmt.Sprintf("%s/api/queues/%s/%s", strings.TrimSuffix(u.String(), "/"),
+		url.PathEscape(threshold.vhost), url.PathEscape(threshold.name))
+
+	resp, err := httpx.HTTPGet(queueURL, headers, 5)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errors.New("queue not found")
+	}
+
+	statusOK := resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !statusOK {
+		return nil, fmt.Errorf("management API status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var queue queueInfo
+	err = json.Unmarshal(body, &queue)
+	if err != nil {
+		return nil, err
+	}
+
+	return &queue, nil
+}
+
+// SetURL will set the service URL
+func (d *RabbitMQ) SetURL(url string) {
+	d.url = url
+}
+
+func (d *RabbitMQ) dial() error {
+	if d.verbose {
+		d.logger.Println("connecting to RabbitMQ server")
+	}
+
+	conn, err := amqp.DialConfig(d.url, amqp.Config{
+		Heartbeat: 10 * time.Second,
+		Locale:    "en_US",
+		Dial:      amqp.DefaultDial(10 * time.Second),
+		Properties: amqp.Table{
+			"connection_name": "tob",
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// set connected conn
+	d.conn = conn
+
+	if d.verbose {
+		d.logger.Println("connecting to RabbitMQ server succeed")
+	}
+
+	return nil
+}
+
+// Connect to service if needed
+func (d *RabbitMQ) Connect() error {
+	return d.dial()
+}
+
+// Close will close the service resources if needed
+func (d *RabbitMQ) Close() error {
+	if d.verbose {
+		d.logger.Println("closing RabbitMQ connection")
+	}
+
+	if d.conn != nil && !d.conn.IsClosed() {
+		err := d.conn.Close()
+		if err != nil {
+			return err
+		}
+
+		if d.verbose {
+			d.logger.Println("closing RabbitMQ connection succeed")
+		}
+	}
+
+	return nil
+}
+
+// SetRecover will set recovered status
+func (d *RabbitMQ) SetRecover(recovered bool) {
+	d.recovered = recovered
+}
+
+// IsRecover will return recovered status
+func (d *RabbitMQ) IsRecover() bool {
+	return d.recovered
+}
+
+// LastDownTime will set last down time of service to current time
+func (d *RabbitMQ) SetLastDownTimeNow() {
+	if d.recovered {
+		d.lastDownTime = time.Now().Format(util.YYMMDD)
+	}
+}
+
+// GetDownTimeDiff will return down time service difference in minutes
+func (d *RabbitMQ) GetDownTimeDiff() string {
+	return util.TimeDifference(d.lastDownTime, time.Now().Format(util.YYMMDD))
+}
+
+// SetCheckInterval will set check interval to service
+func (d *RabbitMQ) SetCheckInterval(interval int) {
+	d.checkInterval = interval
+}
+
+// GetCheckInterval will return check interval to service
+func (d *RabbitMQ) GetCheckInterval() int {
+	return d.checkInterval
+}
+
+// Enable will set enabled status to service
+func (d *RabbitMQ) Enable(enabled bool) {
+	d.enabled = enabled
+}
+
+// IsEnabled will return enable status
+func (d *RabbitMQ) IsEnabled() bool {
+	return d.enabled
+}
+
+// SetMessage will set additional message
+func (d *RabbitMQ) SetMessage(message string) {
+	d.message = message
+}
+
+// GetMessage will return additional message
+func (d *RabbitMQ) GetMessage() string {
+	return d.message
+}
+
+// SetConfig will set config
+func (d *RabbitMQ) SetConfig(configs config.Config) {
+	d.configs = configs
+}
+
+// SetNotificatorConfig will set config
+func (d *RabbitMQ) SetNotificatorConfig(configs config.Config) {
+	d.notificatorConfig = configs
+}
+
+// GetNotificators will return notificators
+func (d *RabbitMQ) GetNotificators() []tob.Notificator {
+	return tob.InitNotificatorFactory(d.notificatorConfig, d.verbose)
+}
+
+// Stop will receive stop channel
+func (d *RabbitMQ) Stop() chan bool {
+	return d.stopChan
+}
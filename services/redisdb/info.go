@@ -0,0 +1,218 @@
+package redisdb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/telkomdev/tob/util"
+)
+
+// infoThreshold represent info field of the service config
+type infoThreshold struct {
+	warningUsedMemoryPercent     float64
+	criticalUsedMemoryPercent    float64
+	warningConnectedClients      float64
+	criticalConnectedClients     float64
+	warningRejectedConnections   float64
+	criticalRejectedConnections  float64
+	warningEvictedKeysPerSecond  float64
+	criticalEvictedKeysPerSecond float64
+	role                         string
+	checkReplicationLink         bool
+}
+
+// infoCounter keeps cumulative INFO counters of the previous check
+type infoCounter struct {
+	rejectedConnections float64
+	evictedKeys         float64
+	checkedAt           time.Time
+}
+
+func parseInfoThreshold(info map[string]interface{}) infoThreshold {
+	role, _ := info["role"].(string)
+	checkReplicationLink, _ := info["checkReplicationLink"].(bool)
+
+	return infoThreshold{
+		warningUsedMemoryPercent:     util.InterfaceToFloat64(info["warningUsedMemoryPercent"]),
+		criticalUsedMemoryPercent:    util.InterfaceToFloat64(info["criticalUsedMemoryPercent"]),
+		warningConnectedClients:      util.InterfaceToFloat64(info["warningConnectedClients"]),
+		criticalConnectedClients:     util.InterfaceToFloat64(info["criticalConnectedClients"]),
+		warningRejectedConnections:   util.InterfaceToFloat64(info["warningRejectedConnections"]),
+		criticalRejectedConnections:  util.InterfaceToFloat64(info["criticalRejectedConnections"]),
+		warningEvictedKeysPerSecond:  util.InterfaceToFloat64(info["warningEvictedKeysPerSecond"]),
+		criticalEvictedKeysPerSecond: util.InterfaceToFloat64(info["criticalEvictedKeysPerSecond"]),
+		role:                         role,
+		checkReplicationLink:         checkReplicationLink,
+	}
+}
+
+// parseInfo will parse INFO reply into key => value
+func parseInfo(info string) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if ok {
+			result[key] = value
+		}
+	}
+
+	return result
+}
+
+func infoFloat(info map[string]string, key string) float64 {
+	value, err := strconv.ParseFloat(info[key], 64)
+	if err != nil {
+		return 0
+	}
+
+	return value
+}
+
+// checkClusterState will check CLUSTER INFO cluster_state
+func (d *Redis) checkClusterState(ctx context.Context) ([]string, []string) {
+	clusterInfo, err := d.client.ClusterInfo(ctx).Result()
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read cluster info: %s", err.Error())}, nil
+	}
+
+	state := parseInfo(clusterInfo)["cluster_state"]
+	if state != "ok" {
+		return []string{fmt.Sprintf("cluster state is %s", state)}, nil
+	}
+
+	return nil, nil
+}
+
+// checkInfo will check INFO of every node, or the connected node when the mode is not cluster
+func (d *Redis) checkInfo(ctx context.Context, threshold infoThreshold) ([]string, []string) {
+	clusterClient, ok := d.client.(*redis.ClusterClient)
+	if !ok {
+		info, err := d.client.Info(ctx).Result()
+		if err != nil {
+			return []string{fmt.Sprintf("cannot read info: %s", err.Error())}, nil
+		}
+
+		return d.checkNodeInfo("", parseInfo(info), threshold)
+	}
+
+	var (
+		criticals []string
+		warnings  []string
+		mutex     sync.Mutex
+	)
+
+	// the expected role is meaningless in cluster mode, every shard has its own master
+	threshold.role = ""
+
+	err := clusterClient.ForEachShard(ctx, func(ctx context.Context, shard *redis.Client) error {
+		node := shard.Options().Addr
+
+		info, err := shard.Info(ctx).Result()
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if err != nil {
+			criticals = append(criticals, fmt.Sprintf("%s: cannot read info: %s", node, err.Error()))
+			return nil
+		}
+
+		c, w := d.checkNodeInfo(node, parseInfo(info), threshold)
+		criticals = append(criticals, c...)
+		warnings = append(warnings, w...)
+
+		return nil
+	})
+	if err != nil {
+		criticals = append(criticals, fmt.Sprintf("cannot read cluster nodes: %s", err.Error()))
+	}
+
+	return criticals, warnings
+}
+
+// checkNodeInfo will check INFO of a single node against the thresholds
+func (d *Redis) checkNodeInfo(node string, info map[string]string, threshold infoThreshold) ([]string, []string) {
+	var (
+		criticals []string
+		warnings  []string
+	)
+
+	prefix := ""
+	if node != "" {
+		prefix = node + ": "
+	}
+
+	check := func(name string, value, warning, critical float64, format string) {
+		if critical > 0 && value >= critical {
+			criticals = append(criticals, fmt.Sprintf("%s%s "+format+" exceeds critical threshold "+format, prefix, name, value, critical))
+		} else if warning > 0 && value >= warning {
+			warnings = append(warnings, fmt.Sprintf("%s%s "+format+" exceeds warning threshold "+format, prefix, name, value, warning))
+		}
+	}
+
+	// used memory percent of maxmemory, or of the system memory when maxmemory is not set
+	maxMemory := infoFloat(info, "maxmemory")
+	if maxMemory <= 0 {
+		maxMemory = infoFloat(info, "total_system_memory")
+	}
+
+	if maxMemory > 0 {
+		usedMemoryPercent := infoFloat(info, "used_memory") / maxMemory * 100
+		check("used memory", usedMemoryPercent, threshold.warningUsedMemoryPercent, threshold.criticalUsedMemoryPercent, "%.0f%%")
+	}
+
+	check("connected clients", infoFloat(info, "connected_clients"),
+		threshold.warningConnectedClients, threshold.criticalConnectedClients, "%.0f")
+
+	role := info["role"]
+	if threshold.role != "" && role != threshold.role {
+		criticals = append(criticals, fmt.Sprintf("%sexpected role %s but server is %s", prefix, threshold.role, role))
+	}
+
+	if threshold.checkReplicationLink && role == "slave" && info["master_link_status"] != "up" {
+		criticals = append(criticals, fmt.Sprintf("%sreplication link to master %s:%s is %s", prefix,
+			info["master_host"], info["master_port"], info["master_link_status"]))
+	}
+
+	// rejected_connections and evicted_keys are cumulative, compare them with the previous check
+	now := time.Now()
+	current := infoCounter{
+		rejectedConnections: infoFloat(info, "rejected_connections"),
+		evictedKeys:         infoFloat(info, "evicted_keys"),
+		checkedAt:           now,
+	}
+
+	d.countersMutex.Lock()
+	previous, ok := d.counters[node]
+	d.counters[node] = current
+	d.countersMutex.Unlock()
+
+	// counters are reset when Redis restarts
+	if ok && current.rejectedConnections >= previous.rejectedConnections && current.evictedKeys >= previous.evictedKeys {
+		check("rejected connections since last check", current.rejectedConnections-previous.rejectedConnections,
+			threshold.warningRejectedConnections, threshold.criticalRejectedConnections, "%.0f")
+
+		elapsed := now.Sub(previous.checkedAt).Seconds()
+		if elapsed > 0 {
+			check("evicted keys per second", (current.evictedKeys-previous.evictedKeys)/elapsed,
+				threshold.warningEvictedKeysPerSecond, threshold.criticalEvictedKeysPerSecond, "%.2f")
+		}
+	}
+
+	if d.verbose {
+		d.logger.Printf("Redis %srole: %s, used memory: %s, connected clients: %s\n",
+			prefix, role, info["used_memory_human"], info["connected_clients"])
+	}
+
+	return criticals, warnings
+}
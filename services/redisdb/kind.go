@@ -0,0 +1,25 @@
+package redisdb
+
+import (
+	"log"
+
+	"github.com/telkomdev/tob"
+)
+
+func init() {
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.Redis,
+		Description: "Redis standalone, cluster or sentinel and INFO thresholds",
+		Options: []tob.Option{
+			{Name: "mode", Type: "string", Description: "standalone, cluster or sentinel, default standalone"},
+			{Name: "masterName", Type: "string", Description: "sentinel master name"},
+			{Name: "sentinelUsername", Type: "string", Description: "sentinel username"},
+			{Name: "sentinelPassword", Type: "string", Description: "sentinel password"},
+			{Name: "tlsSkipVerify", Type: "bool", Description: "skip TLS certificate verification"},
+			{Name: "info", Type: "object", Description: "INFO thresholds: memory, clients, rejected connections, evicted keys and replication link"},
+		},
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewRedis(verbose, logger)
+		},
+	})
+}
@@ -2,8 +2,14 @@ package redisdb
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
 	"log"
+	"net"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -12,6 +18,17 @@ import (
 	"github.com/telkomdev/tob/util"
 )
 
+const (
+	// ModeStandalone single Redis server
+	ModeStandalone = "standalone"
+
+	// ModeCluster Redis Cluster
+	ModeCluster = "cluster"
+
+	// ModeSentinel Redis Sentinel, url hosts are the sentinel addresses
+	ModeSentinel = "sentinel"
+)
+
 // Redis service
 type Redis struct {
 	url               string
@@ -20,10 +37,14 @@ type Redis struct {
 	enabled           bool
 	verbose           bool
 	logger            *log.Logger
-	client            *redis.Client
+	client            redis.UniversalClient
+	mode              string
+	counters          map[string]infoCounter
+	countersMutex     sync.Mutex
 	checkInterval     int
 	stopChan          chan bool
 	message           string
+	configs           config.Config
 	notificatorConfig config.Config
 }
 
@@ -38,6 +59,7 @@ func NewRedis(verbose bool, logger *log.Logger) *Redis {
 		recovered:     true,
 		checkInterval: 0,
 		stopChan:      stopChan,
+		counters:      make(map[string]infoCounter),
 	}
 }
 
@@ -52,7 +74,10 @@ func (d *Redis) Ping() []byte {
 		return []byte("NOT_OK")
 	}
 
-	reply := d.client.Ping(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer func() { cancel() }()
+
+	reply := d.client.Ping(ctx)
 	if reply.Err() != nil {
 		d.SetMessage(reply.Err().Error())
 		if d.verbose {
@@ -67,6 +92,33 @@ func (d *Redis) Ping() []byte {
 		d.logger.Println(reply.String())
 	}
 
+	var (
+		criticals []string
+		warnings  []string
+	)
+
+	if d.mode == ModeCluster {
+		c, w := d.checkClusterState(ctx)
+		criticals = append(criticals, c...)
+		warnings = append(warnings, w...)
+	}
+
+	if info, ok := d.configs["info"].(map[string]interface{}); ok {
+		c, w := d.checkInfo(ctx, parseInfoThreshold(info))
+		criticals = append(criticals, c...)
+		warnings = append(warnings, w...)
+	}
+
+	if len(criticals) > 0 {
+		d.SetMessage(strings.Join(append(criticals, warnings...), "\n"))
+		return []byte("NOT_OK")
+	}
+
+	if len(warnings) > 0 {
+		d.SetMessage(strings.Join(warnings, "\n"))
+		return []byte("DEGRADED")
+	}
+
 	return []byte("OK")
 }
 
@@ -86,20 +138,79 @@ func (d *Redis) Connect() error {
 		return err
 	}
 
-	host := u.Host
+	hosts := strings.Split(u.Host, ",")
+	username := ""
 	password := ""
 	if u.User != nil {
+		username = u.User.Username()
 		p, ok := u.User.Password()
 		if ok {
 			password = p
 		}
 	}
 
-	client := redis.NewClient(&redis.Options{
-		Addr:     host,
+	// the DB index is the URL path, eg: redis://localhost:6379/2
+	db := 0
+	if dbStr := strings.Trim(u.Path, "/"); dbStr != "" {
+		db, err = strconv.Atoi(dbStr)
+		if err != nil {
+			return fmt.Errorf("invalid Redis DB index: %s", dbStr)
+		}
+	}
+
+	options := &redis.UniversalOptions{
+		Addrs:    hosts,
+		Username: username,
 		Password: password,
-		DB:       0, // use default DB
-	})
+		DB:       db,
+	}
+
+	mode, ok := d.configs["mode"].(string)
+	if !ok || mode == "" {
+		mode = ModeStandalone
+	}
+
+	if u.Scheme == "rediss" {
+		tlsSkipVerify, _ := d.configs["tlsSkipVerify"].(bool)
+		options.TLSConfig = &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: tlsSkipVerify,
+		}
+
+		// in cluster and sentinel mode ServerName stays empty,
+		// so each node is verified against the host it is dialed with
+		if mode == ModeStandalone {
+			serverName, _, err := net.SplitHostPort(hosts[0])
+			if err != nil {
+				// the host has no port, eg: [::1]
+				serverName = strings.Trim(hosts[0], "[]")
+			}
+
+			options.TLSConfig.ServerName = serverName
+		}
+	}
+
+	var client redis.UniversalClient
+	switch mode {
+	case ModeCluster:
+		client = redis.NewClusterClient(options.Cluster())
+	case ModeSentinel:
+		// url hosts are the sentinel addresses
+		options.MasterName, _ = d.configs["masterName"].(string)
+		options.SentinelUsername, _ = d.configs["sentinelUsername"].(string)
+		options.SentinelPassword, _ = d.configs["sentinelPassword"].(string)
+		if options.MasterName == "" {
+			return fmt.Errorf("masterName is required for Redis %s mode", ModeSentinel)
+		}
+
+		client = redis.NewFailoverClient(options.Failover())
+	case ModeStandalone:
+		client = redis.NewClient(options.Simple())
+	default:
+		return fmt.Errorf("invalid Redis mode: %s", mode)
+	}
+
+	d.mode = mode
 
 	if d.verbose {
 		d.logger.Println("connecting to Redis server succeed")
@@ -185,7 +296,7 @@ func (d *Redis) GetMessage() string {
 
 // SetConfig will set config
 func (d *Redis) SetConfig(configs config.Config) {
-
+	d.configs = configs
 }
 
 // SetNotificatorConfig will set config
@@ -0,0 +1,26 @@
+package sslstatus
+
+import (
+	"log"
+
+	"github.com/telkomdev/tob"
+)
+
+func init() {
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.SSLStatus,
+		Description: "TLS certificate expiry and chain of domains",
+		Options: []tob.Option{
+			{Name: "domains", Type: "array", Required: true, Description: "hosts, host:port, scheme://host:port or objects with host, port, serverName, starttls and caFile"},
+			{Name: "caFile", Type: "string", Description: "CA bundle trusted in addition to the system CA bundle"},
+			{Name: "checkOCSP", Type: "bool", Description: "check the revocation status via OCSP"},
+			{Name: "timeout", Type: "number", Description: "connection timeout in seconds"},
+			{Name: "warningDays", Type: "number", Description: "days left before the service is degraded"},
+			{Name: "dangerDays", Type: "number", Description: "days left before the service is down"},
+			{Name: "criticalDays", Type: "number", Description: "days left before the service is down as critical"},
+		},
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewSSLStatus(verbose, logger)
+		},
+	})
+}
@@ -0,0 +1,70 @@
+package sslstatus
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/telkomdev/tob/httpx"
+	"golang.org/x/crypto/ocsp"
+)
+
+// checkOCSP will check the revocation status of the leaf certificate,
+// the stapled OCSP response is used when the server sends one, otherwise the OCSP responder is queried
+func checkOCSP(stapled []byte, leaf, issuer *x509.Certificate, timeout time.Duration) (string, string) {
+	source := "stapled"
+
+	responseBytes := stapled
+	if len(responseBytes) == 0 {
+		// the certificate authority does not run an OCSP responder
+		if len(leaf.OCSPServer) == 0 {
+			return SeverityInfo, ""
+		}
+
+		source = leaf.OCSPServer[0]
+
+		var err error
+		responseBytes, err = queryOCSP(leaf.OCSPServer[0], leaf, issuer, timeout)
+		if err != nil {
+			return SeverityWarning, fmt.Sprintf("OCSP check failed: %s", err.Error())
+		}
+	}
+
+	response, err := ocsp.ParseResponseForCert(responseBytes, leaf, issuer)
+	if err != nil {
+		return SeverityWarning, fmt.Sprintf("invalid OCSP response (%s): %s", source, err.Error())
+	}
+
+	switch response.Status {
+	case ocsp.Good:
+		return SeverityInfo, ""
+	case ocsp.Revoked:
+		return SeverityCritical, fmt.Sprintf("certificate revoked on %s (OCSP %s)", response.RevokedAt.Format(time.RFC1123), source)
+	default:
+		return SeverityWarning, fmt.Sprintf("OCSP status unknown (%s)", source)
+	}
+}
+
+// queryOCSP will send OCSP request to the responder
+func queryOCSP(server string, leaf, issuer *x509.Certificate, timeout time.Duration) ([]byte, error) {
+	request, err := ocsp.CreateRequest(leaf, issuer, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpx.HTTPPost(server, bytes.NewReader(request),
+		map[string]string{"Content-Type": "application/ocsp-request"}, int(timeout.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { resp.Body.Close() }()
+
+	if resp.StatusCode != 200 {
+		return nil, httpx.ErrorStatusNot200
+	}
+
+	return io.ReadAll(resp.Body)
+}
@@ -1,88 +1,360 @@
 package sslstatus
 
 import (
+	"bytes"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
-	"log"
+	"net"
+	"os"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/telkomdev/tob/util"
 )
 
-func checkSSLExpiry(domain string, logger *log.Logger) string {
-	conn, err := tls.Dial("tcp", domain+":443", &tls.Config{
-		InsecureSkipVerify: true,
-	})
+const (
+	// SeverityInfo the certificate is valid and far from expiry
+	SeverityInfo = "Info"
 
-	status := "Info"
+	// SeverityWarning the certificate expires within warningDays
+	SeverityWarning = "Warning"
 
-	if err != nil {
-		status = "Danger"
+	// SeverityDanger the certificate expires within dangerDays, or the TLS handshake or chain verification failed
+	SeverityDanger = "Danger"
+
+	// SeverityCritical the certificate expires within criticalDays, is expired or is revoked
+	SeverityCritical = "Critical"
+
+	// DefaultWarningDays default warningDays threshold
+	DefaultWarningDays = 30
+
+	// DefaultDangerDays default dangerDays threshold
+	DefaultDangerDays = 15
+
+	// DefaultCriticalDays default criticalDays threshold
+	DefaultCriticalDays = 7
+
+	// DefaultTimeout default dial and handshake timeout in seconds
+	DefaultTimeout = 10
+)
+
+var (
+	// ErrorNoCertificate error type
+	ErrorNoCertificate = errors.New("error: no certificate found")
+
+	// defaultPorts default port of each STARTTLS protocol, https when empty
+	defaultPorts = map[string]string{
+		"":         "443",
+		"smtp":     "25",
+		"imap":     "143",
+		"pop3":     "110",
+		"postgres": "5432",
+	}
+)
+
+// Thresholds represent the days to expiry of each severity
+type Thresholds struct {
+	WarningDays  int
+	DangerDays   int
+	CriticalDays int
+}
+
+// ParseThresholds will parse warningDays, dangerDays and criticalDays from the service config
+func ParseThresholds(configs map[string]interface{}) Thresholds {
+	thresholds := Thresholds{
+		WarningDays:  DefaultWarningDays,
+		DangerDays:   DefaultDangerDays,
+		CriticalDays: DefaultCriticalDays,
+	}
+
+	if _, ok := configs["warningDays"]; ok {
+		thresholds.WarningDays = int(util.InterfaceToFloat64(configs["warningDays"]))
+	}
+
+	if _, ok := configs["dangerDays"]; ok {
+		thresholds.DangerDays = int(util.InterfaceToFloat64(configs["dangerDays"]))
+	}
+
+	if _, ok := configs["criticalDays"]; ok {
+		thresholds.CriticalDays = int(util.InterfaceToFloat64(configs["criticalDays"]))
+	}
+
+	return thresholds
+}
 
-		return fmt.Sprintf("%s: %s - %v\n",
-			status,
-			strings.TrimPrefix(domain, "*."),
-			err)
+// Severity will return the severity of the days left before expiry
+func (t Thresholds) Severity(daysLeft int) string {
+	switch {
+	case daysLeft < 0 || daysLeft <= t.CriticalDays:
+		return SeverityCritical
+	case daysLeft <= t.DangerDays:
+		return SeverityDanger
+	case daysLeft <= t.WarningDays:
+		return SeverityWarning
+	default:
+		return SeverityInfo
 	}
+}
 
-	defer conn.Close()
+// SeverityRank will return the rank of the severity, a higher rank is worse
+func SeverityRank(severity string) int {
+	switch severity {
+	case SeverityWarning:
+		return 1
+	case SeverityDanger:
+		return 2
+	case SeverityCritical:
+		return 3
+	default:
+		return 0
+	}
+}
 
-	cleanDomain := strings.TrimPrefix(domain, "*.")
-	certs := conn.ConnectionState().PeerCertificates
+// domainTarget represent an item of domains field of the service config
+type domainTarget struct {
+	host       string
+	port       string
+	serverName string
+	starttls   string
+	roots      *x509.CertPool
+}
 
-	if len(certs) > 0 {
-		cert := certs[0]
+// address will return host:port of the target
+func (t domainTarget) address() string {
+	return net.JoinHostPort(t.host, t.port)
+}
+
+// name will return the name of the target result, the server name is included
+// when it differs from the host so several names can be checked on the same address
+func (t domainTarget) name() string {
+	if t.serverName != t.host {
+		return fmt.Sprintf("%s (%s)", t.serverName, t.address())
+	}
 
-		// checking whether the certificate matches the domain being checked
-		if err := cert.VerifyHostname(cleanDomain); err != nil {
-			status = "Danger"
-			return fmt.Sprintf("%s | %s | does not match (%s) |\n",
-				status,
-				cleanDomain,
-				cert.Subject.CommonName)
+	return t.address()
+}
+
+// parseDomainTarget will parse a domain, the domain is either a string
+// (eg: example.com, example.com:8443, smtp://mail.example.com:587)
+// or an object with host, port, serverName, starttls and caFile fields
+func parseDomainTarget(domain interface{}, roots *x509.CertPool) (domainTarget, error) {
+	var target domainTarget
+
+	switch d := domain.(type) {
+	case string:
+		if scheme, rest, ok := strings.Cut(d, "://"); ok {
+			target.starttls = scheme
+			d = rest
 		}
 
-		issuer := cert.Issuer.CommonName
-		sub := cert.Issuer.CommonName
-		logger.Println(cleanDomain, " | ", issuer, " | ", sub)
+		target.host = d
+		if host, port, err := net.SplitHostPort(d); err == nil {
+			target.host = host
+			target.port = port
+		}
+	case map[string]interface{}:
+		target.host, _ = d["host"].(string)
+		target.serverName, _ = d["serverName"].(string)
+		target.starttls, _ = d["starttls"].(string)
 
-		expiredDate := cert.NotAfter.Format(time.RFC1123)
+		switch port := d["port"].(type) {
+		case string:
+			target.port = port
+		case nil:
+		default:
+			target.port = strconv.Itoa(int(util.InterfaceToFloat64(port)))
+		}
 
-		if cert.NotAfter.Before(time.Now()) {
-			status = "Danger"
-			return fmt.Sprintf("%s | %s | expired on %s |\n",
-				status,
-				cleanDomain,
-				expiredDate)
+		if caFile, ok := d["caFile"].(string); ok && caFile != "" {
+			var err error
+			roots, err = loadCertPool(caFile)
+			if err != nil {
+				return target, err
+			}
 		}
+	default:
+		return target, fmt.Errorf("error: invalid domain %v", domain)
+	}
 
-		daysLeft := int(time.Until(cert.NotAfter).Hours() / 24)
+	// a wildcard domain is checked through its parent domain
+	target.host = strings.TrimPrefix(target.host, "*.")
+	if target.host == "" {
+		return target, fmt.Errorf("error: invalid domain %v", domain)
+	}
+
+	target.starttls = strings.ToLower(target.starttls)
+	if target.starttls == "https" || target.starttls == "tls" {
+		target.starttls = ""
+	}
+
+	defaultPort, ok := defaultPorts[target.starttls]
+	if !ok {
+		return target, fmt.Errorf("error: STARTTLS protocol %s is not supported", target.starttls)
+	}
+
+	if target.port == "" {
+		target.port = defaultPort
+	}
 
-		if daysLeft <= 7 {
-			status = "Critical"
-		} else if daysLeft <= 15 {
-			status = "Danger"
-		} else if daysLeft <= 30 {
-			status = "Warning"
+	if target.serverName == "" {
+		target.serverName = target.host
+	}
+
+	target.roots = roots
+
+	return target, nil
+}
+
+// loadCertPool will add the PEM certificates of the CA bundle to the system CA bundle
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("error: no certificate found in %s", caFile)
+	}
+
+	return roots, nil
+}
+
+// checkOptions represent the options shared by every domain of the service
+type checkOptions struct {
+	thresholds Thresholds
+	checkOCSP  bool
+	timeout    time.Duration
+}
+
+// checkDomain will check the certificate chain of the domain
+func checkDomain(target domainTarget, options checkOptions) domainResult {
+	result := domainResult{
+		Domain:    target.name(),
+		Severity:  SeverityInfo,
+		CheckedAt: time.Now(),
+	}
+
+	state, err := handshake(target, options.timeout)
+	if err != nil {
+		result.Severity = SeverityDanger
+		result.Message = err.Error()
+		return result
+	}
+
+	certs := state.PeerCertificates
+	if len(certs) == 0 {
+		result.Severity = SeverityDanger
+		result.Message = ErrorNoCertificate.Error()
+		return result
+	}
+
+	leaf := certs[0]
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	// the verified chain includes the root, otherwise only the certificates sent by the server are known
+	chain := certs
+	chains, verifyErr := leaf.Verify(x509.VerifyOptions{
+		DNSName:       target.serverName,
+		Roots:         target.roots,
+		Intermediates: intermediates,
+	})
+	if verifyErr == nil && len(chains) > 0 {
+		chain = chains[0]
+	}
+
+	// the certificate of the chain that expires first, the self-signed root is skipped,
+	// it is trusted by the client and is not renewed with the certificate of the server
+	expiring := leaf
+	for _, cert := range chain[1:] {
+		if bytes.Equal(cert.RawSubject, cert.RawIssuer) {
+			continue
 		}
 
-		return fmt.Sprintf("%s | %s | expire in %d days | (%s)\n",
-			status,
-			cleanDomain,
-			daysLeft,
-			expiredDate)
+		if cert.NotAfter.Before(expiring.NotAfter) {
+			expiring = cert
+		}
 	}
 
-	status = "Danger"
-	return fmt.Sprintf("%s | %s | failed to perform a TLS handshake |\n",
-		status,
-		cleanDomain)
+	result.NotAfter = expiring.NotAfter
+	result.DaysLeft = int(time.Until(expiring.NotAfter).Hours() / 24)
+
+	var details []string
+
+	name := "certificate"
+	if expiring != leaf {
+		name = fmt.Sprintf("intermediate %s", expiring.Subject.CommonName)
+	}
+
+	if expiring.NotAfter.Before(time.Now()) {
+		result.Severity = SeverityCritical
+		details = append(details, fmt.Sprintf("%s expired on %s", name, expiring.NotAfter.Format(time.RFC1123)))
+	} else {
+		result.Severity = options.thresholds.Severity(result.DaysLeft)
+		details = append(details, fmt.Sprintf("%s expire in %d days", name, result.DaysLeft))
+
+		// an expired certificate always fails the verification, it is reported above
+		if verifyErr != nil {
+			result.raise(SeverityDanger)
+			details = append(details, fmt.Sprintf("verification failed: %s", verifyErr.Error()))
+		}
+	}
+
+	if options.checkOCSP && verifyErr == nil && len(chain) > 1 {
+		severity, detail := checkOCSP(state.OCSPResponse, leaf, chain[1], options.timeout)
+		result.raise(severity)
+		if detail != "" {
+			details = append(details, detail)
+		}
+	}
+
+	result.Message = fmt.Sprintf("%s | (%s)", strings.Join(details, ", "), leaf.NotAfter.Format(time.RFC1123))
+
+	return result
 }
 
-func checkSSLExpiryMulti(domains []string, logger *log.Logger) string {
-	var sb strings.Builder
+// handshake will connect to the target, negotiate STARTTLS if needed, and perform the TLS handshake,
+// the chain is verified by checkDomain so the certificates of an invalid chain are still inspected
+func handshake(target domainTarget, timeout time.Duration) (tls.ConnectionState, error) {
+	conn, err := net.DialTimeout("tcp", target.address(), timeout)
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
 
-	for _, domain := range domains {
-		sb.WriteString(checkSSLExpiry(domain, logger))
+	defer func() { conn.Close() }()
+
+	err = conn.SetDeadline(time.Now().Add(timeout))
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+
+	if target.starttls != "" {
+		err = starttls(conn, target.starttls)
+		if err != nil {
+			return tls.ConnectionState{}, err
+		}
+	}
+
+	tlsConn := tls.Client(conn, &tls.Config{
+		ServerName:         target.serverName,
+		InsecureSkipVerify: true,
+	})
+
+	err = tlsConn.Handshake()
+	if err != nil {
+		return tls.ConnectionState{}, fmt.Errorf("failed to perform a TLS handshake: %s", err.Error())
 	}
-	return sb.String()
+
+	return tlsConn.ConnectionState(), nil
 }
@@ -0,0 +1,323 @@
+package sslstatus
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/telkomdev/tob/config"
+)
+
+// testCA a certificate and its key, used to sign the certificates of the test chains
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// issue will create a certificate valid for daysLeft days and a half, signed by the parent,
+// a nil parent creates a self-signed root
+func issue(t *testing.T, parent *testCA, commonName string, daysLeft int, isCA bool) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-24 * time.Hour),
+		NotAfter:              time.Now().Add(time.Duration(daysLeft)*24*time.Hour + 12*time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+
+	if isCA {
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		template.DNSNames = []string{commonName}
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	}
+
+	signer := &testCA{cert: template, key: key}
+	if parent != nil {
+		signer = parent
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer.cert, &key.PublicKey, signer.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testCA{cert: cert, key: key}
+}
+
+// newTLSServer will serve the leaf, followed by the rest of the chain
+func newTLSServer(t *testing.T, leaf *testCA, chain ...*testCA) *httptest.Server {
+	certificate := tls.Certificate{PrivateKey: leaf.key, Certificate: [][]byte{leaf.cert.Raw}}
+	for _, cert := range chain {
+		certificate.Certificate = append(certificate.Certificate, cert.cert.Raw)
+	}
+
+	server := httptest.NewUnstartedServer(http.NotFoundHandler())
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{certificate}}
+	server.StartTLS()
+
+	t.Cleanup(server.Close)
+	return server
+}
+
+// writeCAFile will write the PEM certificate of the root into a CA bundle
+func writeCAFile(t *testing.T, root *testCA) string {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+
+	err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: root.cert.Raw}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return caFile
+}
+
+func TestParseDomainTarget(t *testing.T) {
+	tests := []struct {
+		domain interface{}
+
+		host, port, serverName, starttls string
+	}{
+		{domain: "example.com", host: "example.com", port: "443", serverName: "example.com"},
+		{domain: "example.com:8443", host: "example.com", port: "8443", serverName: "example.com"},
+		{domain: "*.example.com", host: "example.com", port: "443", serverName: "example.com"},
+		{domain: "https://example.com", host: "example.com", port: "443", serverName: "example.com"},
+		{domain: "smtp://mail.example.com:587", host: "mail.example.com", port: "587", serverName: "mail.example.com", starttls: "smtp"},
+		{domain: "IMAP://mail.example.com", host: "mail.example.com", port: "143", serverName: "mail.example.com", starttls: "imap"},
+		{domain: "[::1]:8443", host: "::1", port: "8443", serverName: "::1"},
+		{
+			domain: map[string]interface{}{"host": "10.0.0.1", "port": 8443.0, "serverName": "api.example.com"},
+			host:   "10.0.0.1", port: "8443", serverName: "api.example.com",
+		},
+		{
+			domain: map[string]interface{}{"host": "db.example.com", "starttls": "postgres"},
+			host:   "db.example.com", port: "5432", serverName: "db.example.com", starttls: "postgres",
+		},
+	}
+
+	for _, test := range tests {
+		target, err := parseDomainTarget(test.domain, nil)
+		if err != nil {
+			t.Errorf("parseDomainTarget(%v) unexpected error: %s", test.domain, err)
+			continue
+		}
+
+		if target.host != test.host || target.port != test.port || target.serverName != test.serverName || target.starttls != test.starttls {
+			t.Errorf("parseDomainTarget(%v) expected %s %s %s %s, got %s %s %s %s", test.domain,
+				test.host, test.port, test.serverName, test.starttls,
+				target.host, target.port, target.serverName, target.starttls)
+		}
+	}
+
+	invalid := []interface{}{
+		"",
+		"ftp://example.com",
+		1.0,
+		map[string]interface{}{"port": 443.0},
+		map[string]interface{}{"host": "example.com", "caFile": filepath.Join(t.TempDir(), "missing.pem")},
+	}
+
+	for _, domain := range invalid {
+		if _, err := parseDomainTarget(domain, nil); err == nil {
+			t.Errorf("parseDomainTarget(%v) expected an error", domain)
+		}
+	}
+}
+
+func TestThresholds(t *testing.T) {
+	defaults := ParseThresholds(config.Config{})
+	if defaults != (Thresholds{WarningDays: 30, DangerDays: 15, CriticalDays: 7}) {
+		t.Errorf("unexpected default thresholds %+v", defaults)
+	}
+
+	thresholds := ParseThresholds(config.Config{"warningDays": 60.0, "dangerDays": 20.0, "criticalDays": 0.0})
+
+	tests := map[int]string{
+		-1: SeverityCritical,
+		0:  SeverityCritical,
+		1:  SeverityDanger,
+		20: SeverityDanger,
+		21: SeverityWarning,
+		60: SeverityWarning,
+		61: SeverityInfo,
+	}
+
+	for daysLeft, expected := range tests {
+		if severity := thresholds.Severity(daysLeft); severity != expected {
+			t.Errorf("Severity(%d) expected %s, got %s", daysLeft, expected, severity)
+		}
+	}
+}
+
+func TestCheckDomain(t *testing.T) {
+	// the root expires first, it is not renewed by the server so it is not reported
+	root := issue(t, nil, "Tob Root", 5, true)
+	intermediate := issue(t, root, "Tob Intermediate", 200, true)
+	expiringIntermediate := issue(t, root, "Tob Expiring Intermediate", 20, true)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root.cert)
+
+	tests := []struct {
+		name       string
+		server     *httptest.Server
+		serverName string
+		roots      *x509.CertPool
+		severity   string
+
+		// message the beginning of the result message
+		message string
+	}{
+		{
+			name:     "leaf",
+			server:   newTLSServer(t, issue(t, intermediate, "tob.test", 90, false), intermediate),
+			roots:    roots,
+			severity: SeverityInfo,
+			message:  "certificate expire in 90 days |",
+		},
+		{
+			name:     "intermediate",
+			server:   newTLSServer(t, issue(t, expiringIntermediate, "tob.test", 90, false), expiringIntermediate),
+			roots:    roots,
+			severity: SeverityWarning,
+			message:  "intermediate Tob Expiring Intermediate expire in 20 days |",
+		},
+		{
+			name:     "danger",
+			server:   newTLSServer(t, issue(t, intermediate, "tob.test", 10, false), intermediate),
+			roots:    roots,
+			severity: SeverityDanger,
+			message:  "certificate expire in 10 days |",
+		},
+		{
+			name:     "expired",
+			server:   newTLSServer(t, issue(t, intermediate, "tob.test", -3, false), intermediate),
+			roots:    roots,
+			severity: SeverityCritical,
+			message:  "certificate expired on ",
+		},
+		{
+			name:     "untrusted root sent by the server",
+			server:   newTLSServer(t, issue(t, intermediate, "tob.test", 90, false), intermediate, root),
+			severity: SeverityDanger,
+			message:  "certificate expire in 90 days, verification failed: ",
+		},
+		{
+			name:       "hostname mismatch",
+			server:     newTLSServer(t, issue(t, intermediate, "tob.test", 90, false), intermediate),
+			serverName: "other.test",
+			roots:      roots,
+			severity:   SeverityDanger,
+			message:    "certificate expire in 90 days, verification failed: ",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			host, port, _ := net.SplitHostPort(test.server.Listener.Addr().String())
+
+			serverName := test.serverName
+			if serverName == "" {
+				serverName = "tob.test"
+			}
+
+			target := domainTarget{host: host, port: port, serverName: serverName, roots: test.roots}
+			result := checkDomain(target, checkOptions{thresholds: ParseThresholds(config.Config{}), timeout: 5 * time.Second})
+
+			if result.Severity != test.severity || !strings.HasPrefix(result.Message, test.message) {
+				t.Errorf("expected %s %q, got %s %q", test.severity, test.message, result.Severity, result.Message)
+			}
+		})
+	}
+
+	// the handshake fails on a closed port
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+
+	result := checkDomain(domainTarget{host: host, port: port, serverName: host}, checkOptions{timeout: time.Second})
+	if result.Severity != SeverityDanger {
+		t.Errorf("expected %s on a closed port, got %s %q", SeverityDanger, result.Severity, result.Message)
+	}
+}
+
+func TestSSLStatusResults(t *testing.T) {
+	root := issue(t, nil, "Tob Root", 3650, true)
+	valid := newTLSServer(t, issue(t, root, "tob.test", 90, false))
+	warning := newTLSServer(t, issue(t, root, "tob.test", 20, false))
+
+	domain := func(server *httptest.Server) map[string]interface{} {
+		host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+		return map[string]interface{}{"host": host, "port": port, "serverName": "tob.test"}
+	}
+
+	d := NewSSLStatus(false, log.New(io.Discard, "", 0))
+	d.SetConfig(config.Config{
+		"domains": []interface{}{domain(valid), domain(warning)},
+		"caFile":  writeCAFile(t, root),
+	})
+
+	if err := d.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	if status := string(d.Ping()); status != "DEGRADED" {
+		t.Fatalf("expected DEGRADED, got %s:\n%s", status, d.GetMessage())
+	}
+
+	first := d.Results()
+	if len(first) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(first))
+	}
+
+	expected := []struct{ name, status, message string }{
+		{name: "tob.test (" + valid.Listener.Addr().String() + ")", status: "UP", message: "Info | certificate expire in 90 days"},
+		{name: "tob.test (" + warning.Listener.Addr().String() + ")", status: "DEGRADED", message: "Warning | certificate expire in 20 days"},
+	}
+
+	for i, result := range first {
+		if result.Name != expected[i].name || result.Status != expected[i].status || !strings.HasPrefix(result.Message, expected[i].message) {
+			t.Errorf("expected %s %s %q, got %s %s %q", expected[i].name, expected[i].status, expected[i].message, result.Name, result.Status, result.Message)
+		}
+	}
+
+	// the severity has not changed, so the results keep their since
+	d.Ping()
+	for i, result := range d.Results() {
+		if !result.Since.Equal(first[i].Since) {
+			t.Errorf("expected %s to be %s since %s, got %s", result.Name, result.Status, first[i].Since, result.Since)
+		}
+	}
+}
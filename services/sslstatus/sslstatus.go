@@ -1,12 +1,17 @@
 package sslstatus
 
 import (
+	"crypto/x509"
+	"errors"
+	"fmt"
 	"log"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/telkomdev/tob"
 	"github.com/telkomdev/tob/config"
+	"github.com/telkomdev/tob/events"
 	"github.com/telkomdev/tob/util"
 )
 
@@ -21,11 +26,41 @@ type SSLStatus struct {
 	checkInterval     int
 	stopChan          chan bool
 	message           string
+	targets           []domainTarget
+	options           checkOptions
+	results           map[string]domainResult
+	resultsMutex      sync.RWMutex
 	configs           config.Config
 	notificatorConfig config.Config
 }
 
-var SEVERITIES = []string{"Warning", "Danger", "Critical"}
+// SEVERITIES the severities that alert, Warning marks the service DEGRADED, Danger and Critical mark the service DOWN
+var SEVERITIES = []string{SeverityWarning, SeverityDanger, SeverityCritical}
+
+var (
+	// ErrorNoDomains error type
+	ErrorNoDomains = errors.New("error: domains is not in the sslstatus config")
+)
+
+// domainResult represent the latest check result of a domain
+type domainResult struct {
+	Domain    string
+	Severity  string
+	Message   string
+	NotAfter  time.Time
+	DaysLeft  int
+	CheckedAt time.Time
+
+	// Since the time the domain entered its current severity
+	Since time.Time
+}
+
+// raise will set the severity when it is worse than the current one
+func (r *domainResult) raise(severity string) {
+	if SeverityRank(severity) > SeverityRank(r.Severity) {
+		r.Severity = severity
+	}
+}
 
 // NewSSLStatus SSLStatus's constructor
 func NewSSLStatus(verbose bool, logger *log.Logger) *SSLStatus {
@@ -38,6 +73,7 @@ func NewSSLStatus(verbose bool, logger *log.Logger) *SSLStatus {
 		recovered:     true,
 		checkInterval: 0,
 		stopChan:      stopChan,
+		results:       make(map[string]domainResult),
 	}
 }
 
@@ -48,32 +84,100 @@ func (d *SSLStatus) Name() string {
 
 // Ping will try to ping the service
 func (d *SSLStatus) Ping() []byte {
-	domains, ok := d.configs["domains"].([]interface{})
-	if !ok {
+	if len(d.targets) == 0 {
+		d.SetMessage(ErrorNoDomains.Error())
 		if d.verbose {
-			d.logger.Println("domains is not in the SSL_status config")
+			d.logger.Println(ErrorNoDomains)
 		}
 		return []byte("NOT_OK")
 	}
 
-	var domianStrs []string
+	results := make([]domainResult, len(d.targets))
 
-	for _, domain := range domains {
-		domainStr, ok := domain.(string)
-		if ok {
-			domianStrs = append(domianStrs, domainStr)
-		}
+	var wg sync.WaitGroup
+	for i, target := range d.targets {
+		wg.Add(1)
+		go func(i int, target domainTarget) {
+			defer wg.Done()
+			results[i] = checkDomain(target, d.options)
+		}(i, target)
 	}
 
-	sslStatusData := checkSSLExpiryMulti(domianStrs, d.logger)
+	wg.Wait()
+
+	var (
+		sb    strings.Builder
+		worst = SeverityInfo
+	)
+
+	d.resultsMutex.Lock()
+	for _, result := range results {
+		// each domain keeps its own state, so the message tells which domain has changed
+		changed := ""
+		result.Since = result.CheckedAt
+		if previous, ok := d.results[result.Domain]; ok {
+			if previous.Severity == result.Severity {
+				result.Since = previous.Since
+			} else {
+				changed = fmt.Sprintf(" | changed from %s", previous.Severity)
+			}
+		}
+
+		d.results[result.Domain] = result
 
-	d.SetMessage(sslStatusData)
+		if d.verbose {
+			d.logger.Printf("%s | %s | %s\n", result.Severity, result.Domain, result.Message)
+		}
+
+		sb.WriteString(fmt.Sprintf("%s | %s | %s%s\n", result.Severity, result.Domain, result.Message, changed))
+
+		if SeverityRank(result.Severity) > SeverityRank(worst) {
+			worst = result.Severity
+		}
+	}
+	d.resultsMutex.Unlock()
 
-	if containsSeverity(sslStatusData, SEVERITIES) {
+	d.SetMessage(sb.String())
+
+	switch worst {
+	case SeverityDanger, SeverityCritical:
 		return []byte("NOT_OK")
+	case SeverityWarning:
+		return []byte("DEGRADED")
+	default:
+		return []byte("OK")
+	}
+}
+
+// Results will return the latest check result of every domain
+func (d *SSLStatus) Results() []events.Result {
+	d.resultsMutex.RLock()
+	defer d.resultsMutex.RUnlock()
+
+	results := make([]events.Result, 0, len(d.targets))
+	for _, target := range d.targets {
+		result, ok := d.results[target.name()]
+		if !ok {
+			continue
+		}
+
+		status := "UP"
+		switch result.Severity {
+		case SeverityDanger, SeverityCritical:
+			status = "DOWN"
+		case SeverityWarning:
+			status = "DEGRADED"
+		}
+
+		results = append(results, events.Result{
+			Name:    result.Domain,
+			Status:  status,
+			Message: fmt.Sprintf("%s | %s", result.Severity, result.Message),
+			Since:   result.Since,
+		})
 	}
 
-	return []byte("OK")
+	return results
 }
 
 // SetURL will set the service URL
@@ -87,6 +191,42 @@ func (d *SSLStatus) Connect() error {
 		d.logger.Println("connect SSLStatus")
 	}
 
+	// nil roots means the system CA bundle
+	var roots *x509.CertPool
+	if caFile, ok := d.configs["caFile"].(string); ok && caFile != "" {
+		var err error
+		roots, err = loadCertPool(caFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Ping reports the missing domains
+	domains, _ := d.configs["domains"].([]interface{})
+
+	d.targets = d.targets[:0]
+	for _, domain := range domains {
+		target, err := parseDomainTarget(domain, roots)
+		if err != nil {
+			return err
+		}
+
+		d.targets = append(d.targets, target)
+	}
+
+	checkOCSP, _ := d.configs["checkOCSP"].(bool)
+
+	timeout := int(util.InterfaceToFloat64(d.configs["timeout"]))
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	d.options = checkOptions{
+		thresholds: ParseThresholds(d.configs),
+		checkOCSP:  checkOCSP,
+		timeout:    time.Duration(timeout) * time.Second,
+	}
+
 	return nil
 }
 
@@ -170,12 +310,3 @@ func (d *SSLStatus) GetNotificators() []tob.Notificator {
 func (d *SSLStatus) Stop() chan bool {
 	return d.stopChan
 }
-
-func containsSeverity(sslStatusData string, severities []string) bool {
-	for _, sev := range severities {
-		if strings.Contains(sslStatusData, sev) {
-			return true
-		}
-	}
-	return false
-}
@@ -0,0 +1,170 @@
+package sslstatus
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// postgresSSLRequestCode the SSLRequest message code of PostgreSQL protocol
+const postgresSSLRequestCode = 80877103
+
+// starttls will upgrade the plain text connection so the TLS handshake can start
+func starttls(conn net.Conn, protocol string) error {
+	var err error
+
+	switch protocol {
+	case "smtp":
+		err = starttlsSMTP(conn)
+	case "imap":
+		err = starttlsIMAP(conn)
+	case "pop3":
+		err = starttlsPOP3(conn)
+	case "postgres":
+		err = starttlsPostgres(conn)
+	default:
+		err = fmt.Errorf("STARTTLS protocol %s is not supported", protocol)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s STARTTLS failed: %s", protocol, err.Error())
+	}
+
+	return nil
+}
+
+// readSMTPReply will read a (multi line) SMTP reply and check its code
+func readSMTPReply(reader *bufio.Reader, code string) error {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+
+		if !strings.HasPrefix(line, code) {
+			return fmt.Errorf("unexpected reply %s", strings.TrimSpace(line))
+		}
+
+		// the last line of a reply is "code text", the other lines are "code-text"
+		if len(line) <= len(code) || line[len(code)] != '-' {
+			return nil
+		}
+	}
+}
+
+func starttlsSMTP(conn net.Conn) error {
+	reader := bufio.NewReader(conn)
+
+	err := readSMTPReply(reader, "220")
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(conn, "EHLO tob\r\n")
+	if err != nil {
+		return err
+	}
+
+	err = readSMTPReply(reader, "250")
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(conn, "STARTTLS\r\n")
+	if err != nil {
+		return err
+	}
+
+	return readSMTPReply(reader, "220")
+}
+
+func starttlsIMAP(conn net.Conn) error {
+	reader := bufio.NewReader(conn)
+
+	greeting, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+
+	if !strings.HasPrefix(greeting, "* OK") {
+		return fmt.Errorf("unexpected greeting %s", strings.TrimSpace(greeting))
+	}
+
+	_, err = io.WriteString(conn, "a001 STARTTLS\r\n")
+	if err != nil {
+		return err
+	}
+
+	// skip untagged replies until the tagged reply
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+
+		if !strings.HasPrefix(line, "a001 ") {
+			continue
+		}
+
+		if !strings.HasPrefix(line, "a001 OK") {
+			return fmt.Errorf("unexpected reply %s", strings.TrimSpace(line))
+		}
+
+		return nil
+	}
+}
+
+func starttlsPOP3(conn net.Conn) error {
+	reader := bufio.NewReader(conn)
+
+	greeting, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+
+	if !strings.HasPrefix(greeting, "+OK") {
+		return fmt.Errorf("unexpected greeting %s", strings.TrimSpace(greeting))
+	}
+
+	_, err = io.WriteString(conn, "STLS\r\n")
+	if err != nil {
+		return err
+	}
+
+	reply, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+
+	if !strings.HasPrefix(reply, "+OK") {
+		return fmt.Errorf("unexpected reply %s", strings.TrimSpace(reply))
+	}
+
+	return nil
+}
+
+func starttlsPostgres(conn net.Conn) error {
+	request := make([]byte, 8)
+	binary.BigEndian.PutUint32(request[0:4], 8)
+	binary.BigEndian.PutUint32(request[4:8], postgresSSLRequestCode)
+
+	_, err := conn.Write(request)
+	if err != nil {
+		return err
+	}
+
+	reply := make([]byte, 1)
+	_, err = io.ReadFull(conn, reply)
+	if err != nil {
+		return err
+	}
+
+	if reply[0] != 'S' {
+		return fmt.Errorf("server does not support SSL")
+	}
+
+	return nil
+}
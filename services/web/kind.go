@@ -0,0 +1,17 @@
+package web
+
+import (
+	"log"
+
+	"github.com/telkomdev/tob"
+)
+
+func init() {
+	tob.RegisterServiceKind(tob.ServiceKindInfo{
+		Kind:        tob.Web,
+		Description: "HTTP endpoint returns 200",
+		Factory: func(verbose bool, logger *log.Logger) tob.Service {
+			return NewWeb(verbose, logger)
+		},
+	})
+}
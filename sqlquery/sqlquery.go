@@ -0,0 +1,309 @@
+package sqlquery
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/telkomdev/tob/config"
+	"github.com/telkomdev/tob/util"
+)
+
+const (
+	// AssertScalar assert the first column of the first row
+	AssertScalar = "scalar"
+
+	// AssertRows assert the number of returned rows
+	AssertRows = "rows"
+
+	// SeverityWarning failed assertion marks the service DEGRADED
+	SeverityWarning = "warning"
+
+	// SeverityCritical failed assertion marks the service DOWN
+	SeverityCritical = "critical"
+
+	// DefaultTimeout default query timeout in seconds
+	DefaultTimeout = 5
+)
+
+var (
+	// ErrorNotReadOnly error type
+	ErrorNotReadOnly = errors.New("error: only SELECT, WITH, SHOW and VALUES statements are allowed")
+
+	readOnlyKeywords = []string{"SELECT", "WITH", "SHOW", "VALUES"}
+)
+
+// Query represent a read only SQL statement and its assertion
+type Query struct {
+	Name     string
+	SQL      string
+	Timeout  int
+	Assert   string
+	Operator string
+	Value    interface{}
+	Severity string
+}
+
+// Runner will run Query inside a read only transaction that is always rolled back
+type Runner struct {
+	db *sql.DB
+
+	// readOnlyStatement is executed as the first statement of the transaction
+	// for drivers that do not support sql.TxOptions ReadOnly
+	readOnlyStatement string
+}
+
+// NewRunner Runner's constructor, set readOnlyStatement to empty string
+// when the driver supports read only transaction
+func NewRunner(db *sql.DB, readOnlyStatement string) *Runner {
+	return &Runner{
+		db:                db,
+		readOnlyStatement: readOnlyStatement,
+	}
+}
+
+// ParseQueries will parse queries field from the service config
+func ParseQueries(configs config.Config) []Query {
+	var queries []Query
+
+	queryInterfaces, ok := configs["queries"].([]interface{})
+	if !ok {
+		return queries
+	}
+
+	for i, queryInterface := range queryInterfaces {
+		queryConfig, ok := queryInterface.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		statement, ok := queryConfig["sql"].(string)
+		if !ok || statement == "" {
+			continue
+		}
+
+		name, ok := queryConfig["name"].(string)
+		if !ok || name == "" {
+			name = fmt.Sprintf("query %d", i+1)
+		}
+
+		timeout := int(util.InterfaceToFloat64(queryConfig["timeout"]))
+		if timeout <= 0 {
+			timeout = DefaultTimeout
+		}
+
+		assert, ok := queryConfig["assert"].(string)
+		if !ok || assert == "" {
+			assert = AssertScalar
+		}
+
+		operator, ok := queryConfig["operator"].(string)
+		if !ok || operator == "" {
+			operator = "=="
+		}
+
+		severity, ok := queryConfig["severity"].(string)
+		if !ok || severity == "" {
+			severity = SeverityCritical
+		}
+
+		queries = append(queries, Query{
+			Name:     name,
+			SQL:      statement,
+			Timeout:  timeout,
+			Assert:   assert,
+			Operator: operator,
+			Value:    queryConfig["value"],
+			Severity: severity,
+		})
+	}
+
+	return queries
+}
+
+// Run will run all queries, and return failed critical and warning assertion messages
+func (r *Runner) Run(queries []Query) ([]string, []string) {
+	var (
+		criticals []string
+		warnings  []string
+	)
+
+	for _, query := range queries {
+		err := r.run(query)
+		if err == nil {
+			continue
+		}
+
+		message := fmt.Sprintf("%s: %s", query.Name, err.Error())
+		if query.Severity == SeverityWarning {
+			warnings = append(warnings, message)
+		} else {
+			criticals = append(criticals, message)
+		}
+	}
+
+	return criticals, warnings
+}
+
+func (r *Runner) run(query Query) error {
+	if !isReadOnly(query.SQL) {
+		return ErrorNotReadOnly
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(query.Timeout)*time.Second)
+	defer func() { cancel() }()
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: r.readOnlyStatement == ""})
+	if err != nil {
+		return err
+	}
+
+	// nothing is ever committed
+	defer func() { tx.Rollback() }()
+
+	if r.readOnlyStatement != "" {
+		_, err = tx.ExecContext(ctx, r.readOnlyStatement)
+		if err != nil {
+			return err
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, query.SQL)
+	if err != nil {
+		return err
+	}
+
+	defer func() { rows.Close() }()
+
+	var (
+		rowCount int
+		scalar   interface{}
+	)
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		if rowCount == 0 && len(columns) > 0 {
+			values := make([]interface{}, len(columns))
+			for i := range values {
+				values[i] = new(interface{})
+			}
+
+			err = rows.Scan(values...)
+			if err != nil {
+				return err
+			}
+
+			scalar = *(values[0].(*interface{}))
+		}
+
+		rowCount++
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return err
+	}
+
+	if query.Assert == AssertRows {
+		return compare(float64(rowCount), query.Operator, query.Value, "row count")
+	}
+
+	if rowCount == 0 {
+		return errors.New("query returned no rows")
+	}
+
+	return compare(scalar, query.Operator, query.Value, "value")
+}
+
+// compare will compare actual value with the expected value,
+// numbers are compared numerically and anything else as string
+func compare(actual interface{}, operator string, expected interface{}, label string) error {
+	actualStr := toString(actual)
+	expectedStr := toString(expected)
+
+	actualNum, actualErr := strconv.ParseFloat(actualStr, 64)
+	expectedNum, expectedErr := strconv.ParseFloat(expectedStr, 64)
+	numeric := actualErr == nil && expectedErr == nil
+
+	var ok bool
+	switch operator {
+	case "==", "=":
+		if numeric {
+			ok = actualNum == expectedNum
+		} else {
+			ok = actualStr == expectedStr
+		}
+	case "!=", "<>":
+		if numeric {
+			ok = actualNum != expectedNum
+		} else {
+			ok = actualStr != expectedStr
+		}
+	case "<", "<=", ">", ">=":
+		if !numeric {
+			return fmt.Errorf("cannot compare %s %s with %s %s, not a number", label, actualStr, operator, expectedStr)
+		}
+
+		switch operator {
+		case "<":
+			ok = actualNum < expectedNum
+		case "<=":
+			ok = actualNum <= expectedNum
+		case ">":
+			ok = actualNum > expectedNum
+		case ">=":
+			ok = actualNum >= expectedNum
+		}
+	default:
+		return fmt.Errorf("invalid operator %s", operator)
+	}
+
+	if !ok {
+		return fmt.Errorf("%s %s, expected %s %s", label, actualStr, operator, expectedStr)
+	}
+
+	return nil
+}
+
+func toString(val interface{}) string {
+	switch t := val.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return string(t)
+	case string:
+		return t
+	case time.Time:
+		return t.Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(t), 'f', -1, 32)
+	default:
+		return fmt.Sprintf("%v", t)
+	}
+}
+
+func isReadOnly(statement string) bool {
+	fields := strings.Fields(strings.TrimLeft(statement, "( \t\r\n"))
+	if len(fields) == 0 {
+		return false
+	}
+
+	keyword := strings.ToUpper(fields[0])
+	for _, readOnlyKeyword := range readOnlyKeywords {
+		if keyword == readOnlyKeyword {
+			return true
+		}
+	}
+
+	return false
+}
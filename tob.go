@@ -11,6 +11,9 @@ const (
 	// NotOk service status
 	NotOk = "NOT_OK"
 
+	// Degraded service status, the service is reachable but one or more thresholds are exceeded
+	Degraded = "DEGRADED"
+
 	// TimeZoneJakarta timezone
 	TimeZoneJakarta = "Asia/Jakarta"
 )